@@ -28,6 +28,10 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if len(cfg.Auth.JWTSecret) < config.MinJWTSecretLength {
+		log.Fatalf("JWT_SECRET must be set to at least %d characters", config.MinJWTSecretLength)
+	}
+
 	// Set Gin mode
 	gin.SetMode(cfg.Server.GinMode)
 
@@ -40,12 +44,41 @@ func main() {
 
 	// Initialize layers
 	queries := db.New(dbPool)
-	recipesRepo := repository.NewRecipesRepository(queries)
-	recipesService := service.NewRecipesService(recipesRepo)
+
+	usersRepo := repository.NewUsersRepository(dbPool, queries)
+	authService := service.NewAuthService(usersRepo, []byte(cfg.Auth.JWTSecret),
+		service.WithTokenLifetimes(cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL))
+	authHandler := handler.NewAuthHandler(authService)
+
+	pantryRepo := repository.NewPantryRepository(queries)
+	pantryService := service.NewPantryService(pantryRepo)
+	pantryHandler := handler.NewPantryHandler(pantryService)
+
+	recipesRepo := repository.NewRecipesRepository(dbPool, queries)
+	if err := checkBackfill(recipesRepo); err != nil {
+		log.Fatalf("Database is not ready: %v", err)
+	}
+	recipesService := service.NewRecipesService(recipesRepo, service.WithPantry(pantryRepo))
 	recipesHandler := handler.NewRecipesHandler(recipesService)
 
+	categoriesRepo := repository.NewCategoriesRepository(queries)
+	categoriesService := service.NewCategoriesService(categoriesRepo)
+	categoriesHandler := handler.NewCategoriesHandler(categoriesService)
+
+	variantsRepo := repository.NewVariantsRepository(queries)
+	variantsService := service.NewVariantsService(variantsRepo)
+	variantsHandler := handler.NewVariantsHandler(variantsService)
+
+	tagsRepo := repository.NewTagsRepository(queries)
+	tagsService := service.NewTagsService(tagsRepo)
+	tagsHandler := handler.NewTagsHandler(tagsService)
+
+	mealPlansRepo := repository.NewMealPlansRepository(dbPool, queries)
+	mealPlansService := service.NewMealPlansService(mealPlansRepo, recipesService)
+	mealPlansHandler := handler.NewMealPlansHandler(mealPlansService)
+
 	// Setup router
-	router := setupRouter(cfg, recipesHandler)
+	router := setupRouter(cfg, recipesHandler, categoriesHandler, variantsHandler, tagsHandler, mealPlansHandler, pantryHandler, authHandler)
 
 	// Start server
 	srv := &http.Server{
@@ -101,14 +134,40 @@ func connectDB(cfg *config.Config) (*sql.DB, error) {
 	return dbConn, nil
 }
 
-func setupRouter(cfg *config.Config, recipesHandler *handler.RecipesHandler) *gin.Engine {
+// checkBackfill makes sure every recipe has the structured ingredients,
+// steps and allergens the API reads, which recipes inserted directly into
+// the database only get from the backfill command
+func checkBackfill(repo repository.RecipesRepository) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pending, err := repo.CountRecipesNeedingBackfill(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to check for recipes needing backfill: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d recipe(s) need the structured-data backfill; run `go run ./cmd/backfill`", pending)
+	}
+	return nil
+}
+
+func setupRouter(
+	cfg *config.Config,
+	recipesHandler *handler.RecipesHandler,
+	categoriesHandler *handler.CategoriesHandler,
+	variantsHandler *handler.VariantsHandler,
+	tagsHandler *handler.TagsHandler,
+	mealPlansHandler *handler.MealPlansHandler,
+	pantryHandler *handler.PantryHandler,
+	authHandler *handler.AuthHandler,
+) *gin.Engine {
 	router := gin.Default()
 
 	// CORS middleware
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CORS.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Client-ID"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -121,16 +180,77 @@ func setupRouter(cfg *config.Config, recipesHandler *handler.RecipesHandler) *gi
 
 	// API routes
 	api := router.Group("/api")
+	requireAuth := authHandler.RequireAuth()
+	{
+		// Auth endpoints
+		api.POST("/auth/register", authHandler.Register)
+		api.POST("/auth/login", authHandler.Login)
+		api.POST("/auth/refresh", authHandler.Refresh)
+		api.POST("/auth/logout", authHandler.Logout)
+	}
+
+	// Everything else also works anonymously. A request with an access
+	// token is signed in, and its spin history, pantry and meal plans are
+	// the user's rather than the X-Client-ID's.
+	app := api.Group("", authHandler.OptionalAuth())
 	{
-		// Recipes endpoints
-		api.GET("/recipes", recipesHandler.ListRecipes)
-		api.POST("/recipes", recipesHandler.CreateRecipe)
-		api.GET("/recipes/:id", recipesHandler.GetRecipeByID)
-		api.PUT("/recipes/:id", recipesHandler.UpdateRecipe)
-		api.DELETE("/recipes/:id", recipesHandler.DeleteRecipe)
+		// Recipes endpoints; changes need a signed-in user
+		app.GET("/recipes", recipesHandler.ListRecipes)
+		app.POST("/recipes", requireAuth, recipesHandler.CreateRecipe)
+		app.POST("/recipes/pantry", recipesHandler.SearchByPantry)
+		app.GET("/recipes/dietary-audit", recipesHandler.AuditDietary)
+		app.GET("/recipes/:id", recipesHandler.GetRecipeByID)
+		app.PUT("/recipes/:id", requireAuth, recipesHandler.UpdateRecipe)
+		app.DELETE("/recipes/:id", requireAuth, recipesHandler.DeleteRecipe)
 
 		// Spin wheel endpoint (bonus feature)
-		api.POST("/spin", recipesHandler.Spin)
+		app.POST("/spin", recipesHandler.Spin)
+		app.POST("/spin/plan", recipesHandler.GenerateMealPlan)
+		app.POST("/spin/plan/reroll", recipesHandler.RerollMealPlanSlot)
+
+		// Shopping list endpoint
+		app.POST("/shopping-list", recipesHandler.BuildShoppingList)
+
+		// Categories endpoints; changes need a signed-in user
+		app.GET("/categories", categoriesHandler.ListCategories)
+		app.POST("/categories", requireAuth, categoriesHandler.CreateCategory)
+		app.GET("/categories/:id", categoriesHandler.GetCategoryByID)
+		app.PUT("/categories/:id", requireAuth, categoriesHandler.UpdateCategory)
+		app.DELETE("/categories/:id", requireAuth, categoriesHandler.DeleteCategory)
+
+		// Variants endpoints; changes need a signed-in user
+		app.GET("/variants", variantsHandler.ListVariants)
+		app.POST("/variants", requireAuth, variantsHandler.CreateVariant)
+		app.GET("/variants/:id", variantsHandler.GetVariantByID)
+		app.PUT("/variants/:id", requireAuth, variantsHandler.UpdateVariant)
+		app.DELETE("/variants/:id", requireAuth, variantsHandler.DeleteVariant)
+
+		// Tags endpoints; changes need a signed-in user
+		app.GET("/tags", tagsHandler.ListTags)
+		app.GET("/tags/autocomplete", tagsHandler.AutocompleteTags)
+		app.POST("/tags", requireAuth, tagsHandler.CreateTag)
+		app.GET("/tags/:id", tagsHandler.GetTagByID)
+		app.PUT("/tags/:id", requireAuth, tagsHandler.UpdateTag)
+		app.DELETE("/tags/:id", requireAuth, tagsHandler.DeleteTag)
+
+		// Meal plan endpoints
+		app.GET("/plans", mealPlansHandler.ListMealPlans)
+		app.POST("/plans", mealPlansHandler.CreateMealPlan)
+		app.GET("/plans/:id", mealPlansHandler.GetMealPlan)
+		app.PUT("/plans/:id", mealPlansHandler.UpdateMealPlan)
+		app.DELETE("/plans/:id", mealPlansHandler.DeleteMealPlan)
+		app.GET("/plans/:id/shopping-list", mealPlansHandler.GetMealPlanShoppingList)
+		app.POST("/plans/:id/entries", mealPlansHandler.AssignMealPlanEntry)
+		app.POST("/plans/:id/spin", mealPlansHandler.SpinMealPlanEntry)
+		app.POST("/plans/:id/entries/:entryId/move", mealPlansHandler.MoveMealPlanEntry)
+		app.PUT("/plans/:id/entries/:entryId/servings", mealPlansHandler.SetMealPlanEntryServings)
+		app.DELETE("/plans/:id/entries/:entryId", mealPlansHandler.DeleteMealPlanEntry)
+
+		// Pantry endpoints, scoped to the user or X-Client-ID
+		app.GET("/pantry", pantryHandler.ListPantryItems)
+		app.POST("/pantry", pantryHandler.CreatePantryItem)
+		app.PUT("/pantry/:id", pantryHandler.UpdatePantryItem)
+		app.DELETE("/pantry/:id", pantryHandler.DeletePantryItem)
 	}
 
 	return router
@@ -0,0 +1,187 @@
+// Command backfill converts legacy free-text recipe data into the
+// structured tables introduced by later migrations. It only touches
+// recipes that have no structured rows yet, so it is safe to re-run. It
+// must be run after migrating: the API server does not start while any
+// recipe is still flagged as needing it.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/sonyadriko/masakyuk/internal/config"
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	dbConn, err := sql.Open("mysql", cfg.GetDatabaseURL())
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer dbConn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	repo := repository.NewRecipesRepository(dbConn, db.New(dbConn))
+
+	count, err := backfillIngredients(ctx, repo)
+	if err != nil {
+		log.Fatalf("Failed to backfill ingredients: %v", err)
+	}
+	log.Printf("Structured ingredients created for %d recipe(s)", count)
+
+	// Steps reference ingredients, so they are backfilled second
+	count, err = backfillSteps(ctx, repo)
+	if err != nil {
+		log.Fatalf("Failed to backfill steps: %v", err)
+	}
+	log.Printf("Instruction steps created for %d recipe(s)", count)
+
+	count, err = backfillAllergens(ctx, repo)
+	if err != nil {
+		log.Fatalf("Failed to backfill allergens: %v", err)
+	}
+	log.Printf("Allergens refreshed for %d recipe(s)", count)
+
+	count, err = backfillIngredientMatch(ctx, repo)
+	if err != nil {
+		log.Fatalf("Failed to backfill pantry match keys: %v", err)
+	}
+	log.Printf("Pantry match keys updated for %d ingredient(s)", count)
+
+	if err := repo.MarkRecipesBackfilled(ctx); err != nil {
+		log.Fatalf("Failed to mark recipes as backfilled: %v", err)
+	}
+}
+
+// backfillIngredients parses recipes.ingredients for recipes without
+// structured ingredient rows
+func backfillIngredients(ctx context.Context, repo repository.RecipesRepository) (int, error) {
+	rows, err := repo.ListRecipesWithoutIngredients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		items := service.ParseIngredients(row.Ingredients)
+		params := make([]repository.IngredientParams, len(items))
+		for i, item := range items {
+			params[i] = repository.IngredientParams{
+				Quantity: item.Quantity,
+				Unit:     item.Unit,
+				Name:     item.Name,
+				Note:     item.Note,
+				Group:    item.Group,
+			}
+			params[i].FoodName, params[i].Optional = service.IngredientMatch(item)
+		}
+
+		if err := repo.ReplaceRecipeIngredients(ctx, row.ID, params); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(rows), nil
+}
+
+// backfillSteps splits recipes.instructions into steps for recipes without
+// step rows, linking steps to the recipe's structured ingredients
+func backfillSteps(ctx context.Context, repo repository.RecipesRepository) (int, error) {
+	rows, err := repo.ListRecipesWithoutSteps(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		ingredientRows, err := repo.ListRecipeIngredients(ctx, []int32{row.ID})
+		if err != nil {
+			return 0, err
+		}
+		ingredients := make([]service.Ingredient, len(ingredientRows))
+		for i, ingredient := range ingredientRows {
+			ingredients[i] = service.Ingredient{Name: ingredient.Name}
+		}
+
+		steps := service.ParseInstructions(row.Instructions, ingredients)
+		params := make([]repository.StepParams, len(steps))
+		for i, step := range steps {
+			params[i] = repository.StepParams{
+				Instruction:         step.Text,
+				DurationSeconds:     step.DurationSeconds,
+				TemperatureCelsius:  step.TemperatureCelsius,
+				IngredientPositions: step.IngredientRefs,
+			}
+		}
+
+		if err := repo.ReplaceRecipeSteps(ctx, row.ID, params); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(rows), nil
+}
+
+// backfillAllergens re-derives allergens for every recipe with structured
+// ingredients, so rule changes are picked up on re-runs
+func backfillAllergens(ctx context.Context, repo repository.RecipesRepository) (int, error) {
+	rows, err := repo.ListAllRecipeIngredients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	byRecipe := make(map[int32][]service.Ingredient)
+	var order []int32
+	for _, row := range rows {
+		if _, ok := byRecipe[row.RecipeID]; !ok {
+			order = append(order, row.RecipeID)
+		}
+		byRecipe[row.RecipeID] = append(byRecipe[row.RecipeID], service.Ingredient{Name: row.Name})
+	}
+
+	for _, recipeID := range order {
+		allergens := service.DetectAllergens(byRecipe[recipeID])
+		if err := repo.ReplaceRecipeAllergens(ctx, recipeID, allergens); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(order), nil
+}
+
+// backfillIngredientMatch re-derives the pantry match columns of every
+// structured ingredient, updating the rows that changed
+func backfillIngredientMatch(ctx context.Context, repo repository.RecipesRepository) (int, error) {
+	rows, err := repo.ListAllRecipeIngredients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, row := range rows {
+		item := service.Ingredient{Name: row.Name}
+		if row.Note.Valid {
+			item.Note = &row.Note.String
+		}
+		foodName, optional := service.IngredientMatch(item)
+		if foodName == row.FoodName && optional == row.IsOptional {
+			continue
+		}
+		if err := repo.UpdateIngredientMatch(ctx, row.ID, foodName, optional); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,31 @@
+-- Migration: Move health tags into a join table so they can be filtered
+-- Created: 2025-12-19
+
+USE masakyuk;
+
+CREATE TABLE recipe_health_tags (
+    recipe_id INT NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (recipe_id, tag),
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recipe_health_tags_tag ON recipe_health_tags(tag);
+
+-- Split existing comma-separated values (up to 10 tags per recipe)
+INSERT IGNORE INTO recipe_health_tags (recipe_id, tag)
+SELECT r.id, TRIM(SUBSTRING_INDEX(SUBSTRING_INDEX(r.health_tags, ',', n.n), ',', -1))
+FROM recipes r
+JOIN (
+    SELECT 1 AS n UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT 4 UNION ALL SELECT 5
+    UNION ALL SELECT 6 UNION ALL SELECT 7 UNION ALL SELECT 8 UNION ALL SELECT 9 UNION ALL SELECT 10
+) n ON n.n <= 1 + LENGTH(r.health_tags) - LENGTH(REPLACE(r.health_tags, ',', ''))
+WHERE r.health_tags IS NOT NULL
+    AND TRIM(SUBSTRING_INDEX(SUBSTRING_INDEX(r.health_tags, ',', n.n), ',', -1)) <> '';
+
+DROP INDEX idx_health_tags ON recipes;
+ALTER TABLE recipes DROP COLUMN health_tags;
+
+-- Indexes for nutrition range filters
+CREATE INDEX idx_recipes_calories ON recipes(calories);
+CREATE INDEX idx_recipes_protein ON recipes(protein);
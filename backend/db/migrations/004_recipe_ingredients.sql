@@ -0,0 +1,23 @@
+-- Migration: Structured ingredients
+-- Created: 2025-12-22
+--
+-- recipes.ingredients is kept as a rendered, searchable copy of the list.
+-- Existing rows are converted by the backfill command (go run ./cmd/backfill),
+-- which is required after migrating (see 014_recipe_backfill_flag.sql).
+
+USE masakyuk;
+
+CREATE TABLE recipe_ingredients (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    recipe_id INT NOT NULL,
+    position INT NOT NULL,
+    quantity DECIMAL(10,3) DEFAULT NULL COMMENT 'Amount for the stored servings, NULL when unquantified',
+    unit VARCHAR(50) DEFAULT NULL,
+    name VARCHAR(255) NOT NULL,
+    note VARCHAR(255) DEFAULT NULL COMMENT 'Preparation note, e.g. minced, to taste',
+    group_name VARCHAR(100) DEFAULT NULL COMMENT 'Optional section, e.g. For spice paste',
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_recipe_ingredients_position (recipe_id, position)
+);
+
+CREATE INDEX idx_recipe_ingredients_name ON recipe_ingredients(name);
@@ -0,0 +1,27 @@
+-- Migration: Structured instruction steps
+-- Created: 2025-12-29
+--
+-- recipes.instructions is kept as a rendered "1. ...\n2. ..." copy.
+-- Existing rows are converted by the backfill command (go run ./cmd/backfill),
+-- which is required after migrating (see 014_recipe_backfill_flag.sql).
+
+USE masakyuk;
+
+CREATE TABLE recipe_steps (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    recipe_id INT NOT NULL,
+    position INT NOT NULL,
+    instruction TEXT NOT NULL,
+    duration_seconds INT DEFAULT NULL COMMENT 'Timer length for this step',
+    temperature_celsius INT DEFAULT NULL COMMENT 'Oven/oil temperature when stated',
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_recipe_steps_position (recipe_id, position)
+);
+
+-- Ingredients used by a step, by their position in recipe_ingredients
+CREATE TABLE recipe_step_ingredients (
+    step_id INT NOT NULL,
+    ingredient_position INT NOT NULL,
+    PRIMARY KEY (step_id, ingredient_position),
+    FOREIGN KEY (step_id) REFERENCES recipe_steps(id) ON DELETE CASCADE
+);
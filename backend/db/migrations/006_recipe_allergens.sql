@@ -0,0 +1,17 @@
+-- Migration: Allergens derived from structured ingredients
+-- Created: 2026-01-05
+--
+-- Rows are written by the API whenever ingredients change. Existing recipes
+-- are filled in by the backfill command (go run ./cmd/backfill), which is
+-- required after migrating (see 014_recipe_backfill_flag.sql).
+
+USE masakyuk;
+
+CREATE TABLE recipe_allergens (
+    recipe_id INT NOT NULL,
+    allergen VARCHAR(30) NOT NULL,
+    PRIMARY KEY (recipe_id, allergen),
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recipe_allergens_allergen ON recipe_allergens(allergen);
@@ -0,0 +1,7 @@
+-- Migration: Full-text search across title, description and ingredients
+-- Created: 2026-01-08
+
+USE masakyuk;
+
+DROP INDEX idx_recipes_title ON recipes;
+CREATE FULLTEXT INDEX idx_recipes_search ON recipes(title, description, ingredients);
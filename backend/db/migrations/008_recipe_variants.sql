@@ -0,0 +1,40 @@
+-- Migration: Many-to-many dietary variants per recipe
+-- Created: 2026-01-09
+--
+-- A recipe can now be e.g. Vegetarian, Gluten-Free and Halal at once.
+-- Existing single assignments are copied before recipes.variant_id is
+-- dropped. Variants stay protected from deletion while recipes use them.
+
+USE masakyuk;
+
+CREATE TABLE recipe_variants (
+    recipe_id INT NOT NULL,
+    variant_id INT NOT NULL,
+    PRIMARY KEY (recipe_id, variant_id),
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES variants(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_recipe_variants_variant_id ON recipe_variants(variant_id);
+
+INSERT INTO recipe_variants (recipe_id, variant_id)
+SELECT id, variant_id FROM recipes;
+
+-- The variant_id foreign key was created without a name, so the name
+-- MySQL generated for it is looked up instead of assumed
+SET @variant_fk = (
+    SELECT CONSTRAINT_NAME FROM information_schema.KEY_COLUMN_USAGE
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'recipes'
+      AND COLUMN_NAME = 'variant_id'
+      AND REFERENCED_TABLE_NAME = 'variants'
+    LIMIT 1
+);
+SET @drop_variant_fk = IF(@variant_fk IS NULL, 'DO 0',
+    CONCAT('ALTER TABLE recipes DROP FOREIGN KEY `', @variant_fk, '`'));
+PREPARE drop_variant_fk FROM @drop_variant_fk;
+EXECUTE drop_variant_fk;
+DEALLOCATE PREPARE drop_variant_fk;
+
+DROP INDEX idx_recipes_variant_id ON recipes;
+ALTER TABLE recipes DROP COLUMN variant_id;
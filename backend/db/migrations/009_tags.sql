@@ -0,0 +1,25 @@
+-- Migration: Free-form recipe tags
+-- Created: 2026-01-10
+--
+-- Tags are lowercase slugs such as "one-pot" or "street-food". They are
+-- created on first use when a recipe is saved; deleting a tag removes it
+-- from every recipe.
+
+USE masakyuk;
+
+CREATE TABLE tags (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE recipe_tags (
+    recipe_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY (recipe_id, tag_id),
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recipe_tags_tag_id ON recipe_tags(tag_id);
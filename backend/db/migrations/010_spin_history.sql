@@ -0,0 +1,17 @@
+-- Migration: Per-client spin history
+-- Created: 2026-01-11
+--
+-- Clients identify themselves with the X-Client-ID header. Spins are
+-- recorded so POST /api/spin can avoid recently returned recipes.
+
+USE masakyuk;
+
+CREATE TABLE spin_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    recipe_id INT NOT NULL,
+    spun_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_spin_history_client ON spin_history(client_id, spun_at);
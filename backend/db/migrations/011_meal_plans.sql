@@ -0,0 +1,31 @@
+-- Migration: Stored meal plans
+-- Created: 2026-01-13
+--
+-- A meal plan covers a date range. Each entry puts a recipe in one meal
+-- (e.g. "lunch") of one day, optionally overriding its servings. Deleting
+-- a recipe removes its entries from every plan.
+
+USE masakyuk;
+
+CREATE TABLE meal_plans (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE meal_plan_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    meal_plan_id INT NOT NULL,
+    plan_date DATE NOT NULL,
+    meal VARCHAR(20) NOT NULL,
+    recipe_id INT NOT NULL,
+    servings INT,
+    UNIQUE KEY uq_meal_plan_entries_slot (meal_plan_id, plan_date, meal),
+    FOREIGN KEY (meal_plan_id) REFERENCES meal_plans(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_meal_plan_entries_recipe_id ON meal_plan_entries(recipe_id);
@@ -0,0 +1,24 @@
+-- Migration: Per-client pantry inventory
+-- Created: 2026-01-15
+--
+-- Clients identify themselves with the X-Client-ID header, as for spin
+-- history. Each item is an ingredient on hand, stored under its
+-- normalised name, with an optional quantity and expiry date. Recipes can
+-- be annotated with how much of them the pantry covers, and spins can
+-- prefer recipes using items that expire soon.
+
+USE masakyuk;
+
+CREATE TABLE pantry_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    quantity DECIMAL(10,3),
+    unit VARCHAR(20),
+    expires_on DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_pantry_items_client_name (client_id, name)
+);
+
+CREATE INDEX idx_pantry_items_expiry ON pantry_items(client_id, expires_on);
@@ -0,0 +1,14 @@
+-- Migration: Track recipes awaiting the structured-data backfill
+-- Created: 2026-01-17
+--
+-- Recipes written straight to the database, including every recipe that
+-- existed before migrations 004-006 and the seed data, only have the
+-- free-text ingredients and instructions. They are flagged until the
+-- backfill command (go run ./cmd/backfill) has created their ingredient,
+-- step and allergen rows. The API server refuses to start while any recipe
+-- is flagged. Recipes saved through the API are written fully structured.
+
+USE masakyuk;
+
+ALTER TABLE recipes
+    ADD COLUMN needs_backfill BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,15 @@
+-- Migration: Meal plan owners
+-- Created: 2026-01-18
+--
+-- Meal plans belong to the client that created them, identified by the
+-- X-Client-ID header as for spin history and the pantry. Other clients
+-- cannot see or change them. Plans saved before this migration have no
+-- owner and are hidden until one is set, e.g.
+-- UPDATE meal_plans SET client_id = '<client id>' WHERE client_id = '';
+
+USE masakyuk;
+
+ALTER TABLE meal_plans
+    ADD COLUMN client_id VARCHAR(64) NOT NULL DEFAULT '' AFTER id;
+
+CREATE INDEX idx_meal_plans_client ON meal_plans(client_id, start_date);
@@ -0,0 +1,19 @@
+-- Migration: Pantry match keys on structured ingredients
+-- Created: 2026-01-19
+--
+-- Pantry searches and pantry spins count each recipe's missing ingredients
+-- in SQL. food_name is the ingredient name lowercased with every word
+-- singularized, and is_optional marks staples and garnishes a pantry does
+-- not need to cover. The API derives both when it saves a recipe. Existing
+-- rows are filled in by the backfill command, so every recipe is flagged
+-- again (see 014_recipe_backfill_flag.sql).
+
+USE masakyuk;
+
+ALTER TABLE recipe_ingredients
+    ADD COLUMN food_name VARCHAR(255) NOT NULL DEFAULT '' AFTER name,
+    ADD COLUMN is_optional BOOLEAN NOT NULL DEFAULT FALSE AFTER note;
+
+CREATE INDEX idx_recipe_ingredients_pantry ON recipe_ingredients(recipe_id, is_optional, food_name);
+
+UPDATE recipes SET needs_backfill = TRUE;
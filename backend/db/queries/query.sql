@@ -9,101 +9,24 @@ SELECT
     r.skill_level,
     r.category_id,
     c.name as category_name,
-    r.variant_id,
-    v.name as variant_name,
     r.image_url,
     r.servings,
     r.calories,
     r.protein,
     r.carbs,
     r.fat,
-    r.health_tags,
     r.created_at,
     r.updated_at
 FROM recipes r
 INNER JOIN categories c ON r.category_id = c.id
-INNER JOIN variants v ON r.variant_id = v.id
 WHERE r.id = ?;
 
--- name: ListRecipes :many
-SELECT 
-    r.id, r.title, r.description,
-    r.cooking_time, r.skill_level, r.servings, r.image_url,
-    r.calories, r.protein, r.carbs, r.fat, r.health_tags,
-    r.category_id, c.name as category_name,
-    r.variant_id, v.name as variant_name
-FROM recipes r
-JOIN categories c ON r.category_id = c.id
-JOIN variants v ON r.variant_id = v.id
-WHERE
-    (? IS NULL OR CONCAT(r.title, r.description, r.ingredients) LIKE CONCAT('%', ?, '%'))
-    AND (? IS NULL OR r.skill_level = ?)
-    AND (? IS NULL OR r.variant_id = ?)
-    AND (? IS NULL OR r.category_id = ?)
-    AND (? IS NULL OR r.cooking_time <= ?)
-ORDER BY r.created_at DESC
-LIMIT ? OFFSET ?;
-
--- name: CountRecipes :one
-SELECT COUNT(*)
-FROM recipes r
-WHERE 
-    (? IS NULL OR r.title LIKE CONCAT('%', ?, '%'))
-    AND (? IS NULL OR r.skill_level = ?)
-    AND (? IS NULL OR r.variant_id = ?)
-    AND (? IS NULL OR r.category_id = ?)
-    AND (? IS NULL OR r.cooking_time <= ?);
-
--- name: GetRandomRecipe :one
-SELECT 
-    r.id,
-    r.title,
-    r.description,
-    r.ingredients,
-    r.instructions,
-    r.cooking_time,
-    r.skill_level,
-    r.category_id,
-    c.name as category_name,
-    r.variant_id,
-    v.name as variant_name,
-    r.image_url,
-    r.servings,
-    r.calories,
-    r.protein,
-    r.carbs,
-    r.fat,
-    r.health_tags,
-    r.created_at,
-    r.updated_at
-FROM recipes r
-INNER JOIN categories c ON r.category_id = c.id
-INNER JOIN variants v ON r.variant_id = v.id
-WHERE 
-    (? IS NULL OR r.title LIKE CONCAT('%', ?, '%'))
-    AND (? IS NULL OR r.skill_level = ?)
-    AND (? IS NULL OR r.variant_id = ?)
-    AND (? IS NULL OR r.category_id = ?)
-    AND (? IS NULL OR r.cooking_time <= ?)
-ORDER BY RAND()
-LIMIT 1;
-
--- name: ListCategories :many
-SELECT id, name, description, created_at, updated_at
-FROM categories
-ORDER BY name;
-
--- name: ListVariants :many
-SELECT id, name, description, created_at, updated_at
-FROM variants
-ORDER BY name;
-
 -- name: CreateRecipe :execresult
 INSERT INTO recipes (
     title, description, ingredients, instructions, 
-    cooking_time, skill_level, category_id, variant_id, 
-    image_url, servings, calories, protein, carbs, fat, health_tags
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+    cooking_time, skill_level, category_id,
+    image_url, servings, calories, protein, carbs, fat, needs_backfill
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, FALSE);
 
 -- name: UpdateRecipe :exec
 UPDATE recipes SET
@@ -114,16 +37,394 @@ UPDATE recipes SET
     cooking_time = ?,
     skill_level = ?,
     category_id = ?,
-    variant_id = ?,
     image_url = ?,
     servings = ?,
     calories = ?,
     protein = ?,
     carbs = ?,
     fat = ?,
-    health_tags = ?,
+    needs_backfill = FALSE,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
 -- name: DeleteRecipe :exec
 DELETE FROM recipes WHERE id = ?;
+
+-- name: ListRecipeHealthTags :many
+SELECT recipe_id, tag
+FROM recipe_health_tags
+WHERE recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY recipe_id, tag;
+
+-- name: AddRecipeHealthTag :exec
+INSERT INTO recipe_health_tags (recipe_id, tag) VALUES (?, ?);
+
+-- name: DeleteRecipeHealthTags :exec
+DELETE FROM recipe_health_tags WHERE recipe_id = ?;
+
+-- name: ListRecipeAllergens :many
+SELECT recipe_id, allergen
+FROM recipe_allergens
+WHERE recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY recipe_id, allergen;
+
+-- name: AddRecipeAllergen :exec
+INSERT INTO recipe_allergens (recipe_id, allergen) VALUES (?, ?);
+
+-- name: DeleteRecipeAllergens :exec
+DELETE FROM recipe_allergens WHERE recipe_id = ?;
+
+-- name: ListRecipeVariants :many
+SELECT rv.recipe_id, rv.variant_id, v.name
+FROM recipe_variants rv
+INNER JOIN variants v ON rv.variant_id = v.id
+WHERE rv.recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY rv.recipe_id, v.name;
+
+-- name: ListVariantNames :many
+SELECT id, name FROM variants
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: AddRecipeVariant :exec
+INSERT INTO recipe_variants (recipe_id, variant_id) VALUES (?, ?);
+
+-- name: DeleteRecipeVariants :exec
+DELETE FROM recipe_variants WHERE recipe_id = ?;
+
+-- name: ListRecipeTags :many
+SELECT rt.recipe_id, t.name
+FROM recipe_tags rt
+INNER JOIN tags t ON rt.tag_id = t.id
+WHERE rt.recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY rt.recipe_id, t.name;
+
+-- name: EnsureTag :exec
+INSERT INTO tags (name) VALUES (?)
+ON DUPLICATE KEY UPDATE name = name;
+
+-- name: AddRecipeTag :exec
+INSERT INTO recipe_tags (recipe_id, tag_id)
+SELECT ?, id FROM tags WHERE name = ?;
+
+-- name: DeleteRecipeTags :exec
+DELETE FROM recipe_tags WHERE recipe_id = ?;
+
+-- name: ListCategories :many
+SELECT
+    c.id, c.name, c.description, c.created_at, c.updated_at,
+    COUNT(r.id) AS recipe_count
+FROM categories c
+LEFT JOIN recipes r ON r.category_id = c.id
+GROUP BY c.id, c.name, c.description, c.created_at, c.updated_at
+ORDER BY c.name;
+
+-- name: GetCategoryByID :one
+SELECT
+    c.id, c.name, c.description, c.created_at, c.updated_at,
+    COUNT(r.id) AS recipe_count
+FROM categories c
+LEFT JOIN recipes r ON r.category_id = c.id
+WHERE c.id = ?
+GROUP BY c.id, c.name, c.description, c.created_at, c.updated_at;
+
+-- name: CreateCategory :execresult
+INSERT INTO categories (name, description) VALUES (?, ?);
+
+-- name: UpdateCategory :exec
+UPDATE categories SET
+    name = ?,
+    description = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteCategory :exec
+DELETE FROM categories WHERE id = ?;
+
+-- name: ListVariants :many
+SELECT
+    v.id, v.name, v.description, v.created_at, v.updated_at,
+    COUNT(rv.recipe_id) AS recipe_count
+FROM variants v
+LEFT JOIN recipe_variants rv ON rv.variant_id = v.id
+GROUP BY v.id, v.name, v.description, v.created_at, v.updated_at
+ORDER BY v.name;
+
+-- name: GetVariantByID :one
+SELECT
+    v.id, v.name, v.description, v.created_at, v.updated_at,
+    COUNT(rv.recipe_id) AS recipe_count
+FROM variants v
+LEFT JOIN recipe_variants rv ON rv.variant_id = v.id
+WHERE v.id = ?
+GROUP BY v.id, v.name, v.description, v.created_at, v.updated_at;
+
+-- name: CreateVariant :execresult
+INSERT INTO variants (name, description) VALUES (?, ?);
+
+-- name: UpdateVariant :exec
+UPDATE variants SET
+    name = ?,
+    description = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteVariant :exec
+DELETE FROM variants WHERE id = ?;
+
+-- name: ListRecipeIngredients :many
+SELECT id, recipe_id, position, quantity, unit, name, food_name, note, is_optional, group_name
+FROM recipe_ingredients
+WHERE recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY recipe_id, position;
+
+-- name: ListAllRecipeIngredients :many
+SELECT id, recipe_id, position, quantity, unit, name, food_name, note, is_optional, group_name
+FROM recipe_ingredients
+ORDER BY recipe_id, position;
+
+-- name: AddRecipeIngredient :exec
+INSERT INTO recipe_ingredients (
+    recipe_id, position, quantity, unit, name, food_name, note, is_optional, group_name
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: UpdateRecipeIngredientMatch :exec
+UPDATE recipe_ingredients SET food_name = ?, is_optional = ? WHERE id = ?;
+
+-- name: DeleteRecipeIngredients :exec
+DELETE FROM recipe_ingredients WHERE recipe_id = ?;
+
+-- name: ListRecipesWithoutIngredients :many
+SELECT r.id, r.ingredients
+FROM recipes r
+WHERE NOT EXISTS (
+    SELECT 1 FROM recipe_ingredients i WHERE i.recipe_id = r.id
+)
+ORDER BY r.id;
+
+-- name: CountRecipesNeedingBackfill :one
+SELECT COUNT(*) FROM recipes WHERE needs_backfill;
+
+-- name: MarkRecipesBackfilled :exec
+UPDATE recipes SET needs_backfill = FALSE WHERE needs_backfill;
+
+-- name: ListRecipeSteps :many
+SELECT id, recipe_id, position, instruction, duration_seconds, temperature_celsius
+FROM recipe_steps
+WHERE recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY recipe_id, position;
+
+-- name: ListRecipeStepIngredients :many
+SELECT si.step_id, si.ingredient_position
+FROM recipe_step_ingredients si
+JOIN recipe_steps s ON si.step_id = s.id
+WHERE s.recipe_id IN (sqlc.slice('recipe_ids'))
+ORDER BY si.step_id, si.ingredient_position;
+
+-- name: AddRecipeStep :execresult
+INSERT INTO recipe_steps (
+    recipe_id, position, instruction, duration_seconds, temperature_celsius
+) VALUES (?, ?, ?, ?, ?);
+
+-- name: AddRecipeStepIngredient :exec
+INSERT INTO recipe_step_ingredients (step_id, ingredient_position) VALUES (?, ?);
+
+-- name: DeleteRecipeSteps :exec
+DELETE FROM recipe_steps WHERE recipe_id = ?;
+
+-- name: ListRecipesWithoutSteps :many
+SELECT r.id, r.instructions
+FROM recipes r
+WHERE NOT EXISTS (
+    SELECT 1 FROM recipe_steps s WHERE s.recipe_id = r.id
+)
+ORDER BY r.id;
+
+-- name: ListTags :many
+SELECT
+    t.id, t.name, t.created_at, t.updated_at,
+    COUNT(rt.recipe_id) AS recipe_count
+FROM tags t
+LEFT JOIN recipe_tags rt ON rt.tag_id = t.id
+GROUP BY t.id, t.name, t.created_at, t.updated_at
+ORDER BY t.name;
+
+-- name: GetTagByID :one
+SELECT
+    t.id, t.name, t.created_at, t.updated_at,
+    COUNT(rt.recipe_id) AS recipe_count
+FROM tags t
+LEFT JOIN recipe_tags rt ON rt.tag_id = t.id
+WHERE t.id = ?
+GROUP BY t.id, t.name, t.created_at, t.updated_at;
+
+-- name: AutocompleteTags :many
+SELECT
+    t.id, t.name, t.created_at, t.updated_at,
+    COUNT(rt.recipe_id) AS recipe_count
+FROM tags t
+LEFT JOIN recipe_tags rt ON rt.tag_id = t.id
+WHERE t.name LIKE CONCAT(sqlc.arg('prefix'), '%')
+GROUP BY t.id, t.name, t.created_at, t.updated_at
+ORDER BY recipe_count DESC, t.name
+LIMIT ?;
+
+-- name: CreateTag :execresult
+INSERT INTO tags (name) VALUES (?);
+
+-- name: UpdateTag :exec
+UPDATE tags SET
+    name = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteTag :exec
+DELETE FROM tags WHERE id = ?;
+
+-- name: RecordSpin :exec
+INSERT INTO spin_history (client_id, recipe_id) VALUES (?, ?);
+
+-- name: ListLastSpins :many
+SELECT recipe_id FROM spin_history
+WHERE client_id = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: ListSpinsSince :many
+SELECT recipe_id, MAX(id) AS last_id
+FROM spin_history
+WHERE client_id = ? AND spun_at >= NOW() - INTERVAL sqlc.arg('days') DAY
+GROUP BY recipe_id
+ORDER BY last_id DESC;
+
+-- name: ListMealPlans :many
+SELECT
+    p.id, p.name, p.start_date, p.end_date, p.created_at, p.updated_at,
+    COUNT(e.id) AS entry_count
+FROM meal_plans p
+LEFT JOIN meal_plan_entries e ON e.meal_plan_id = p.id
+WHERE p.client_id = ?
+GROUP BY p.id, p.name, p.start_date, p.end_date, p.created_at, p.updated_at
+ORDER BY p.start_date DESC, p.id DESC;
+
+-- name: GetMealPlanByID :one
+SELECT * FROM meal_plans WHERE id = ?;
+
+-- name: CreateMealPlan :execresult
+INSERT INTO meal_plans (client_id, name, start_date, end_date) VALUES (?, ?, ?, ?);
+
+-- name: UpdateMealPlan :exec
+UPDATE meal_plans SET
+    name = ?,
+    start_date = ?,
+    end_date = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: TouchMealPlan :exec
+UPDATE meal_plans SET updated_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: TouchMealPlansWithRecipe :exec
+UPDATE meal_plans SET updated_at = CURRENT_TIMESTAMP
+WHERE id IN (SELECT meal_plan_id FROM meal_plan_entries WHERE recipe_id = ?);
+
+-- name: CountMealPlanEntriesWithRecipe :one
+SELECT COUNT(*) FROM meal_plan_entries WHERE recipe_id = ?;
+
+-- name: DeleteMealPlan :exec
+DELETE FROM meal_plans WHERE id = ?;
+
+-- name: CountMealPlanEntriesOutside :one
+SELECT COUNT(*) FROM meal_plan_entries
+WHERE meal_plan_id = sqlc.arg('meal_plan_id')
+  AND (plan_date < sqlc.arg('start_date') OR plan_date > sqlc.arg('end_date'));
+
+-- name: ListMealPlanEntries :many
+SELECT
+    e.id, e.meal_plan_id, e.plan_date, e.meal, e.recipe_id, e.servings,
+    r.title AS recipe_title, r.cooking_time, r.servings AS recipe_servings
+FROM meal_plan_entries e
+JOIN recipes r ON r.id = e.recipe_id
+WHERE e.meal_plan_id = ?
+ORDER BY e.plan_date, e.meal;
+
+-- name: GetMealPlanEntryByID :one
+SELECT * FROM meal_plan_entries WHERE id = ?;
+
+-- name: GetMealPlanEntryBySlot :one
+SELECT * FROM meal_plan_entries
+WHERE meal_plan_id = ? AND plan_date = ? AND meal = ?;
+
+-- name: UpsertMealPlanEntry :exec
+INSERT INTO meal_plan_entries (meal_plan_id, plan_date, meal, recipe_id, servings)
+VALUES (?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE recipe_id = VALUES(recipe_id), servings = VALUES(servings);
+
+-- name: MoveMealPlanEntry :exec
+UPDATE meal_plan_entries SET plan_date = ?, meal = ? WHERE id = ?;
+
+-- name: UpdateMealPlanEntryServings :exec
+UPDATE meal_plan_entries SET servings = ? WHERE id = ?;
+
+-- name: DeleteMealPlanEntry :exec
+DELETE FROM meal_plan_entries WHERE id = ?;
+
+-- name: ListPantryItems :many
+SELECT * FROM pantry_items
+WHERE client_id = ?
+ORDER BY expires_on IS NULL, expires_on, name;
+
+-- name: GetPantryItemByID :one
+SELECT * FROM pantry_items WHERE id = ?;
+
+-- name: CreatePantryItem :execresult
+INSERT INTO pantry_items (client_id, name, quantity, unit, expires_on)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: UpdatePantryItem :exec
+UPDATE pantry_items SET
+    name = ?,
+    quantity = ?,
+    unit = ?,
+    expires_on = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeletePantryItem :exec
+DELETE FROM pantry_items WHERE id = ?;
+
+-- name: ListStockedPantryItems :many
+SELECT name FROM pantry_items
+WHERE client_id = ?
+  AND (quantity IS NULL OR quantity > 0)
+  AND (expires_on IS NULL OR expires_on >= CURDATE())
+ORDER BY name;
+
+-- name: ListExpiringPantryItems :many
+SELECT name FROM pantry_items
+WHERE client_id = ?
+  AND (quantity IS NULL OR quantity > 0)
+  AND expires_on BETWEEN CURDATE() AND CURDATE() + INTERVAL sqlc.arg('days') DAY
+ORDER BY expires_on, name;
+
+-- name: CreateUser :execresult
+INSERT INTO users (email, password_hash) VALUES (?, ?);
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = ?;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = ?;
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES (?, ?, ?);
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_tokens WHERE token_hash = ?;
+
+-- name: RevokeRefreshToken :execrows
+UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND revoked_at IS NULL;
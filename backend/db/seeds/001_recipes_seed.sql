@@ -20,7 +20,7 @@ INSERT INTO variants (id, name, description) VALUES
 ON DUPLICATE KEY UPDATE name=VALUES(name);
 
 -- Insert Indonesian Recipes
-INSERT INTO recipes (title, description, ingredients, instructions, cooking_time, skill_level, category_id, variant_id, servings) VALUES
+INSERT INTO recipes (title, description, ingredients, instructions, cooking_time, skill_level, category_id, servings) VALUES
 -- Beginner Level Recipes
 ('Nasi Goreng', 'Classic Indonesian fried rice with aromatic spices and vegetables', 
 '2 cups cooked rice (day-old), 2 eggs, 1 cup mixed vegetables (carrots, peas, cabbage), 3 cloves garlic (minced), 2 shallots (sliced), 2 tbsp sweet soy sauce (kecap manis), 1 tbsp soy sauce, 1 tsp shrimp paste, 2 tbsp cooking oil, Salt and pepper to taste, Green onions for garnish',
@@ -35,7 +35,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 9. Add scrambled eggs back in
 10. Season with salt and pepper
 11. Garnish with green onions and serve hot',
-20, 'beginner', 1, 4, 2),
+20, 'beginner', 1, 2),
 
 ('Mie Goreng', 'Savory Indonesian stir-fried noodles with vegetables and protein',
 '300g egg noodles, 200g chicken breast (sliced), 1 cup cabbage (shredded), 1 carrot (julienned), 3 cloves garlic (minced), 2 shallots (sliced), 2 tbsp sweet soy sauce, 1 tbsp soy sauce, 1 tsp oyster sauce, 2 eggs, 3 tbsp cooking oil, Salt and pepper, Fried shallots for topping',
@@ -49,7 +49,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Add scrambled eggs back in
 9. Season to taste
 10. Serve topped with fried shallots',
-25, 'beginner', 1, 4, 3),
+25, 'beginner', 1, 3),
 
 ('Soto Ayam', 'Traditional Indonesian chicken soup with turmeric and aromatic spices',
 '500g chicken pieces, 2 liters water, 3 stalks lemongrass (bruised), 4 kaffir lime leaves, 3 cm galangal (sliced), 5 cloves garlic, 6 shallots, 2 tsp turmeric powder, 1 tsp coriander powder, 200g bean sprouts, 100g glass noodles, 2 hard-boiled eggs (halved), Fried shallots, Lime wedges, Salt to taste',
@@ -63,7 +63,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Prepare bowls with noodles, bean sprouts, shredded chicken
 9. Pour hot soup over
 10. Top with eggs, fried shallots, and serve with lime',
-45, 'beginner', 1, 4, 4),
+45, 'beginner', 1, 4),
 
 ('Gado-Gado', 'Indonesian vegetable salad with peanut sauce',
 '200g cabbage (boiled), 150g bean sprouts (blanched), 2 potatoes (boiled, cubed), 2 eggs (hard-boiled, halved), 100g green beans (blanched), 1 cucumber (sliced), 2 tomatoes (sliced), Fried tofu and tempeh, Prawn crackers, For peanut sauce: 200g roasted peanuts, 3 cloves garlic, 2 red chilies, 2 tbsp palm sugar, 1 tbsp tamarind paste, 200ml water, Salt to taste',
@@ -74,7 +74,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 5. Add tofu, tempeh, and eggs
 6. Pour peanut sauce generously over vegetables
 7. Serve with prawn crackers on the side',
-30, 'beginner', 1, 2, 4),
+30, 'beginner', 1, 4),
 
 -- Intermediate Level Recipes
 ('Rendang Daging', 'Rich and tender beef slow-cooked in coconut milk and spices',
@@ -89,7 +89,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Season with salt and sugar
 9. Continue cooking until beef is very tender
 10. Serve with steamed rice',
-180, 'intermediate', 1, 4, 6),
+180, 'intermediate', 1, 6),
 
 ('Ayam Bakar Taliwang', 'Grilled spicy chicken from Lombok with special sambal',
 '1 whole chicken (cut into pieces), 4 tbsp lime juice, Salt, For spice paste: 10 red chilies, 5 bird\'s eye chilies, 6 shallots, 4 cloves garlic, 2 tomatoes, 1 tsp shrimp paste, 2 tbsp palm sugar, 3 tbsp cooking oil',
@@ -102,7 +102,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Continue grilling until fully cooked and slightly charred
 8. Baste occasionally with spice paste
 9. Serve hot with plecing kangkung',
-60, 'intermediate', 1, 4, 4),
+60, 'intermediate', 1, 4),
 
 ('Sate Ayam', 'Indonesian chicken satay with peanut sauce',
 '500g chicken thigh (cubed), 20 bamboo skewers (soaked), For marinade: 3 cloves garlic (minced), 2 tbsp sweet soy sauce, 1 tbsp cooking oil, 1 tsp coriander powder, For peanut sauce: 200g roasted peanuts, 3 cloves garlic, 3 red chilies, 2 tbsp sweet soy sauce, 1 tbsp tamarind paste, 100ml water, Salt and sugar',
@@ -115,7 +115,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Grill satay over charcoal, basting with oil
 8. Cook until charred and cooked through
 9. Serve with peanut sauce, rice cakes, and pickles',
-40, 'intermediate', 1, 4, 4),
+40, 'intermediate', 1, 4),
 
 ('Nasi Uduk', 'Fragrant coconut rice cooked with aromatic spices',
 '2 cups jasmine rice, 400ml coconut milk, 200ml water, 2 pandan leaves (knotted), 2 stalks lemongrass (bruised), 3 kaffir lime leaves, 2 cm galangal (sliced), 1 tsp salt, Accompaniments: fried chicken, sambal, fried shallots, cucumber, omelet',
@@ -127,7 +127,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 6. Remove aromatics before serving
 7. Fluff rice gently
 8. Serve with fried chicken, sambal, and accompaniments',
-35, 'intermediate', 1, 4, 4),
+35, 'intermediate', 1, 4),
 
 ('Bakso', 'Indonesian meatball soup with noodles',
 'For meatballs: 500g ground beef, 100g tapioca starch, 3 cloves garlic (minced), 1 egg white, Ice water, Salt and pepper, For broth: 2 liters beef stock, 3 cloves garlic (fried), 2 stalks celery, Salt and pepper, For serving: Egg noodles, Bok choy, Fried shallots, Sambal',
@@ -141,7 +141,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Cook noodles and bok choy separately
 9. Assemble: noodles, bok choy, meatballs in bowl
 10. Pour hot broth, top with fried shallots and sambal',
-50, 'intermediate', 1, 4, 4),
+50, 'intermediate', 1, 4),
 
 -- Advanced Level Recipes
 ('Rawon', 'East Javanese black beef soup with keluak nuts',
@@ -156,7 +156,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Season with salt and sugar
 9. Cook until flavors meld
 10. Serve with rice, bean sprouts, salted eggs, and sambal',
-150, 'advanced', 1, 4, 6),
+150, 'advanced', 1, 6),
 
 ('Gulai Kambing', 'Spicy goat curry with rich coconut milk gravy',
 '1kg goat meat (bone-in pieces), 800ml coconut milk, 3 potatoes (cubed), For spice paste: 10 shallots, 6 cloves garlic, 5 red chilies, 3 cm ginger, 3 cm galangal, 3 cm turmeric, 2 tsp coriander, 1 tsp cumin, 4 cardamom pods, 2 star anise, 1 cinnamon stick, 4 kaffir lime leaves, 2 stalks lemongrass, Salt and sugar',
@@ -170,7 +170,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Add potatoes, cook until soft
 9. Season with salt and sugar
 10. Serve with rice or roti',
-120, 'advanced', 1, 4, 6),
+120, 'advanced', 1, 6),
 
 ('Pempek Palembang', 'South Sumatran fish cake with sweet and sour sauce',
 'For pempek: 500g Spanish mackerel (ground), 200g tapioca starch, 300ml ice water, 3 cloves garlic (minced), 2 eggs, Salt and sugar, For cuko sauce: 200g palm sugar, 100ml tamarind water, 5 red chilies (blended), 3 cloves garlic (minced), 1 liter water, Salt, Accompaniments: Cucumber, yellow noodles',
@@ -183,7 +183,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Add blended chilies and garlic
 8. Simmer until thick, season with salt
 9. Serve pempek with cuko sauce, cucumber, and noodles',
-90, 'advanced', 1, 4, 6),
+90, 'advanced', 1, 6),
 
 -- More Beginner Recipes
 ('Tempe Goreng', 'Crispy fried tempeh with spiced batter',
@@ -194,7 +194,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 4. Fry tempeh until golden and crispy
 5. Drain on paper towels
 6. Serve hot with sambal and rice',
-15, 'beginner', 1, 2, 4),
+15, 'beginner', 1, 4),
 
 ('Capcay', 'Indonesian stir-fried mixed vegetables',
 '200g cabbage (chopped), 1 carrot (sliced), 100g cauliflower, 100g broccoli, 50g baby corn, 3 cloves garlic (minced), 2 tbsp oyster sauce, 1 tbsp soy sauce, 200ml water, 1 tbsp cornstarch, Salt and pepper, 2 tbsp cooking oil',
@@ -207,7 +207,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Cook until vegetables are tender-crisp
 8. Season with salt and pepper
 9. Serve hot with rice',
-20, 'beginner', 1, 2, 3),
+20, 'beginner', 1, 3),
 
 ('Perkedel Kentang', 'Indonesian potato fritters',
 '500g potatoes (boiled, mashed), 2 eggs (1 for mixture, 1 for coating), 3 cloves garlic (minced), 2 shallots (minced), 2 stalks green onions (chopped), 1 stalk celery (chopped), Salt and pepper, Oil for frying',
@@ -218,7 +218,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 5. Dip patties in egg
 6. Fry until golden brown on both sides
 7. Drain and serve hot',
-25, 'beginner', 1, 4, 4),
+25, 'beginner', 1, 4),
 
 -- More Intermediate Recipes
 ('Sop Buntut', 'Oxtail soup with vegetables',
@@ -233,7 +233,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Add tomato and celery
 9. Season with salt and pepper
 10. Serve hot with fried shallots and rice',
-180, 'intermediate', 1, 4, 4),
+180, 'intermediate', 1, 4),
 
 ('Ikan Bakar Bumbu Kuning', 'Grilled fish with yellow spice paste',
 '1 whole fish (snapper or mackerel), 5 tbsp lime juice, Salt, For spice paste: 5 shallots, 3 cloves garlic, 3 cm turmeric, 2 cm ginger, 3 candlenuts, 2 red chilies, 1 tsp coriander, 2 tbsp oil, Banana leaves for wrapping',
@@ -245,7 +245,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 6. Wrap in banana leaves
 7. Grill over charcoal for 15 minutes each side
 8. Unwrap and serve with sambal',
-45, 'intermediate', 1, 4, 3),
+45, 'intermediate', 1, 3),
 
 ('Opor Ayam', 'Chicken in coconut milk curry',
 '1kg chicken pieces, 600ml coconut milk, 3 kaffir lime leaves, 2 stalks lemongrass, 2 cm galangal, For spice paste: 6 shallots, 4 cloves garlic, 3 candlenuts, 2 cm ginger, 1 tsp coriander, 1/2 tsp cumin, 1/2 tsp white pepper, Salt and sugar',
@@ -259,7 +259,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 8. Season with salt and sugar
 9. Cook until chicken is tender and sauce thickens
 10. Serve with rice or ketupat',
-60, 'intermediate', 1, 4, 5),
+60, 'intermediate', 1, 5),
 
 ('Sayur Asem', 'Tamarind vegetable soup',
 '200g melinjo leaves, 100g long beans (cut), 1 corn (cut into pieces), 100g peanuts (boiled), 1 chayote (cubed), 2 tomatoes (quartered), 3 tbsp tamarind paste, 1 liter water, For spice paste: 5 shallots, 3 red chilies, 1 tsp shrimp paste, Palm sugar, Salt',
@@ -272,7 +272,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Cook until all vegetables are tender
 8. Adjust seasoning
 9. Serve hot with rice',
-35, 'beginner', 1, 2, 4),
+35, 'beginner', 1, 4),
 
 -- Desserts
 ('Es Cendol', 'Indonesian iced dessert with coconut milk and palm sugar syrup',
@@ -286,7 +286,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Assemble: cendol, palm sugar syrup, coconut milk, ice
 8. Add jackfruit if desired
 9. Serve immediately',
-30, 'intermediate', 4, 3, 4),
+30, 'intermediate', 4, 4),
 
 ('Klepon', 'Sweet rice cake balls filled with palm sugar',
 '250g glutinous rice flour, 150ml pandan juice, 100g palm sugar (chopped small), 200g grated coconut (steamed with salt), Pinch of salt',
@@ -299,7 +299,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 7. Cook until they float
 8. Remove and roll in grated coconut
 9. Serve at room temperature',
-40, 'intermediate', 4, 3, 20),
+40, 'intermediate', 4, 20),
 
 ('Pisang Goreng', 'Crispy fried banana fritters',
 '6 ripe plantains, 100g rice flour, 50g all-purpose flour, 2 tbsp sugar, 1/4 tsp salt, 150ml water, Oil for frying',
@@ -311,7 +311,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 6. Fry until golden and crispy
 7. Drain on paper towels
 8. Serve warm',
-20, 'beginner', 4, 3, 6),
+20, 'beginner', 4, 6),
 
 -- More Indonesian Favorites
 ('Nasi Kuning', 'Yellow turmeric rice for celebrations',
@@ -324,7 +324,7 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 6. Remove aromatics
 7. Fluff rice gently
 8. Serve with various side dishes',
-30, 'beginner', 1, 4, 6),
+30, 'beginner', 1, 6),
 
 ('Pecel Lele', 'Fried catfish with sambal',
 '4 catfish (cleaned), 5 cloves garlic (minced), 2 cm turmeric (grated), 1 tsp coriander powder, Salt, Oil for frying, For sambal: 10 red chilies, 5 bird\'s eye chilies, 3 cloves garlic, 2 tomatoes, 1 tsp shrimp paste, Salt and sugar',
@@ -335,7 +335,41 @@ INSERT INTO recipes (title, description, ingredients, instructions, cooking_time
 5. Grind into coarse paste
 6. Season with salt and sugar
 7. Serve catfish with sambal and fresh vegetables',
-40, 'beginner', 1, 4, 4);
+40, 'beginner', 1, 4);
+
+-- Assign variants (a recipe may have several)
+INSERT INTO recipe_variants (recipe_id, variant_id)
+SELECT r.id, v.variant_id
+FROM recipes r
+JOIN (
+    SELECT 'Nasi Goreng' AS title, 4 AS variant_id
+    UNION ALL SELECT 'Mie Goreng', 4
+    UNION ALL SELECT 'Soto Ayam', 4
+    -- Served with prawn crackers, so not Vegetarian
+    UNION ALL SELECT 'Gado-Gado', 4
+    UNION ALL SELECT 'Rendang Daging', 4
+    UNION ALL SELECT 'Ayam Bakar Taliwang', 4
+    UNION ALL SELECT 'Sate Ayam', 4
+    UNION ALL SELECT 'Nasi Uduk', 4
+    UNION ALL SELECT 'Bakso', 4
+    UNION ALL SELECT 'Rawon', 4
+    UNION ALL SELECT 'Gulai Kambing', 4
+    UNION ALL SELECT 'Pempek Palembang', 4
+    UNION ALL SELECT 'Tempe Goreng', 2
+    UNION ALL SELECT 'Tempe Goreng', 4
+    UNION ALL SELECT 'Tempe Goreng', 5
+    UNION ALL SELECT 'Capcay', 4
+    UNION ALL SELECT 'Perkedel Kentang', 4
+    UNION ALL SELECT 'Sop Buntut', 4
+    UNION ALL SELECT 'Ikan Bakar Bumbu Kuning', 4
+    UNION ALL SELECT 'Opor Ayam', 4
+    UNION ALL SELECT 'Sayur Asem', 4
+    UNION ALL SELECT 'Es Cendol', 3
+    UNION ALL SELECT 'Klepon', 3
+    UNION ALL SELECT 'Pisang Goreng', 3
+    UNION ALL SELECT 'Nasi Kuning', 4
+    UNION ALL SELECT 'Pecel Lele', 4
+) v ON v.title = r.title;
 
 -- Update auto-increment for next inserts
 ALTER TABLE recipes AUTO_INCREMENT = 26;
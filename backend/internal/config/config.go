@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +13,7 @@ type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	CORS     CORSConfig
+	Auth     AuthConfig
 }
 
 type DatabaseConfig struct {
@@ -31,6 +33,15 @@ type CORSConfig struct {
 	AllowedOrigins []string
 }
 
+// MinJWTSecretLength is the shortest JWT_SECRET the API server accepts
+const MinJWTSecretLength = 32
+
+type AuthConfig struct {
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
@@ -52,6 +63,15 @@ func Load() (*Config, error) {
 		},
 	}
 
+	var err error
+	cfg.Auth.JWTSecret = getEnv("JWT_SECRET", "")
+	if cfg.Auth.AccessTokenTTL, err = getDuration("ACCESS_TOKEN_TTL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.RefreshTokenTTL, err = getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -86,3 +106,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getDuration parses a duration such as "15m" or "720h"
+func getDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 15m, got %q", key, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+// userIDKey is the gin context key RequireAuth stores the signed-in
+// user's ID under
+const userIDKey = "user_id"
+
+type AuthHandler struct {
+	service service.AuthService
+}
+
+func NewAuthHandler(service service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// RefreshRequest holds the body of a refresh or logout request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Register handles POST /api/auth/register
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req service.Credentials
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tokens, err := h.service.Register(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "failed to register")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": tokens})
+}
+
+// Login handles POST /api/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req service.Credentials
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tokens, err := h.service.Login(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "failed to log in")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// Refresh handles POST /api/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.handleError(c, err, "failed to refresh token")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// Logout handles POST /api/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		h.handleError(c, err, "failed to log out")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
+}
+
+// RequireAuth is middleware that rejects requests without a valid
+// "Authorization: Bearer <access token>" header with 401
+func (h *AuthHandler) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+			return
+		}
+		if h.authenticate(c) {
+			c.Next()
+		}
+	}
+}
+
+// OptionalAuth is middleware that signs in requests carrying an access
+// token, as RequireAuth does, and lets requests without an Authorization
+// header through anonymously
+func (h *AuthHandler) OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" || h.authenticate(c) {
+			c.Next()
+		}
+	}
+}
+
+// authenticate checks the Authorization header and stores the signed-in
+// user's ID under userIDKey. It aborts with 401 and reports false when the
+// header is malformed or the token invalid.
+func (h *AuthHandler) authenticate(c *gin.Context) bool {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return false
+	}
+
+	userID, err := h.service.Authenticate(strings.TrimSpace(token))
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		return false
+	}
+
+	c.Set(userIDKey, userID)
+	return true
+}
+
+// clientID returns the ID that scopes spin history, the pantry and meal
+// plans: the signed-in user's, or the X-Client-ID header for anonymous
+// requests. A header naming a user is dropped, so only that user's access
+// token reaches their data.
+func clientID(c *gin.Context) string {
+	if userID, ok := c.Get(userIDKey); ok {
+		return service.UserClientID(userID.(int32))
+	}
+
+	header := c.GetHeader("X-Client-ID")
+	if service.IsUserClientID(header) {
+		return ""
+	}
+	return header
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *AuthHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
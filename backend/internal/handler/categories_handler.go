@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+type CategoriesHandler struct {
+	service service.CategoriesService
+}
+
+func NewCategoriesHandler(service service.CategoriesService) *CategoriesHandler {
+	return &CategoriesHandler{
+		service: service,
+	}
+}
+
+// ListCategories handles GET /api/categories
+func (h *CategoriesHandler) ListCategories(c *gin.Context) {
+	categories, err := h.service.ListCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// GetCategoryByID handles GET /api/categories/:id
+func (h *CategoriesHandler) GetCategoryByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category ID"})
+		return
+	}
+
+	category, err := h.service.GetCategoryByID(c.Request.Context(), int32(id))
+	if err != nil {
+		h.handleError(c, err, "failed to fetch category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// CreateCategory handles POST /api/categories
+func (h *CategoriesHandler) CreateCategory(c *gin.Context) {
+	var req service.CategoryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "failed to create category")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": category})
+}
+
+// UpdateCategory handles PUT /api/categories/:id
+func (h *CategoriesHandler) UpdateCategory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category ID"})
+		return
+	}
+
+	var req service.CategoryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(c.Request.Context(), int32(id), req)
+	if err != nil {
+		h.handleError(c, err, "failed to update category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": category})
+}
+
+// DeleteCategory handles DELETE /api/categories/:id
+func (h *CategoriesHandler) DeleteCategory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category ID"})
+		return
+	}
+
+	if err := h.service.DeleteCategory(c.Request.Context(), int32(id)); err != nil {
+		h.handleError(c, err, "failed to delete category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted successfully"})
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *CategoriesHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "category not found"})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+// MealPlansHandler serves the stored meal plans of the signed-in user, or
+// of the client named by the X-Client-ID header (see clientID)
+type MealPlansHandler struct {
+	service service.MealPlansService
+}
+
+func NewMealPlansHandler(service service.MealPlansService) *MealPlansHandler {
+	return &MealPlansHandler{
+		service: service,
+	}
+}
+
+// MealPlanSpinRequest represents the request body for spinning a plan slot
+type MealPlanSpinRequest struct {
+	SpinRequest
+	Date     string `json:"date"`
+	Meal     string `json:"meal"`
+	Servings *int32 `json:"servings,omitempty"`
+}
+
+// MealPlanServingsRequest represents the request body for overriding an
+// entry's servings; null goes back to the recipe's servings
+type MealPlanServingsRequest struct {
+	Servings *int32 `json:"servings"`
+}
+
+// ListMealPlans handles GET /api/plans
+func (h *MealPlansHandler) ListMealPlans(c *gin.Context) {
+	plans, err := h.service.ListMealPlans(c.Request.Context(), clientID(c))
+	if err != nil {
+		h.handleError(c, err, "failed to fetch meal plans")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plans})
+}
+
+// GetMealPlan handles GET /api/plans/:id
+func (h *MealPlansHandler) GetMealPlan(c *gin.Context) {
+	id, ok := h.planID(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.service.GetMealPlan(c.Request.Context(), clientID(c), id)
+	if err != nil {
+		h.handleError(c, err, "failed to fetch meal plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// CreateMealPlan handles POST /api/plans
+func (h *MealPlansHandler) CreateMealPlan(c *gin.Context) {
+	var req service.SaveMealPlanRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.CreateMealPlan(c.Request.Context(), clientID(c), req)
+	if err != nil {
+		h.handleError(c, err, "failed to create meal plan")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": plan})
+}
+
+// UpdateMealPlan handles PUT /api/plans/:id
+func (h *MealPlansHandler) UpdateMealPlan(c *gin.Context) {
+	id, ok := h.planID(c)
+	if !ok {
+		return
+	}
+
+	var req service.SaveMealPlanRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.UpdateMealPlan(c.Request.Context(), clientID(c), id, req)
+	if err != nil {
+		h.handleError(c, err, "failed to update meal plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// DeleteMealPlan handles DELETE /api/plans/:id
+func (h *MealPlansHandler) DeleteMealPlan(c *gin.Context) {
+	id, ok := h.planID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteMealPlan(c.Request.Context(), clientID(c), id); err != nil {
+		h.handleError(c, err, "failed to delete meal plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "meal plan deleted successfully"})
+}
+
+// AssignMealPlanEntry handles POST /api/plans/:id/entries
+func (h *MealPlansHandler) AssignMealPlanEntry(c *gin.Context) {
+	id, ok := h.planID(c)
+	if !ok {
+		return
+	}
+
+	var req service.MealPlanEntryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.AssignMealPlanEntry(c.Request.Context(), clientID(c), id, req)
+	if err != nil {
+		h.handleError(c, err, "failed to assign meal plan entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// SpinMealPlanEntry handles POST /api/plans/:id/spin
+func (h *MealPlansHandler) SpinMealPlanEntry(c *gin.Context) {
+	id, ok := h.planID(c)
+	if !ok {
+		return
+	}
+
+	var req MealPlanSpinRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	filters := req.recipeFilters()
+	filters.ClientID = clientID(c)
+	filters.ExcludeRecent = req.ExcludeRecent
+	filters.Weights = req.Weights
+	filters.Seed = req.Seed
+
+	plan, result, err := h.service.SpinMealPlanEntry(c.Request.Context(), clientID(c), id, service.MealPlanSpinRequest{
+		Date:     req.Date,
+		Meal:     req.Meal,
+		Servings: req.Servings,
+		Filters:  filters,
+	})
+	if err != nil {
+		h.handleError(c, err, "failed to spin meal plan entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": plan,
+		"spin": SpinResponse{
+			Recipe:       result.Recipe,
+			Probability:  result.Probability,
+			Candidates:   result.Candidates,
+			RecentRepeat: result.RecentRepeat,
+			Seed:         result.Seed,
+		},
+	})
+}
+
+// MoveMealPlanEntry handles POST /api/plans/:id/entries/:entryId/move
+func (h *MealPlansHandler) MoveMealPlanEntry(c *gin.Context) {
+	id, entryID, ok := h.entryID(c)
+	if !ok {
+		return
+	}
+
+	var req service.MealPlanMoveRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.MoveMealPlanEntry(c.Request.Context(), clientID(c), id, entryID, req)
+	if err != nil {
+		h.handleError(c, err, "failed to move meal plan entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// SetMealPlanEntryServings handles PUT /api/plans/:id/entries/:entryId/servings
+func (h *MealPlansHandler) SetMealPlanEntryServings(c *gin.Context) {
+	id, entryID, ok := h.entryID(c)
+	if !ok {
+		return
+	}
+
+	var req MealPlanServingsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.SetMealPlanEntryServings(c.Request.Context(), clientID(c), id, entryID, req.Servings)
+	if err != nil {
+		h.handleError(c, err, "failed to update meal plan entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// DeleteMealPlanEntry handles DELETE /api/plans/:id/entries/:entryId
+func (h *MealPlansHandler) DeleteMealPlanEntry(c *gin.Context) {
+	id, entryID, ok := h.entryID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteMealPlanEntry(c.Request.Context(), clientID(c), id, entryID); err != nil {
+		h.handleError(c, err, "failed to delete meal plan entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "meal plan entry deleted successfully"})
+}
+
+// GetMealPlanShoppingList handles GET /api/plans/:id/shopping-list
+// An optional ?units=metric|us converts quantities and ?format=markdown
+// returns the list as Markdown
+func (h *MealPlansHandler) GetMealPlanShoppingList(c *gin.Context) {
+	id, ok := h.planID(c)
+	if !ok {
+		return
+	}
+
+	format, ok := shoppingListFormat(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.GetMealPlanShoppingList(c.Request.Context(), clientID(c), id, c.Query("units"))
+	if err != nil {
+		h.handleError(c, err, "failed to build shopping list")
+		return
+	}
+
+	writeShoppingList(c, format, list)
+}
+
+// planID parses the :id parameter, responding with 400 when it is invalid
+func (h *MealPlansHandler) planID(c *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid meal plan ID"})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// entryID parses the :id and :entryId parameters, responding with 400 when
+// either is invalid
+func (h *MealPlansHandler) entryID(c *gin.Context) (int32, int32, bool) {
+	id, ok := h.planID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	entryID, err := strconv.ParseInt(c.Param("entryId"), 10, 32)
+	if err != nil || entryID < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid meal plan entry ID"})
+		return 0, 0, false
+	}
+	return id, int32(entryID), true
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *MealPlansHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrMealPlanNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "meal plan not found"})
+	case errors.Is(err, service.ErrMealPlanEntryNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "meal plan entry not found"})
+	case errors.Is(err, service.ErrRecipeNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
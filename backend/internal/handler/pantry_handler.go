@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+// PantryHandler serves the stored pantry of the signed-in user, or of the
+// client named by the X-Client-ID header (see clientID)
+type PantryHandler struct {
+	service service.PantryService
+}
+
+func NewPantryHandler(service service.PantryService) *PantryHandler {
+	return &PantryHandler{
+		service: service,
+	}
+}
+
+// ListPantryItems handles GET /api/pantry
+func (h *PantryHandler) ListPantryItems(c *gin.Context) {
+	items, err := h.service.ListPantryItems(c.Request.Context(), clientID(c))
+	if err != nil {
+		h.handleError(c, err, "failed to fetch pantry items")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// CreatePantryItem handles POST /api/pantry
+func (h *PantryHandler) CreatePantryItem(c *gin.Context) {
+	var req service.PantryItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	item, err := h.service.CreatePantryItem(c.Request.Context(), clientID(c), req)
+	if err != nil {
+		h.handleError(c, err, "failed to create pantry item")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// UpdatePantryItem handles PUT /api/pantry/:id
+func (h *PantryHandler) UpdatePantryItem(c *gin.Context) {
+	id, ok := h.itemID(c)
+	if !ok {
+		return
+	}
+
+	var req service.PantryItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	item, err := h.service.UpdatePantryItem(c.Request.Context(), clientID(c), id, req)
+	if err != nil {
+		h.handleError(c, err, "failed to update pantry item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// DeletePantryItem handles DELETE /api/pantry/:id
+func (h *PantryHandler) DeletePantryItem(c *gin.Context) {
+	id, ok := h.itemID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePantryItem(c.Request.Context(), clientID(c), id); err != nil {
+		h.handleError(c, err, "failed to delete pantry item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "pantry item deleted successfully"})
+}
+
+// itemID parses the :id parameter, responding with 400 when it is invalid
+func (h *PantryHandler) itemID(c *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid pantry item ID"})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *PantryHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrPantryItemNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "pantry item not found"})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sonyadriko/masakyuk/internal/service"
@@ -24,21 +26,159 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// SpinFilters are the recipe filters a spin draws from
+type SpinFilters struct {
+	Search             *string    `json:"search,omitempty"`
+	SkillLevel         stringList `json:"skill_level,omitempty"`
+	VariantID          int32List  `json:"variant_id,omitempty"`
+	CategoryID         int32List  `json:"category_id,omitempty"`
+	MinCookingTime     *int32     `json:"min_cooking_time,omitempty"`
+	MaxCookingTime     *int32     `json:"max_cooking_time,omitempty"`
+	MinCalories        *int32     `json:"min_calories,omitempty"`
+	MaxCalories        *int32     `json:"max_calories,omitempty"`
+	MinProtein         *float64   `json:"min_protein,omitempty"`
+	MaxCarbs           *float64   `json:"max_carbs,omitempty"`
+	MaxFat             *float64   `json:"max_fat,omitempty"`
+	HealthTagsAny      stringList `json:"health_tags_any,omitempty"`
+	HealthTagsAll      stringList `json:"health_tags_all,omitempty"`
+	TagsAny            stringList `json:"tags_any,omitempty"`
+	TagsAll            stringList `json:"tags_all,omitempty"`
+	ExcludeIngredients stringList `json:"exclude_ingredients,omitempty"`
+	ExcludeAllergens   stringList `json:"exclude_allergens,omitempty"`
+	Pantry             stringList `json:"pantry,omitempty"`
+	MaxMissing         int32      `json:"max_missing,omitempty"`
+}
+
+// recipeFilters converts spin filters to service filters
+func (f SpinFilters) recipeFilters() service.RecipeFilters {
+	return service.RecipeFilters{
+		Search:             f.Search,
+		SkillLevels:        f.SkillLevel,
+		VariantIDs:         f.VariantID,
+		CategoryIDs:        f.CategoryID,
+		MinCookingTime:     f.MinCookingTime,
+		MaxCookingTime:     f.MaxCookingTime,
+		MinCalories:        f.MinCalories,
+		MaxCalories:        f.MaxCalories,
+		MinProtein:         f.MinProtein,
+		MaxCarbs:           f.MaxCarbs,
+		MaxFat:             f.MaxFat,
+		HealthTagsAny:      f.HealthTagsAny,
+		HealthTagsAll:      f.HealthTagsAll,
+		TagsAny:            f.TagsAny,
+		TagsAll:            f.TagsAll,
+		ExcludeIngredients: f.ExcludeIngredients,
+		ExcludeAllergens:   f.ExcludeAllergens,
+		Pantry:             f.Pantry,
+		MaxMissing:         f.MaxMissing,
+	}
+}
+
 // SpinRequest represents the request body for spin endpoint
 type SpinRequest struct {
-	Search         *string `json:"search,omitempty"`
-	SkillLevel     *string `json:"skill_level,omitempty"`
-	VariantID      *int32  `json:"variant_id,omitempty"`
-	CategoryID     *int32  `json:"category_id,omitempty"`
-	MaxCookingTime *int32  `json:"max_cooking_time,omitempty"`
+	SpinFilters
+	// ExcludeRecent avoids recipes this client (see clientID) spun recently
+	ExcludeRecent *service.RecentSpins `json:"exclude_recent,omitempty"`
+	// Weights skews the draw towards some recipes
+	Weights *service.SpinWeights `json:"weights,omitempty"`
+	// Seed replays a previous spin
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// stringList accepts a JSON string (optionally comma-separated) or an
+// array of strings
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		var single string
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		values = strings.Split(single, ",")
+	}
+
+	*l = nil
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			*l = append(*l, trimmed)
+		}
+	}
+	return nil
+}
+
+// int32List accepts a JSON number or an array of numbers
+type int32List []int32
+
+func (l *int32List) UnmarshalJSON(data []byte) error {
+	var values []int32
+	if err := json.Unmarshal(data, &values); err != nil {
+		var single int32
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		values = []int32{single}
+	}
+	*l = values
+	return nil
 }
 
 // SpinResponse represents the response for spin endpoint
 type SpinResponse struct {
 	Recipe *service.Recipe `json:"recipe"`
+	// Probability is the chance of landing on recipe out of candidates
+	Probability float64 `json:"probability"`
+	Candidates  int     `json:"candidates"`
+	// RecentRepeat reports that only recently spun recipes matched
+	RecentRepeat bool `json:"recent_repeat,omitempty"`
+	// Seed replays this spin when sent with the same filters
+	Seed int64 `json:"seed"`
+}
+
+// MealPlanRequest represents the request body for the meal plan endpoint
+type MealPlanRequest struct {
+	Days                int      `json:"days,omitempty"`
+	Meals               []string `json:"meals,omitempty"`
+	MaxDailyCookingTime int32    `json:"max_daily_cooking_time,omitempty"`
+	// Filters apply to every slot; DayFilters narrow them on the given
+	// days, numbered from 1
+	Filters    SpinFilters          `json:"filters"`
+	DayFilters map[int]SpinFilters  `json:"day_filters,omitempty"`
+	Weights    *service.SpinWeights `json:"weights,omitempty"`
+	Seed       *int64               `json:"seed,omitempty"`
+}
+
+// mealPlanRequest converts the request to service constraints
+func (r MealPlanRequest) mealPlanRequest() service.MealPlanRequest {
+	req := service.MealPlanRequest{
+		Days:                r.Days,
+		Meals:               r.Meals,
+		MaxDailyCookingTime: r.MaxDailyCookingTime,
+		Filters:             r.Filters.recipeFilters(),
+		DayFilters:          make(map[int]service.RecipeFilters, len(r.DayFilters)),
+	}
+	req.Filters.Weights = r.Weights
+	req.Filters.Seed = r.Seed
+	for day, filters := range r.DayFilters {
+		req.DayFilters[day] = filters.recipeFilters()
+	}
+	return req
+}
+
+// MealPlanRerollRequest represents the request body for re-rolling one
+// slot of a plan. The constraints are those the plan was generated with;
+// days and meals come from the plan.
+type MealPlanRerollRequest struct {
+	MealPlanRequest
+	Plan service.MealPlan `json:"plan"`
+	Day  int              `json:"day"`
+	Meal string           `json:"meal"`
 }
 
 // ListRecipes handles GET /api/recipes
+// An optional ?pantry=true annotates each recipe with how much of it the
+// client's stored pantry covers
 func (h *RecipesHandler) ListRecipes(c *gin.Context) {
 	// Parse query parameters
 	filters := service.RecipeFilters{
@@ -51,44 +191,69 @@ func (h *RecipesHandler) ListRecipes(c *gin.Context) {
 		filters.Search = &search
 	}
 
-	// Parse skill_level
-	if skillLevel := c.Query("skill_level"); skillLevel != "" {
-		filters.SkillLevel = &skillLevel
-	}
+	// Parse skill_level, variant_id and category_id (comma-separated or repeated)
+	filters.SkillLevels = queryList(c, "skill_level")
 
-	// Parse variant_id
-	if variantIDStr := c.Query("variant_id"); variantIDStr != "" {
-		variantID, err := strconv.ParseInt(variantIDStr, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid variant_id"})
-			return
-		}
-		variantID32 := int32(variantID)
-		filters.VariantID = &variantID32
+	var err error
+	if filters.VariantIDs, err = queryInt32List(c, "variant_id"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filters.CategoryIDs, err = queryInt32List(c, "category_id"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	// Parse category_id
-	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
-		categoryID, err := strconv.ParseInt(categoryIDStr, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category_id"})
-			return
-		}
-		categoryID32 := int32(categoryID)
-		filters.CategoryID = &categoryID32
+	// Parse cooking time bounds
+	if filters.MinCookingTime, err = queryInt32(c, "min_cooking_time"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filters.MaxCookingTime, err = queryInt32(c, "max_cooking_time"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	// Parse max_cooking_time
-	if maxTimeStr := c.Query("max_cooking_time"); maxTimeStr != "" {
-		maxTime, err := strconv.ParseInt(maxTimeStr, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid max_cooking_time"})
-			return
-		}
-		maxTime32 := int32(maxTime)
-		filters.MaxCookingTime = &maxTime32
+	// Parse nutrition bounds
+	if filters.MinCalories, err = queryInt32(c, "min_calories"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filters.MaxCalories, err = queryInt32(c, "max_calories"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filters.MinProtein, err = queryFloat64(c, "min_protein"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filters.MaxCarbs, err = queryFloat64(c, "max_carbs"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filters.MaxFat, err = queryFloat64(c, "max_fat"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
 	}
 
+	// Parse health tags (comma-separated or repeated)
+	filters.HealthTagsAny = queryList(c, "health_tags_any")
+	filters.HealthTagsAll = queryList(c, "health_tags_all")
+	filters.TagsAny = queryList(c, "tags_any")
+	filters.TagsAll = queryList(c, "tags_all")
+
+	// Parse exclusions
+	filters.ExcludeIngredients = queryList(c, "exclude_ingredients")
+	filters.ExcludeAllergens = queryList(c, "exclude_allergens")
+
+	// Parse sort order (validated by the service)
+	filters.Sort = c.Query("sort")
+	filters.Order = c.Query("order")
+	filters.Cursor = c.Query("cursor")
+
+	// Parse requested facet counts
+	filters.Facets = queryList(c, "facets")
+
 	// Parse page
 	if pageStr := c.Query("page"); pageStr != "" {
 		page, err := strconv.Atoi(pageStr)
@@ -109,6 +274,13 @@ func (h *RecipesHandler) ListRecipes(c *gin.Context) {
 		filters.PerPage = perPage
 	}
 
+	// Parse pantry annotation
+	pantry, err := queryBool(c, "pantry")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// Call service
 	result, err := h.service.ListRecipes(c.Request.Context(), filters)
 	if err != nil {
@@ -120,9 +292,106 @@ func (h *RecipesHandler) ListRecipes(c *gin.Context) {
 		return
 	}
 
+	if pantry {
+		recipes := make([]*service.Recipe, len(result.Data))
+		for i := range result.Data {
+			recipes[i] = &result.Data[i]
+		}
+		if !h.annotatePantry(c, recipes...) {
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, result)
 }
 
+// annotatePantry annotates recipes with the client's stored pantry,
+// responding with an error and returning false when that fails
+func (h *RecipesHandler) annotatePantry(c *gin.Context, recipes ...*service.Recipe) bool {
+	err := h.service.AnnotatePantry(c.Request.Context(), clientID(c), recipes...)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParams) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to load pantry"})
+		return false
+	}
+	return true
+}
+
+// queryInt32List parses a list of integers given comma-separated or as a
+// repeated query parameter
+func queryInt32List(c *gin.Context, key string) ([]int32, error) {
+	raw := queryList(c, key)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	values := make([]int32, len(raw))
+	for i, item := range raw {
+		value, err := strconv.ParseInt(item, 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid " + key)
+		}
+		values[i] = int32(value)
+	}
+	return values, nil
+}
+
+// queryBool parses an optional boolean query parameter; absent is false
+func queryBool(c *gin.Context, key string) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.New("invalid " + key)
+	}
+	return value, nil
+}
+
+// queryInt32 parses an optional integer query parameter
+func queryInt32(c *gin.Context, key string) (*int32, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid " + key)
+	}
+	value32 := int32(value)
+	return &value32, nil
+}
+
+// queryFloat64 parses an optional decimal query parameter
+func queryFloat64(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, errors.New("invalid " + key)
+	}
+	return &value, nil
+}
+
+// queryList collects a list parameter given either as repeated keys
+// (?tag=a&tag=b) or as a comma-separated value (?tag=a,b)
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, part := range strings.Split(raw, ",") {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				values = append(values, trimmed)
+			}
+		}
+	}
+	return values
+}
+
 // Spin handles POST /api/spin
 func (h *RecipesHandler) Spin(c *gin.Context) {
 	var req SpinRequest
@@ -134,16 +403,14 @@ func (h *RecipesHandler) Spin(c *gin.Context) {
 	}
 
 	// Build filters
-	filters := service.RecipeFilters{
-		Search:         req.Search,
-		SkillLevel:     req.SkillLevel,
-		VariantID:      req.VariantID,
-		CategoryID:     req.CategoryID,
-		MaxCookingTime: req.MaxCookingTime,
-	}
+	filters := req.recipeFilters()
+	filters.ClientID = clientID(c)
+	filters.ExcludeRecent = req.ExcludeRecent
+	filters.Weights = req.Weights
+	filters.Seed = req.Seed
 
 	// Get random recipe
-	recipe, err := h.service.GetRandomRecipe(c.Request.Context(), filters)
+	result, err := h.service.GetRandomRecipe(c.Request.Context(), filters)
 	if err != nil {
 		if errors.Is(err, service.ErrRecipeNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "no recipes match the criteria"})
@@ -157,10 +424,157 @@ func (h *RecipesHandler) Spin(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, SpinResponse{Recipe: recipe})
+	c.JSON(http.StatusOK, SpinResponse{
+		Recipe:       result.Recipe,
+		Probability:  result.Probability,
+		Candidates:   result.Candidates,
+		RecentRepeat: result.RecentRepeat,
+		Seed:         result.Seed,
+	})
+}
+
+// GenerateMealPlan handles POST /api/spin/plan
+func (h *RecipesHandler) GenerateMealPlan(c *gin.Context) {
+	var req MealPlanRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.GenerateMealPlan(c.Request.Context(), req.mealPlanRequest())
+	if err != nil {
+		h.handleMealPlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// RerollMealPlanSlot handles POST /api/spin/plan/reroll
+func (h *RecipesHandler) RerollMealPlanSlot(c *gin.Context) {
+	var req MealPlanRerollRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	plan, err := h.service.RerollMealPlanSlot(c.Request.Context(), service.MealPlanRerollRequest{
+		MealPlanRequest: req.mealPlanRequest(),
+		Plan:            req.Plan,
+		Day:             req.Day,
+		Meal:            req.Meal,
+	})
+	if err != nil {
+		h.handleMealPlanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// handleMealPlanError maps meal plan errors; a plan that cannot be filled
+// reports which slot failed
+func (h *RecipesHandler) handleMealPlanError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecipeNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to plan meals"})
+	}
+}
+
+// SearchByPantry handles POST /api/recipes/pantry
+func (h *RecipesHandler) SearchByPantry(c *gin.Context) {
+	var req service.PantrySearchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	result, err := h.service.SearchByPantry(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidParams) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to search recipes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BuildShoppingList handles POST /api/shopping-list
+// An optional ?format=markdown returns the list as Markdown
+func (h *RecipesHandler) BuildShoppingList(c *gin.Context) {
+	format, ok := shoppingListFormat(c)
+	if !ok {
+		return
+	}
+
+	var req service.ShoppingListRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	list, err := h.service.BuildShoppingList(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrRecipeNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidParams) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to build shopping list"})
+		return
+	}
+
+	writeShoppingList(c, format, list)
+}
+
+// shoppingListFormat reads ?format=json|markdown, responding with 400 when
+// it is anything else
+func shoppingListFormat(c *gin.Context) (string, bool) {
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "json", "markdown":
+		return format, true
+	}
+	c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be json or markdown"})
+	return "", false
+}
+
+// writeShoppingList responds with the list in the requested format
+func writeShoppingList(c *gin.Context, format string, list *service.ShoppingList) {
+	if format == "markdown" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(service.FormatShoppingList(list)))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list})
+}
+
+// AuditDietary handles GET /api/recipes/dietary-audit
+func (h *RecipesHandler) AuditDietary(c *gin.Context) {
+	result, err := h.service.AuditDietary(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to audit recipes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
 
 // GetRecipeByID handles GET /api/recipes/:id
+// An optional ?servings=N rescales ingredient quantities and ?units=metric|us
+// converts them. ?pantry=true annotates the recipe as on the list.
 func (h *RecipesHandler) GetRecipeByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 32)
@@ -169,16 +583,53 @@ func (h *RecipesHandler) GetRecipeByID(c *gin.Context) {
 		return
 	}
 
-	recipe, err := h.service.GetRecipeByID(c.Request.Context(), int32(id))
+	servings, err := queryInt32(c, "servings")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var system service.UnitSystem
+	if unitsStr := c.Query("units"); unitsStr != "" {
+		if system, err = service.ParseUnitSystem(unitsStr); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	pantry, err := queryBool(c, "pantry")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var recipe *service.Recipe
+	if servings != nil {
+		recipe, err = h.service.ScaleRecipe(c.Request.Context(), int32(id), *servings)
+	} else {
+		recipe, err = h.service.GetRecipeByID(c.Request.Context(), int32(id))
+	}
 	if err != nil {
 		if errors.Is(err, service.ErrRecipeNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "recipe not found"})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidParams) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch recipe"})
 		return
 	}
 
+	if system != "" {
+		service.ConvertRecipe(recipe, system)
+	}
+
+	if pantry && !h.annotatePantry(c, recipe) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": recipe})
 }
 
@@ -246,7 +697,7 @@ func (h *RecipesHandler) DeleteRecipe(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteRecipe(c.Request.Context(), int32(id))
+	removed, err := h.service.DeleteRecipe(c.Request.Context(), int32(id))
 	if err != nil {
 		if errors.Is(err, service.ErrRecipeNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "recipe not found"})
@@ -256,5 +707,8 @@ func (h *RecipesHandler) DeleteRecipe(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "recipe deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "recipe deleted successfully",
+		"removed_plan_entries": removed,
+	})
 }
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+type TagsHandler struct {
+	service service.TagsService
+}
+
+func NewTagsHandler(service service.TagsService) *TagsHandler {
+	return &TagsHandler{
+		service: service,
+	}
+}
+
+// ListTags handles GET /api/tags
+func (h *TagsHandler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// AutocompleteTags handles GET /api/tags/autocomplete?q=&limit=
+func (h *TagsHandler) AutocompleteTags(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid limit"})
+		return
+	}
+
+	tags, err := h.service.AutocompleteTags(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		h.handleError(c, err, "failed to autocomplete tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// GetTagByID handles GET /api/tags/:id
+func (h *TagsHandler) GetTagByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tag ID"})
+		return
+	}
+
+	tag, err := h.service.GetTagByID(c.Request.Context(), int32(id))
+	if err != nil {
+		h.handleError(c, err, "failed to fetch tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// CreateTag handles POST /api/tags
+func (h *TagsHandler) CreateTag(c *gin.Context) {
+	var req service.TagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tag, err := h.service.CreateTag(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": tag})
+}
+
+// UpdateTag handles PUT /api/tags/:id
+func (h *TagsHandler) UpdateTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tag ID"})
+		return
+	}
+
+	var req service.TagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tag, err := h.service.UpdateTag(c.Request.Context(), int32(id), req)
+	if err != nil {
+		h.handleError(c, err, "failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// DeleteTag handles DELETE /api/tags/:id
+func (h *TagsHandler) DeleteTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid tag ID"})
+		return
+	}
+
+	if err := h.service.DeleteTag(c.Request.Context(), int32(id)); err != nil {
+		h.handleError(c, err, "failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted successfully"})
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *TagsHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "tag not found"})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
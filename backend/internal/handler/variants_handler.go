@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonyadriko/masakyuk/internal/service"
+)
+
+type VariantsHandler struct {
+	service service.VariantsService
+}
+
+func NewVariantsHandler(service service.VariantsService) *VariantsHandler {
+	return &VariantsHandler{
+		service: service,
+	}
+}
+
+// ListVariants handles GET /api/variants
+func (h *VariantsHandler) ListVariants(c *gin.Context) {
+	variants, err := h.service.ListVariants(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch variants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": variants})
+}
+
+// GetVariantByID handles GET /api/variants/:id
+func (h *VariantsHandler) GetVariantByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid variant ID"})
+		return
+	}
+
+	variant, err := h.service.GetVariantByID(c.Request.Context(), int32(id))
+	if err != nil {
+		h.handleError(c, err, "failed to fetch variant")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": variant})
+}
+
+// CreateVariant handles POST /api/variants
+func (h *VariantsHandler) CreateVariant(c *gin.Context) {
+	var req service.VariantRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	variant, err := h.service.CreateVariant(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "failed to create variant")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": variant})
+}
+
+// UpdateVariant handles PUT /api/variants/:id
+func (h *VariantsHandler) UpdateVariant(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid variant ID"})
+		return
+	}
+
+	var req service.VariantRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(c.Request.Context(), int32(id), req)
+	if err != nil {
+		h.handleError(c, err, "failed to update variant")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": variant})
+}
+
+// DeleteVariant handles DELETE /api/variants/:id
+func (h *VariantsHandler) DeleteVariant(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid variant ID"})
+		return
+	}
+
+	if err := h.service.DeleteVariant(c.Request.Context(), int32(id)); err != nil {
+		h.handleError(c, err, "failed to delete variant")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "variant deleted successfully"})
+}
+
+// handleError maps service errors to HTTP status codes
+func (h *VariantsHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "variant not found"})
+	case errors.Is(err, service.ErrInvalidParams):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// CategoriesRepository defines the interface for category data operations
+type CategoriesRepository interface {
+	ListCategories(ctx context.Context) ([]db.ListCategoriesRow, error)
+	GetCategoryByID(ctx context.Context, id int32) (db.GetCategoryByIDRow, error)
+	CreateCategory(ctx context.Context, params CategoryParams) (int64, error)
+	UpdateCategory(ctx context.Context, id int32, params CategoryParams) error
+	DeleteCategory(ctx context.Context, id int32) error
+}
+
+// CategoryParams holds parameters for creating or updating a category
+type CategoryParams struct {
+	Name        string
+	Description *string
+}
+
+// categoriesRepository implements CategoriesRepository
+type categoriesRepository struct {
+	queries *db.Queries
+}
+
+// NewCategoriesRepository creates a new categories repository
+func NewCategoriesRepository(queries *db.Queries) CategoriesRepository {
+	return &categoriesRepository{
+		queries: queries,
+	}
+}
+
+func (r *categoriesRepository) ListCategories(ctx context.Context) ([]db.ListCategoriesRow, error) {
+	return r.queries.ListCategories(ctx)
+}
+
+func (r *categoriesRepository) GetCategoryByID(ctx context.Context, id int32) (db.GetCategoryByIDRow, error) {
+	return r.queries.GetCategoryByID(ctx, id)
+}
+
+func (r *categoriesRepository) CreateCategory(ctx context.Context, params CategoryParams) (int64, error) {
+	result, err := r.queries.CreateCategory(ctx, db.CreateCategoryParams{
+		Name:        params.Name,
+		Description: stringToNull(params.Description),
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *categoriesRepository) UpdateCategory(ctx context.Context, id int32, params CategoryParams) error {
+	err := r.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
+		Name:        params.Name,
+		Description: stringToNull(params.Description),
+		ID:          id,
+	})
+	return translateError(err)
+}
+
+func (r *categoriesRepository) DeleteCategory(ctx context.Context, id int32) error {
+	return translateError(r.queries.DeleteCategory(ctx, id))
+}
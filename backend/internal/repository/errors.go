@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrDuplicate is returned when a unique constraint is violated
+	ErrDuplicate = errors.New("duplicate entry")
+	// ErrReferenced is returned when a row cannot be deleted because
+	// other rows still reference it through a foreign key
+	ErrReferenced = errors.New("row is still referenced")
+	// ErrUnknownReference is returned when a row points at a parent row,
+	// such as a category or variant, that does not exist
+	ErrUnknownReference = errors.New("referenced row does not exist")
+)
+
+// MySQL error numbers we translate into repository errors
+const (
+	mysqlErrDuplicateEntry  = 1062
+	mysqlErrRowIsReferenced = 1451
+	mysqlErrNoReferencedRow = 1452
+)
+
+// translateError maps driver-specific constraint errors to repository errors
+func translateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case mysqlErrDuplicateEntry:
+		return ErrDuplicate
+	case mysqlErrRowIsReferenced:
+		return ErrReferenced
+	case mysqlErrNoReferencedRow:
+		return ErrUnknownReference
+	default:
+		return err
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// MealPlansRepository defines the interface for meal plan data operations.
+// Every change to a plan's entries also marks the plan as updated.
+type MealPlansRepository interface {
+	ListMealPlans(ctx context.Context, clientID string) ([]db.ListMealPlansRow, error)
+	GetMealPlanByID(ctx context.Context, id int32) (db.MealPlan, error)
+	CreateMealPlan(ctx context.Context, params db.CreateMealPlanParams, entries []db.UpsertMealPlanEntryParams) (int64, error)
+	UpdateMealPlan(ctx context.Context, params db.UpdateMealPlanParams) error
+	DeleteMealPlan(ctx context.Context, id int32) error
+	CountMealPlanEntriesOutside(ctx context.Context, id int32, start, end time.Time) (int64, error)
+	ListMealPlanEntries(ctx context.Context, id int32) ([]db.ListMealPlanEntriesRow, error)
+	GetMealPlanEntryByID(ctx context.Context, id int32) (db.MealPlanEntry, error)
+	AssignMealPlanEntry(ctx context.Context, params db.UpsertMealPlanEntryParams) error
+	MoveMealPlanEntry(ctx context.Context, entry db.MealPlanEntry, date time.Time, meal string) error
+	UpdateMealPlanEntryServings(ctx context.Context, entry db.MealPlanEntry, servings sql.NullInt32) error
+	DeleteMealPlanEntry(ctx context.Context, entry db.MealPlanEntry) error
+}
+
+// mealPlansRepository implements MealPlansRepository
+type mealPlansRepository struct {
+	conn    *sql.DB
+	queries *db.Queries
+}
+
+// NewMealPlansRepository creates a new meal plans repository
+func NewMealPlansRepository(conn *sql.DB, queries *db.Queries) MealPlansRepository {
+	return &mealPlansRepository{
+		conn:    conn,
+		queries: queries,
+	}
+}
+
+// ListMealPlans returns the plans a client owns
+func (r *mealPlansRepository) ListMealPlans(ctx context.Context, clientID string) ([]db.ListMealPlansRow, error) {
+	return r.queries.ListMealPlans(ctx, clientID)
+}
+
+func (r *mealPlansRepository) GetMealPlanByID(ctx context.Context, id int32) (db.MealPlan, error) {
+	return r.queries.GetMealPlanByID(ctx, id)
+}
+
+// CreateMealPlan inserts a plan with its initial entries; MealPlanID of
+// the entries is filled in
+func (r *mealPlansRepository) CreateMealPlan(ctx context.Context, params db.CreateMealPlanParams, entries []db.UpsertMealPlanEntryParams) (int64, error) {
+	var id int64
+	err := inTx(ctx, r.conn, r.queries, func(q *db.Queries) error {
+		result, err := q.CreateMealPlan(ctx, params)
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			entry.MealPlanID = int32(id)
+			if err := q.UpsertMealPlanEntry(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return id, translateError(err)
+}
+
+func (r *mealPlansRepository) UpdateMealPlan(ctx context.Context, params db.UpdateMealPlanParams) error {
+	return r.queries.UpdateMealPlan(ctx, params)
+}
+
+func (r *mealPlansRepository) DeleteMealPlan(ctx context.Context, id int32) error {
+	return r.queries.DeleteMealPlan(ctx, id)
+}
+
+// CountMealPlanEntriesOutside counts a plan's entries dated before start or
+// after end
+func (r *mealPlansRepository) CountMealPlanEntriesOutside(ctx context.Context, id int32, start, end time.Time) (int64, error) {
+	return r.queries.CountMealPlanEntriesOutside(ctx, db.CountMealPlanEntriesOutsideParams{
+		MealPlanID: id,
+		StartDate:  start,
+		EndDate:    end,
+	})
+}
+
+func (r *mealPlansRepository) ListMealPlanEntries(ctx context.Context, id int32) ([]db.ListMealPlanEntriesRow, error) {
+	return r.queries.ListMealPlanEntries(ctx, id)
+}
+
+func (r *mealPlansRepository) GetMealPlanEntryByID(ctx context.Context, id int32) (db.MealPlanEntry, error) {
+	return r.queries.GetMealPlanEntryByID(ctx, id)
+}
+
+// AssignMealPlanEntry puts a recipe in a slot, replacing any recipe there
+func (r *mealPlansRepository) AssignMealPlanEntry(ctx context.Context, params db.UpsertMealPlanEntryParams) error {
+	err := inTx(ctx, r.conn, r.queries, func(q *db.Queries) error {
+		if err := q.UpsertMealPlanEntry(ctx, params); err != nil {
+			return err
+		}
+		return q.TouchMealPlan(ctx, params.MealPlanID)
+	})
+	return translateError(err)
+}
+
+// MoveMealPlanEntry moves an entry to another slot of its plan. An entry
+// already in that slot swaps places with it.
+func (r *mealPlansRepository) MoveMealPlanEntry(ctx context.Context, entry db.MealPlanEntry, date time.Time, meal string) error {
+	return inTx(ctx, r.conn, r.queries, func(q *db.Queries) error {
+		occupant, err := q.GetMealPlanEntryBySlot(ctx, db.GetMealPlanEntryBySlotParams{
+			MealPlanID: entry.MealPlanID,
+			PlanDate:   date,
+			Meal:       meal,
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return err
+		case occupant.ID == entry.ID:
+			return nil
+		default:
+			// Park the entry in a slot no real meal uses so the occupant
+			// can take its place without breaking the unique slot key
+			if err := q.MoveMealPlanEntry(ctx, db.MoveMealPlanEntryParams{PlanDate: entry.PlanDate, Meal: "", ID: entry.ID}); err != nil {
+				return err
+			}
+			if err := q.MoveMealPlanEntry(ctx, db.MoveMealPlanEntryParams{PlanDate: entry.PlanDate, Meal: entry.Meal, ID: occupant.ID}); err != nil {
+				return err
+			}
+		}
+
+		if err := q.MoveMealPlanEntry(ctx, db.MoveMealPlanEntryParams{PlanDate: date, Meal: meal, ID: entry.ID}); err != nil {
+			return err
+		}
+		return q.TouchMealPlan(ctx, entry.MealPlanID)
+	})
+}
+
+// UpdateMealPlanEntryServings overrides an entry's servings; NULL goes back
+// to the recipe's own
+func (r *mealPlansRepository) UpdateMealPlanEntryServings(ctx context.Context, entry db.MealPlanEntry, servings sql.NullInt32) error {
+	return inTx(ctx, r.conn, r.queries, func(q *db.Queries) error {
+		if err := q.UpdateMealPlanEntryServings(ctx, db.UpdateMealPlanEntryServingsParams{Servings: servings, ID: entry.ID}); err != nil {
+			return err
+		}
+		return q.TouchMealPlan(ctx, entry.MealPlanID)
+	})
+}
+
+func (r *mealPlansRepository) DeleteMealPlanEntry(ctx context.Context, entry db.MealPlanEntry) error {
+	return inTx(ctx, r.conn, r.queries, func(q *db.Queries) error {
+		if err := q.DeleteMealPlanEntry(ctx, entry.ID); err != nil {
+			return err
+		}
+		return q.TouchMealPlan(ctx, entry.MealPlanID)
+	})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// PantryRepository defines the interface for pantry inventory data
+// operations. Items belong to the client that added them.
+type PantryRepository interface {
+	ListPantryItems(ctx context.Context, clientID string) ([]db.PantryItem, error)
+	GetPantryItemByID(ctx context.Context, id int32) (db.PantryItem, error)
+	CreatePantryItem(ctx context.Context, params PantryItemParams) (int64, error)
+	UpdatePantryItem(ctx context.Context, id int32, params PantryItemParams) error
+	DeletePantryItem(ctx context.Context, id int32) error
+	ListStockedPantryItems(ctx context.Context, clientID string) ([]string, error)
+	ListExpiringPantryItems(ctx context.Context, clientID string, days int32) ([]string, error)
+}
+
+// PantryItemParams holds the columns of a pantry item. ClientID is only
+// used on create.
+type PantryItemParams struct {
+	ClientID  string
+	Name      string
+	Quantity  *float64
+	Unit      *string
+	ExpiresOn *time.Time
+}
+
+// pantryRepository implements PantryRepository
+type pantryRepository struct {
+	queries *db.Queries
+}
+
+// NewPantryRepository creates a new pantry repository
+func NewPantryRepository(queries *db.Queries) PantryRepository {
+	return &pantryRepository{
+		queries: queries,
+	}
+}
+
+// ListPantryItems returns a client's items, soonest to expire first
+func (r *pantryRepository) ListPantryItems(ctx context.Context, clientID string) ([]db.PantryItem, error) {
+	return r.queries.ListPantryItems(ctx, clientID)
+}
+
+func (r *pantryRepository) GetPantryItemByID(ctx context.Context, id int32) (db.PantryItem, error) {
+	return r.queries.GetPantryItemByID(ctx, id)
+}
+
+func (r *pantryRepository) CreatePantryItem(ctx context.Context, params PantryItemParams) (int64, error) {
+	result, err := r.queries.CreatePantryItem(ctx, db.CreatePantryItemParams{
+		ClientID:  params.ClientID,
+		Name:      params.Name,
+		Quantity:  float64ToNullQuantity(params.Quantity),
+		Unit:      stringToNull(params.Unit),
+		ExpiresOn: timeToNull(params.ExpiresOn),
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *pantryRepository) UpdatePantryItem(ctx context.Context, id int32, params PantryItemParams) error {
+	err := r.queries.UpdatePantryItem(ctx, db.UpdatePantryItemParams{
+		Name:      params.Name,
+		Quantity:  float64ToNullQuantity(params.Quantity),
+		Unit:      stringToNull(params.Unit),
+		ExpiresOn: timeToNull(params.ExpiresOn),
+		ID:        id,
+	})
+	return translateError(err)
+}
+
+func (r *pantryRepository) DeletePantryItem(ctx context.Context, id int32) error {
+	return r.queries.DeletePantryItem(ctx, id)
+}
+
+// ListStockedPantryItems returns the names of a client's items that can
+// still be cooked with: not used up (a quantity of 0) and not expired
+func (r *pantryRepository) ListStockedPantryItems(ctx context.Context, clientID string) ([]string, error) {
+	return r.queries.ListStockedPantryItems(ctx, clientID)
+}
+
+// ListExpiringPantryItems returns the names of a client's stocked items
+// expiring from today to days from now, soonest first
+func (r *pantryRepository) ListExpiringPantryItems(ctx context.Context, clientID string, days int32) ([]string, error) {
+	return r.queries.ListExpiringPantryItems(ctx, db.ListExpiringPantryItemsParams{
+		ClientID: clientID,
+		Days:     days,
+	})
+}
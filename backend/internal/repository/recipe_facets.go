@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Facet is a dimension recipes can be counted by
+type Facet string
+
+const (
+	FacetCategory    Facet = "category"
+	FacetVariant     Facet = "variant"
+	FacetSkillLevel  Facet = "skill_level"
+	FacetCookingTime Facet = "cooking_time"
+)
+
+// FacetCount is the number of matching recipes with one facet value
+type FacetCount struct {
+	Value string
+	Label string
+	Count int64
+}
+
+// facetColumns holds the value, label and ordering expressions of a facet,
+// plus any joins they need
+type facetColumns struct {
+	value, label, order, joins string
+}
+
+// cookingTimeBucket groups cooking times into the ranges the filter
+// sidebar offers; upper bounds are inclusive like max_cooking_time
+const cookingTimeBucket = `CASE
+        WHEN r.cooking_time <= 15 THEN '0-15'
+        WHEN r.cooking_time <= 30 THEN '16-30'
+        WHEN r.cooking_time <= 60 THEN '31-60'
+        ELSE '61+'
+    END`
+
+var facets = map[Facet]facetColumns{
+	FacetCategory: {value: "CAST(r.category_id AS CHAR)", label: "c.name", order: "label"},
+	FacetVariant: {
+		value: "CAST(rv.variant_id AS CHAR)", label: "v.name", order: "label",
+		joins: "\nJOIN recipe_variants rv ON rv.recipe_id = r.id\nJOIN variants v ON rv.variant_id = v.id",
+	},
+	FacetSkillLevel:  {value: "r.skill_level", label: "r.skill_level", order: "MIN(FIELD(r.skill_level, 'beginner', 'intermediate', 'advanced'))"},
+	FacetCookingTime: {value: cookingTimeBucket, label: cookingTimeBucket, order: "MIN(r.cooking_time)"},
+}
+
+// CountRecipeFacet counts the recipes matching filter per value of a
+// facet. Values without recipes are omitted. A recipe with several
+// variants counts once for each.
+func (r *recipesRepository) CountRecipeFacet(ctx context.Context, facet Facet, filter RecipeFilter) ([]FacetCount, error) {
+	cols, ok := facets[facet]
+	if !ok {
+		return nil, fmt.Errorf("unknown facet %q", facet)
+	}
+
+	where, args := recipeFilterSQL(filter)
+	query := "SELECT " + cols.value + " AS value, " + cols.label + " AS label, COUNT(*)" +
+		recipeJoins + cols.joins + where + "\nGROUP BY value, label\nORDER BY " + cols.order
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []FacetCount{}
+	for rows.Next() {
+		var count FacetCount
+		if err := rows.Scan(&count.Value, &count.Label, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RecipeFilter holds the filters shared by ListRecipes, CountRecipes and
+// ListSpinCandidates. All three build their WHERE clause from it with
+// recipeFilterSQL, so a list total always matches its rows.
+type RecipeFilter struct {
+	Search *string
+	// SkillLevels and CategoryIDs each match any of their values;
+	// VariantIDs matches recipes having any of these variants
+	SkillLevels    []string
+	VariantIDs     []int32
+	CategoryIDs    []int32
+	MinCookingTime *int32
+	MaxCookingTime *int32
+	MinCalories    *int32
+	MaxCalories    *int32
+	MinProtein     *float64
+	MaxCarbs       *float64
+	MaxFat         *float64
+	HealthTagsAny  []string
+	HealthTagsAll  []string
+	// TagsAny matches recipes with any of these tag names, TagsAll those
+	// with every one
+	TagsAny []string
+	TagsAll []string
+	// ExcludeIngredients drops recipes with an ingredient name containing
+	// any of these words
+	ExcludeIngredients []string
+	ExcludeAllergens   []string
+	// RecipeIDs restricts results to these recipes when non-empty
+	RecipeIDs []int32
+	// ExcludeRecipeIDs drops these recipes
+	ExcludeRecipeIDs []int32
+	// Pantry keeps recipes with structured ingredients that miss at most
+	// MaxMissing required ones from these food names, when non-empty
+	Pantry     []string
+	MaxMissing int32
+}
+
+// SortField is a column a recipe list can be ordered by
+type SortField string
+
+const (
+	SortCreatedAt   SortField = "created_at"
+	SortCookingTime SortField = "cooking_time"
+	SortTitle       SortField = "title"
+	SortCalories    SortField = "calories"
+	SortRelevance   SortField = "relevance"
+)
+
+// RecipeSort orders a recipe list. Ties are broken by id in the same
+// direction, so the order is total.
+type RecipeSort struct {
+	Field SortField
+	Desc  bool
+}
+
+// RecipeCursor is the keyset position of the last row on a page: its sort
+// value and id. The next page starts strictly after it.
+type RecipeCursor struct {
+	Value interface{}
+	ID    int32
+}
+
+// RecipeRow is a recipe joined with its category name, as returned by the
+// filtered recipe queries
+type RecipeRow struct {
+	ID           int32
+	Title        string
+	Description  string
+	Ingredients  string
+	Instructions string
+	CookingTime  int32
+	SkillLevel   string
+	Servings     int32
+	ImageURL     sql.NullString
+	Calories     sql.NullInt32
+	Protein      sql.NullString
+	Carbs        sql.NullString
+	Fat          sql.NullString
+	CategoryID   int32
+	CategoryName string
+	CreatedAt    time.Time
+	// Relevance is the full-text score in millionths, or 0 when not
+	// searching. It is rounded so keyset conditions can compare it exactly.
+	Relevance int64
+}
+
+// recipeSelect lists the RecipeRow columns in scan order, except relevance
+const recipeSelect = `
+SELECT
+    r.id, r.title, r.description, r.ingredients, r.instructions,
+    r.cooking_time, r.skill_level, r.servings, r.image_url,
+    r.calories, r.protein, r.carbs, r.fat,
+    r.category_id, c.name AS category_name,
+    r.created_at`
+
+const recipeJoins = `
+FROM recipes r
+JOIN categories c ON r.category_id = c.id`
+
+// searchMatch must list the columns of idx_recipes_search exactly
+const searchMatch = "MATCH(r.title, r.description, r.ingredients) AGAINST (? IN BOOLEAN MODE)"
+
+// minSearchTermLength mirrors InnoDB's default innodb_ft_min_token_size;
+// shorter words are never indexed
+const minSearchTermLength = 3
+
+func scanRecipeRow(scanner interface{ Scan(...interface{}) error }) (RecipeRow, error) {
+	var row RecipeRow
+	err := scanner.Scan(
+		&row.ID, &row.Title, &row.Description, &row.Ingredients, &row.Instructions,
+		&row.CookingTime, &row.SkillLevel, &row.Servings, &row.ImageURL,
+		&row.Calories, &row.Protein, &row.Carbs, &row.Fat,
+		&row.CategoryID, &row.CategoryName,
+		&row.CreatedAt, &row.Relevance,
+	)
+	return row, err
+}
+
+// whereBuilder collects AND-ed conditions with their arguments
+type whereBuilder struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (w *whereBuilder) add(clause string, args ...interface{}) {
+	w.clauses = append(w.clauses, clause)
+	w.args = append(w.args, args...)
+}
+
+func (w *whereBuilder) sql() string {
+	if len(w.clauses) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(w.clauses, "\n    AND ")
+}
+
+// recipeFilterSQL builds the WHERE clause for a filter
+func recipeFilterSQL(f RecipeFilter) (string, []interface{}) {
+	w := recipeFilterWhere(f)
+	return w.sql(), w.args
+}
+
+// recipeFilterWhere collects the conditions for a filter
+func recipeFilterWhere(f RecipeFilter) *whereBuilder {
+	w := &whereBuilder{}
+
+	if f.Search != nil {
+		if query, ok := fulltextQuery(*f.Search); ok {
+			w.add(searchMatch, query)
+		} else if term := strings.TrimSpace(*f.Search); term != "" {
+			// Only short words: fall back to a substring match
+			w.add("CONCAT_WS(' ', r.title, r.description, r.ingredients) LIKE CONCAT('%', ?, '%')", term)
+		}
+	}
+	if len(f.SkillLevels) > 0 {
+		w.add("r.skill_level IN ("+placeholders(len(f.SkillLevels))+")", stringArgs(f.SkillLevels)...)
+	}
+	if len(f.VariantIDs) > 0 {
+		w.add(`EXISTS (
+        SELECT 1 FROM recipe_variants rv
+        WHERE rv.recipe_id = r.id AND rv.variant_id IN (`+placeholders(len(f.VariantIDs))+`)
+    )`, int32Args(f.VariantIDs)...)
+	}
+	if len(f.CategoryIDs) > 0 {
+		w.add("r.category_id IN ("+placeholders(len(f.CategoryIDs))+")", int32Args(f.CategoryIDs)...)
+	}
+	if f.MinCookingTime != nil {
+		w.add("r.cooking_time >= ?", *f.MinCookingTime)
+	}
+	if f.MaxCookingTime != nil {
+		w.add("r.cooking_time <= ?", *f.MaxCookingTime)
+	}
+	if f.MinCalories != nil {
+		w.add("r.calories >= ?", *f.MinCalories)
+	}
+	if f.MaxCalories != nil {
+		w.add("r.calories <= ?", *f.MaxCalories)
+	}
+	if f.MinProtein != nil {
+		w.add("r.protein >= ?", *f.MinProtein)
+	}
+	if f.MaxCarbs != nil {
+		w.add("r.carbs <= ?", *f.MaxCarbs)
+	}
+	if f.MaxFat != nil {
+		w.add("r.fat <= ?", *f.MaxFat)
+	}
+	if len(f.HealthTagsAny) > 0 {
+		w.add(`EXISTS (
+        SELECT 1 FROM recipe_health_tags t
+        WHERE t.recipe_id = r.id AND t.tag IN (`+placeholders(len(f.HealthTagsAny))+`)
+    )`, stringArgs(f.HealthTagsAny)...)
+	}
+	if len(f.HealthTagsAll) > 0 {
+		args := append(stringArgs(f.HealthTagsAll), len(f.HealthTagsAll))
+		w.add(`(
+        SELECT COUNT(*) FROM recipe_health_tags t
+        WHERE t.recipe_id = r.id AND t.tag IN (`+placeholders(len(f.HealthTagsAll))+`)
+    ) = ?`, args...)
+	}
+	if len(f.TagsAny) > 0 {
+		w.add(`EXISTS (
+        SELECT 1 FROM recipe_tags rt
+        JOIN tags tg ON rt.tag_id = tg.id
+        WHERE rt.recipe_id = r.id AND tg.name IN (`+placeholders(len(f.TagsAny))+`)
+    )`, stringArgs(f.TagsAny)...)
+	}
+	if len(f.TagsAll) > 0 {
+		args := append(stringArgs(f.TagsAll), len(f.TagsAll))
+		w.add(`(
+        SELECT COUNT(*) FROM recipe_tags rt
+        JOIN tags tg ON rt.tag_id = tg.id
+        WHERE rt.recipe_id = r.id AND tg.name IN (`+placeholders(len(f.TagsAll))+`)
+    ) = ?`, args...)
+	}
+	if pattern := wordPatternOrNil(f.ExcludeIngredients); pattern != nil {
+		w.add(`NOT EXISTS (
+        SELECT 1 FROM recipe_ingredients i
+        WHERE i.recipe_id = r.id AND i.name REGEXP ?
+    )`, *pattern)
+	}
+	if len(f.ExcludeAllergens) > 0 {
+		w.add(`NOT EXISTS (
+        SELECT 1 FROM recipe_allergens a
+        WHERE a.recipe_id = r.id AND a.allergen IN (`+placeholders(len(f.ExcludeAllergens))+`)
+    )`, stringArgs(f.ExcludeAllergens)...)
+	}
+	if len(f.RecipeIDs) > 0 {
+		w.add("r.id IN ("+placeholders(len(f.RecipeIDs))+")", int32Args(f.RecipeIDs)...)
+	}
+	if len(f.ExcludeRecipeIDs) > 0 {
+		w.add("r.id NOT IN ("+placeholders(len(f.ExcludeRecipeIDs))+")", int32Args(f.ExcludeRecipeIDs)...)
+	}
+	if len(f.Pantry) > 0 {
+		missing, args := pantryMissingSQL(f.Pantry)
+		w.add("EXISTS (SELECT 1 FROM recipe_ingredients i WHERE i.recipe_id = r.id)")
+		w.add(`(
+        SELECT COUNT(*) FROM recipe_ingredients i
+        WHERE i.recipe_id = r.id AND `+missing+`
+    ) <= ?`, append(args, f.MaxMissing)...)
+	}
+
+	return w
+}
+
+// pantryCoversSQL holds when the pantry item named p.name covers the
+// ingredient i. Like the service's pantryHas, an item covers an ingredient
+// it names, whose last words it names ("rice" covers "cooked rice"), or
+// whose words it contains ("chicken breast" covers "chicken").
+const pantryCoversSQL = `(i.food_name = p.name
+                OR RIGHT(i.food_name, CHAR_LENGTH(p.name) + 1) = CONCAT(' ', p.name)
+                OR LOCATE(CONCAT(' ', i.food_name, ' '), CONCAT(' ', p.name, ' ')) > 0)`
+
+// pantryMissingSQL returns a condition on recipe_ingredients i that holds
+// when i is required and no pantry item covers it
+func pantryMissingSQL(pantry []string) (string, []interface{}) {
+	items := "SELECT ? AS name" + strings.Repeat(" UNION ALL SELECT ?", len(pantry)-1)
+	return `NOT i.is_optional AND NOT EXISTS (
+            SELECT 1 FROM (` + items + `) p
+            WHERE ` + pantryCoversSQL + `
+        )`, stringArgs(pantry)
+}
+
+// relevanceSQL returns the relevance select expression for a filter. The
+// MATCH() score is a float, so it is scaled and rounded to an integer that
+// is identical each time it is recomputed.
+func relevanceSQL(f RecipeFilter) (string, []interface{}) {
+	if f.Search != nil {
+		if query, ok := fulltextQuery(*f.Search); ok {
+			return "CAST(ROUND(" + searchMatch + " * 1000000) AS SIGNED)", []interface{}{query}
+		}
+	}
+	return "0", nil
+}
+
+// sortSQL returns the ORDER BY clause for a sort. The zero value orders
+// newest first.
+func sortSQL(s RecipeSort) string {
+	if s.Field == "" {
+		s = RecipeSort{Field: SortCreatedAt, Desc: true}
+	}
+
+	dir := " ASC"
+	if s.Desc {
+		dir = " DESC"
+	}
+	return sortExpr(s) + dir + ", r.id" + dir
+}
+
+// sortExpr returns the expression a sort orders by. Recipes without
+// calories sort last in either direction.
+func sortExpr(s RecipeSort) string {
+	switch s.Field {
+	case SortCookingTime:
+		return "r.cooking_time"
+	case SortTitle:
+		return "r.title"
+	case SortCalories:
+		if s.Desc {
+			return "COALESCE(r.calories, -1)"
+		}
+		return "COALESCE(r.calories, 2147483647)"
+	case SortRelevance:
+		return "relevance"
+	}
+	return "r.created_at"
+}
+
+// Value returns the value a row is ordered by under this sort, as used in
+// a RecipeCursor
+func (s RecipeSort) Value(row RecipeRow) interface{} {
+	switch s.Field {
+	case SortCookingTime:
+		return row.CookingTime
+	case SortTitle:
+		return row.Title
+	case SortCalories:
+		if row.Calories.Valid {
+			return row.Calories.Int32
+		}
+		if s.Desc {
+			return int32(-1)
+		}
+		return int32(math.MaxInt32)
+	case SortRelevance:
+		return row.Relevance
+	}
+	return row.CreatedAt
+}
+
+// keysetSQL returns the condition selecting rows after a cursor in sort
+// order. Relevance is recomputed since the alias is not visible in WHERE.
+func keysetSQL(f RecipeFilter, s RecipeSort, after RecipeCursor) (string, []interface{}) {
+	if s.Field == "" {
+		s = RecipeSort{Field: SortCreatedAt, Desc: true}
+	}
+
+	expr, exprArgs := sortExpr(s), []interface{}(nil)
+	if s.Field == SortRelevance {
+		expr, exprArgs = relevanceSQL(f)
+	}
+
+	op := " > "
+	if s.Desc {
+		op = " < "
+	}
+
+	var args []interface{}
+	args = append(args, exprArgs...)
+	args = append(args, after.Value)
+	args = append(args, exprArgs...)
+	args = append(args, after.Value, after.ID)
+	return "(" + expr + op + "? OR (" + expr + " = ? AND r.id" + op + "?))", args
+}
+
+// fulltextQuery turns free text into a boolean-mode query requiring every
+// word as a prefix: "nasi gore" → "+nasi* +gore*". Words shorter than the
+// index's minimum token length are dropped; ok is false if none remain.
+func fulltextQuery(search string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len([]rune(word)) >= minSearchTermLength {
+			terms = append(terms, "+"+word+"*")
+		}
+	}
+	if len(terms) == 0 {
+		return "", false
+	}
+	return strings.Join(terms, " "), true
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func int32Args(values []int32) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sonyadriko/masakyuk/internal/db"
 )
@@ -10,161 +14,601 @@ import (
 // RecipesRepository defines the interface for recipe data operations
 type RecipesRepository interface {
 	GetRecipeByID(ctx context.Context, id int32) (db.GetRecipeByIDRow, error)
-	ListRecipes(ctx context.Context, params ListRecipesParams) ([]db.ListRecipesRow, error)
-	CountRecipes(ctx context.Context, params CountRecipesParams) (int64, error)
-	GetRandomRecipe(ctx context.Context, params GetRandomRecipeParams) (db.GetRandomRecipeRow, error)
+	ListRecipes(ctx context.Context, params ListRecipesParams) ([]RecipeRow, error)
+	CountRecipes(ctx context.Context, filter RecipeFilter) (int64, error)
+	CountRecipeFacet(ctx context.Context, facet Facet, filter RecipeFilter) ([]FacetCount, error)
+	ListSpinCandidates(ctx context.Context, params SpinCandidatesParams) ([]SpinCandidate, error)
 	CreateRecipe(ctx context.Context, params CreateRecipeParams) (int64, error)
 	UpdateRecipe(ctx context.Context, params UpdateRecipeParams) error
-	DeleteRecipe(ctx context.Context, id int32) error
-	ListCategories(ctx context.Context) ([]db.Category, error)
-	ListVariants(ctx context.Context) ([]db.Variant, error)
+	DeleteRecipe(ctx context.Context, id int32) (int64, error)
+	ListRecipeHealthTags(ctx context.Context, recipeIDs []int32) ([]db.RecipeHealthTag, error)
+	ListRecipeAllergens(ctx context.Context, recipeIDs []int32) ([]db.RecipeAllergen, error)
+	ReplaceRecipeAllergens(ctx context.Context, recipeID int32, allergens []string) error
+	ListRecipeVariants(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeVariantsRow, error)
+	ListVariantNames(ctx context.Context, variantIDs []int32) ([]db.ListVariantNamesRow, error)
+	ListRecipeTags(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeTagsRow, error)
+	ListRecipesByIDs(ctx context.Context, recipeIDs []int32) ([]RecipeRow, error)
+	ListRecipeIngredients(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error)
+	ListAllRecipeIngredients(ctx context.Context) ([]db.RecipeIngredient, error)
+	ListPantryMatches(ctx context.Context, pantry []string, maxMissing, limit int32) ([]PantryCoverage, error)
+	UpdateIngredientMatch(ctx context.Context, id int32, foodName string, optional bool) error
+	ListRecipesWithoutIngredients(ctx context.Context) ([]db.ListRecipesWithoutIngredientsRow, error)
+	ReplaceRecipeIngredients(ctx context.Context, recipeID int32, items []IngredientParams) error
+	ListRecipeSteps(ctx context.Context, recipeIDs []int32) ([]db.RecipeStep, error)
+	ListRecipeStepIngredients(ctx context.Context, recipeIDs []int32) ([]db.RecipeStepIngredient, error)
+	ListRecipesWithoutSteps(ctx context.Context) ([]db.ListRecipesWithoutStepsRow, error)
+	ReplaceRecipeSteps(ctx context.Context, recipeID int32, steps []StepParams) error
+	CountRecipesNeedingBackfill(ctx context.Context) (int64, error)
+	MarkRecipesBackfilled(ctx context.Context) error
+	RecordSpin(ctx context.Context, clientID string, recipeID int32) error
+	ListLastSpins(ctx context.Context, clientID string, limit int32) ([]int32, error)
+	ListSpinsSince(ctx context.Context, clientID string, days int32) ([]int32, error)
 }
 
 // ListRecipesParams holds parameters for listing recipes
 type ListRecipesParams struct {
-	Search         *string
-	SkillLevel     *string
-	VariantID      *int32
-	CategoryID     *int32
-	MaxCookingTime *int32
-	Limit          int32
-	Offset         int32
+	RecipeFilter
+	Sort RecipeSort
+	// After switches to keyset pagination: rows after this cursor, with
+	// Offset ignored
+	After  *RecipeCursor
+	Limit  int32
+	Offset int32
 }
 
-// CountRecipesParams holds parameters for counting recipes
-type CountRecipesParams struct {
-	Search         *string
-	SkillLevel     *string
-	VariantID      *int32
-	CategoryID     *int32
-	MaxCookingTime *int32
+// SpinCandidatesParams selects the candidates of a weighted spin: the
+// recipes matching the filter, or a sample of Limit of them when more
+// match. The same Seed samples the same recipes.
+type SpinCandidatesParams struct {
+	RecipeFilter
+	Seed  int64
+	Limit int32
+	// ExpiringClientID, when set, has each candidate count the client's
+	// stocked pantry items expiring within ExpiringDays that it uses
+	ExpiringClientID string
+	ExpiringDays     int32
 }
 
-// GetRandomRecipeParams holds parameters for getting a random recipe
-type GetRandomRecipeParams struct {
-	Search         *string
-	SkillLevel     *string
-	VariantID      *int32
-	CategoryID     *int32
-	MaxCookingTime *int32
+// SpinCandidate is a recipe a spin can land on, with the columns spin
+// weights depend on
+type SpinCandidate struct {
+	ID          int32
+	CookingTime int32
+	CategoryID  int32
+	// Expiring is how many expiring pantry items the recipe uses, or 0
+	// when they were not asked for
+	Expiring int32
 }
 
 // CreateRecipeParams holds parameters for creating a recipe
 type CreateRecipeParams struct {
-	Title        string
-	Description  string
-	Ingredients  string
-	Instructions string
-	CookingTime  int32
-	SkillLevel   string
-	CategoryID   int32
-	VariantID    int32
-	ImageURL     *string
-	Servings     int32
+	Title           string
+	Description     string
+	Ingredients     string
+	Instructions    string
+	CookingTime     int32
+	SkillLevel      string
+	CategoryID      int32
+	VariantIDs      []int32
+	ImageURL        *string
+	Servings        int32
+	Calories        *int32
+	Protein         *float64
+	Carbs           *float64
+	Fat             *float64
+	HealthTags      []string
+	Tags            []string
+	Allergens       []string
+	IngredientItems []IngredientParams
+	Steps           []StepParams
 }
 
 // UpdateRecipeParams holds parameters for updating a recipe
 type UpdateRecipeParams struct {
-	ID           int32
-	Title        string
-	Description  string
-	Ingredients  string
-	Instructions string
-	CookingTime  int32
-	SkillLevel   string
-	CategoryID   int32
-	VariantID    int32
-	ImageURL     *string
-	Servings     int32
+	ID              int32
+	Title           string
+	Description     string
+	Ingredients     string
+	Instructions    string
+	CookingTime     int32
+	SkillLevel      string
+	CategoryID      int32
+	VariantIDs      []int32
+	ImageURL        *string
+	Servings        int32
+	Calories        *int32
+	Protein         *float64
+	Carbs           *float64
+	Fat             *float64
+	HealthTags      []string
+	Tags            []string
+	Allergens       []string
+	IngredientItems []IngredientParams
+	Steps           []StepParams
+}
+
+// IngredientParams holds one structured ingredient line
+type IngredientParams struct {
+	Quantity *float64
+	Unit     *string
+	Name     string
+	Note     *string
+	Group    *string
+	// FoodName is Name as pantry items are matched against it, and
+	// Optional marks an ingredient a pantry does not need to cover
+	FoodName string
+	Optional bool
+}
+
+// StepParams holds one instruction step
+type StepParams struct {
+	Instruction        string
+	DurationSeconds    *int32
+	TemperatureCelsius *int32
+	// IngredientPositions are 1-based positions in the ingredient list
+	IngredientPositions []int32
 }
 
 // recipesRepository implements RecipesRepository
 type recipesRepository struct {
+	conn    *sql.DB
 	queries *db.Queries
 }
 
 // NewRecipesRepository creates a new recipes repository
-func NewRecipesRepository(queries *db.Queries) RecipesRepository {
+func NewRecipesRepository(conn *sql.DB, queries *db.Queries) RecipesRepository {
 	return &recipesRepository{
+		conn:    conn,
 		queries: queries,
 	}
 }
 
+// withTx runs fn inside a transaction, rolling back if it returns an error
+func (r *recipesRepository) withTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	return inTx(ctx, r.conn, r.queries, fn)
+}
+
+// inTx runs fn with queries bound to a new transaction on conn, rolling
+// back if it returns an error
+func inTx(ctx context.Context, conn *sql.DB, queries *db.Queries, fn func(q *db.Queries) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(queries.WithTx(tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *recipesRepository) GetRecipeByID(ctx context.Context, id int32) (db.GetRecipeByIDRow, error) {
 	return r.queries.GetRecipeByID(ctx, id)
 }
 
-func (r *recipesRepository) ListRecipes(ctx context.Context, params ListRecipesParams) ([]db.ListRecipesRow, error) {
-	// MySQL requires duplicating nullable parameters for NULL checks
-	return r.queries.ListRecipes(ctx, db.ListRecipesParams{
-		Column1:     params.Search,
-		CONCAT:      params.Search,
-		Column3:     params.SkillLevel,
-		SkillLevel:  stringOrEmpty(params.SkillLevel),
-		Column5:     params.VariantID,
-		VariantID:   int32OrZero(params.VariantID),
-		Column7:     params.CategoryID,
-		CategoryID:  int32OrZero(params.CategoryID),
-		Column9:     params.MaxCookingTime,
-		CookingTime: int32OrZero(params.MaxCookingTime),
-		Limit:       params.Limit,
-		Offset:      params.Offset,
-	})
+func (r *recipesRepository) ListRecipes(ctx context.Context, params ListRecipesParams) ([]RecipeRow, error) {
+	w := recipeFilterWhere(params.RecipeFilter)
+	offset := params.Offset
+	if params.After != nil {
+		keyset, keysetArgs := keysetSQL(params.RecipeFilter, params.Sort, *params.After)
+		w.add(keyset, keysetArgs...)
+		offset = 0
+	}
+	relevance, args := relevanceSQL(params.RecipeFilter)
+
+	query := recipeSelect + ",\n    " + relevance + " AS relevance" + recipeJoins + w.sql() +
+		"\nORDER BY " + sortSQL(params.Sort) + "\nLIMIT ? OFFSET ?"
+	args = append(args, w.args...)
+	args = append(args, params.Limit, offset)
+
+	return r.queryRecipeRows(ctx, query, args...)
+}
+
+func (r *recipesRepository) CountRecipes(ctx context.Context, filter RecipeFilter) (int64, error) {
+	where, args := recipeFilterSQL(filter)
+
+	var count int64
+	err := r.conn.QueryRowContext(ctx, "SELECT COUNT(*)\nFROM recipes r"+where, args...).Scan(&count)
+	return count, err
 }
 
-func (r *recipesRepository) CountRecipes(ctx context.Context, params CountRecipesParams) (int64, error) {
-	// MySQL requires duplicating nullable parameters for NULL checks
-	return r.queries.CountRecipes(ctx, db.CountRecipesParams{
-		Column1:     params.Search,
-		CONCAT:      params.Search,
-		Column3:     params.SkillLevel,
-		SkillLevel:  stringOrEmpty(params.SkillLevel),
-		Column5:     params.VariantID,
-		VariantID:   int32OrZero(params.VariantID),
-		Column7:     params.CategoryID,
-		CategoryID:  int32OrZero(params.CategoryID),
-		Column9:     params.MaxCookingTime,
-		CookingTime: int32OrZero(params.MaxCookingTime),
+// ListSpinCandidates returns the recipes matching the filter, ordered by
+// a hash of their id and the seed. Taking the first Limit in that order
+// samples the same recipes for the same seed, whatever order the rows are
+// read in.
+func (r *recipesRepository) ListSpinCandidates(ctx context.Context, params SpinCandidatesParams) ([]SpinCandidate, error) {
+	expiring, args := "0", []interface{}(nil)
+	if params.ExpiringClientID != "" {
+		// An item used by several ingredients of a recipe counts once
+		expiring = `(
+        SELECT COUNT(*) FROM pantry_items p
+        WHERE p.client_id = ?
+          AND (p.quantity IS NULL OR p.quantity > 0)
+          AND p.expires_on BETWEEN CURDATE() AND CURDATE() + INTERVAL ? DAY
+          AND EXISTS (
+              SELECT 1 FROM recipe_ingredients i
+              WHERE i.recipe_id = r.id AND ` + pantryCoversSQL + `
+          )
+    )`
+		args = append(args, params.ExpiringClientID, params.ExpiringDays)
+	}
+
+	where, whereArgs := recipeFilterSQL(params.RecipeFilter)
+	query := "SELECT r.id, r.cooking_time, r.category_id, " + expiring + "\nFROM recipes r" + where +
+		"\nORDER BY CRC32(CONCAT(r.id, ':', ?)), r.id\nLIMIT ?"
+	args = append(args, whereArgs...)
+	args = append(args, params.Seed, params.Limit)
+
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []SpinCandidate{}
+	for rows.Next() {
+		var c SpinCandidate
+		if err := rows.Scan(&c.ID, &c.CookingTime, &c.CategoryID, &c.Expiring); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (r *recipesRepository) ListRecipesByIDs(ctx context.Context, recipeIDs []int32) ([]RecipeRow, error) {
+	if len(recipeIDs) == 0 {
+		return []RecipeRow{}, nil
+	}
+	return r.ListRecipes(ctx, ListRecipesParams{
+		RecipeFilter: RecipeFilter{RecipeIDs: recipeIDs},
+		Limit:        int32(len(recipeIDs)),
 	})
 }
 
+// queryRecipeRows runs a query selecting recipeSelect columns plus relevance
+func (r *recipesRepository) queryRecipeRows(ctx context.Context, query string, args ...interface{}) ([]RecipeRow, error) {
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []RecipeRow{}
+	for rows.Next() {
+		row, err := scanRecipeRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, row)
+	}
+	return recipes, rows.Err()
+}
+
 // Helper functions to convert pointers to values
-func stringOrEmpty(s *string) string {
+func stringToNull(s *string) sql.NullString {
 	if s == nil {
-		return ""
+		return sql.NullString{}
 	}
-	return *s
+	return sql.NullString{String: *s, Valid: true}
 }
 
-func int32OrZero(i *int32) int32 {
+func int32ToNull(i *int32) sql.NullInt32 {
 	if i == nil {
-		return 0
-	}
-	return *i
-}
-
-func (r *recipesRepository) GetRandomRecipe(ctx context.Context, params GetRandomRecipeParams) (db.GetRandomRecipeRow, error) {
-	// MySQL requires duplicating nullable parameters for NULL checks
-	return r.queries.GetRandomRecipe(ctx, db.GetRandomRecipeParams{
-		Column1:     params.Search,
-		CONCAT:      params.Search,
-		Column3:     params.SkillLevel,
-		SkillLevel:  stringOrEmpty(params.SkillLevel),
-		Column5:     params.VariantID,
-		VariantID:   int32OrZero(params.VariantID),
-		Column7:     params.CategoryID,
-		CategoryID:  int32OrZero(params.CategoryID),
-		Column9:     params.MaxCookingTime,
-		CookingTime: int32OrZero(params.MaxCookingTime),
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *i, Valid: true}
+}
+
+func timeToNull(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// float64ToNullDecimal formats a value for a DECIMAL(5,1) column
+func float64ToNullDecimal(f *float64) sql.NullString {
+	if f == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strconv.FormatFloat(*f, 'f', 1, 64), Valid: true}
+}
+
+// float64ToNullQuantity formats a value for a DECIMAL(10,3) column
+func float64ToNullQuantity(f *float64) sql.NullString {
+	if f == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strconv.FormatFloat(*f, 'f', 3, 64), Valid: true}
+}
+
+// wordPatternOrNil builds a REGEXP matching any of the terms at the start
+// of a word, so "peanut" also matches "roasted peanuts"
+func wordPatternOrNil(terms []string) *string {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	pattern := `\b(` + strings.Join(quoted, "|") + `)`
+	return &pattern
+}
+
+func (r *recipesRepository) ListRecipeHealthTags(ctx context.Context, recipeIDs []int32) ([]db.RecipeHealthTag, error) {
+	if len(recipeIDs) == 0 {
+		return []db.RecipeHealthTag{}, nil
+	}
+	return r.queries.ListRecipeHealthTags(ctx, recipeIDs)
+}
+
+func (r *recipesRepository) ListRecipeAllergens(ctx context.Context, recipeIDs []int32) ([]db.RecipeAllergen, error) {
+	if len(recipeIDs) == 0 {
+		return []db.RecipeAllergen{}, nil
+	}
+	return r.queries.ListRecipeAllergens(ctx, recipeIDs)
+}
+
+func (r *recipesRepository) ReplaceRecipeAllergens(ctx context.Context, recipeID int32, allergens []string) error {
+	return r.withTx(ctx, func(q *db.Queries) error {
+		return replaceAllergens(ctx, q, recipeID, allergens)
+	})
+}
+
+// replaceAllergens overwrites the detected allergens of a recipe
+func replaceAllergens(ctx context.Context, q *db.Queries, recipeID int32, allergens []string) error {
+	if err := q.DeleteRecipeAllergens(ctx, recipeID); err != nil {
+		return err
+	}
+	for _, allergen := range allergens {
+		if err := q.AddRecipeAllergen(ctx, db.AddRecipeAllergenParams{
+			RecipeID: recipeID,
+			Allergen: allergen,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *recipesRepository) ListRecipeVariants(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeVariantsRow, error) {
+	if len(recipeIDs) == 0 {
+		return []db.ListRecipeVariantsRow{}, nil
+	}
+	return r.queries.ListRecipeVariants(ctx, recipeIDs)
+}
+
+func (r *recipesRepository) ListVariantNames(ctx context.Context, variantIDs []int32) ([]db.ListVariantNamesRow, error) {
+	if len(variantIDs) == 0 {
+		return []db.ListVariantNamesRow{}, nil
+	}
+	return r.queries.ListVariantNames(ctx, variantIDs)
+}
+
+func replaceVariants(ctx context.Context, q *db.Queries, recipeID int32, variantIDs []int32) error {
+	if err := q.DeleteRecipeVariants(ctx, recipeID); err != nil {
+		return err
+	}
+	for _, variantID := range variantIDs {
+		if err := q.AddRecipeVariant(ctx, db.AddRecipeVariantParams{
+			RecipeID:  recipeID,
+			VariantID: variantID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *recipesRepository) ListRecipeTags(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeTagsRow, error) {
+	if len(recipeIDs) == 0 {
+		return []db.ListRecipeTagsRow{}, nil
+	}
+	return r.queries.ListRecipeTags(ctx, recipeIDs)
+}
+
+// replaceTags overwrites the tags of a recipe, creating tags that do not
+// exist yet
+func replaceTags(ctx context.Context, q *db.Queries, recipeID int32, tags []string) error {
+	if err := q.DeleteRecipeTags(ctx, recipeID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := q.EnsureTag(ctx, tag); err != nil {
+			return err
+		}
+		if err := q.AddRecipeTag(ctx, db.AddRecipeTagParams{
+			RecipeID: recipeID,
+			Name:     tag,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceHealthTags overwrites the health tags of a recipe
+func replaceHealthTags(ctx context.Context, q *db.Queries, recipeID int32, tags []string) error {
+	if err := q.DeleteRecipeHealthTags(ctx, recipeID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := q.AddRecipeHealthTag(ctx, db.AddRecipeHealthTagParams{
+			RecipeID: recipeID,
+			Tag:      tag,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *recipesRepository) ListRecipeIngredients(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error) {
+	if len(recipeIDs) == 0 {
+		return []db.RecipeIngredient{}, nil
+	}
+	return r.queries.ListRecipeIngredients(ctx, recipeIDs)
+}
+
+func (r *recipesRepository) ListAllRecipeIngredients(ctx context.Context) ([]db.RecipeIngredient, error) {
+	return r.queries.ListAllRecipeIngredients(ctx)
+}
+
+// PantryCoverage is how many of a recipe's required ingredients a pantry
+// does not cover
+type PantryCoverage struct {
+	RecipeID int32
+	Required int32
+	Missing  int32
+}
+
+// ListPantryMatches ranks the recipes with structured ingredients that
+// miss at most maxMissing required ingredients from pantry: fewest missing
+// first, then by the share of required ingredients covered, then by id.
+// pantry holds food names, as stored in recipe_ingredients.food_name.
+func (r *recipesRepository) ListPantryMatches(ctx context.Context, pantry []string, maxMissing, limit int32) ([]PantryCoverage, error) {
+	missing, args := pantryMissingSQL(pantry)
+	query := `SELECT m.recipe_id, m.required, m.missing
+FROM (
+    SELECT i.recipe_id, SUM(NOT i.is_optional) AS required, SUM(` + missing + `) AS missing
+    FROM recipe_ingredients i
+    GROUP BY i.recipe_id
+) m
+WHERE m.missing <= ?
+ORDER BY m.missing, IF(m.required = 0, 1, (m.required - m.missing) / m.required) DESC, m.recipe_id
+LIMIT ?`
+
+	rows, err := r.conn.QueryContext(ctx, query, append(args, maxMissing, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []PantryCoverage{}
+	for rows.Next() {
+		var c PantryCoverage
+		if err := rows.Scan(&c.RecipeID, &c.Required, &c.Missing); err != nil {
+			return nil, err
+		}
+		matches = append(matches, c)
+	}
+	return matches, rows.Err()
+}
+
+// UpdateIngredientMatch sets the pantry match columns of one ingredient row
+func (r *recipesRepository) UpdateIngredientMatch(ctx context.Context, id int32, foodName string, optional bool) error {
+	return r.queries.UpdateRecipeIngredientMatch(ctx, db.UpdateRecipeIngredientMatchParams{
+		FoodName:   foodName,
+		IsOptional: optional,
+		ID:         id,
+	})
+}
+
+func (r *recipesRepository) ListRecipesWithoutIngredients(ctx context.Context) ([]db.ListRecipesWithoutIngredientsRow, error) {
+	return r.queries.ListRecipesWithoutIngredients(ctx)
+}
+
+func (r *recipesRepository) ReplaceRecipeIngredients(ctx context.Context, recipeID int32, items []IngredientParams) error {
+	return r.withTx(ctx, func(q *db.Queries) error {
+		return replaceIngredients(ctx, q, recipeID, items)
 	})
 }
 
-func (r *recipesRepository) ListCategories(ctx context.Context) ([]db.Category, error) {
-	return r.queries.ListCategories(ctx)
+// replaceIngredients overwrites the structured ingredients of a recipe,
+// keeping the given order
+func replaceIngredients(ctx context.Context, q *db.Queries, recipeID int32, items []IngredientParams) error {
+	if err := q.DeleteRecipeIngredients(ctx, recipeID); err != nil {
+		return err
+	}
+	for i, item := range items {
+		if err := q.AddRecipeIngredient(ctx, db.AddRecipeIngredientParams{
+			RecipeID:   recipeID,
+			Position:   int32(i + 1),
+			Quantity:   float64ToNullQuantity(item.Quantity),
+			Unit:       stringToNull(item.Unit),
+			Name:       item.Name,
+			FoodName:   item.FoodName,
+			Note:       stringToNull(item.Note),
+			IsOptional: item.Optional,
+			GroupName:  stringToNull(item.Group),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *recipesRepository) ListRecipeSteps(ctx context.Context, recipeIDs []int32) ([]db.RecipeStep, error) {
+	if len(recipeIDs) == 0 {
+		return []db.RecipeStep{}, nil
+	}
+	return r.queries.ListRecipeSteps(ctx, recipeIDs)
+}
+
+func (r *recipesRepository) ListRecipeStepIngredients(ctx context.Context, recipeIDs []int32) ([]db.RecipeStepIngredient, error) {
+	if len(recipeIDs) == 0 {
+		return []db.RecipeStepIngredient{}, nil
+	}
+	return r.queries.ListRecipeStepIngredients(ctx, recipeIDs)
+}
+
+func (r *recipesRepository) ListRecipesWithoutSteps(ctx context.Context) ([]db.ListRecipesWithoutStepsRow, error) {
+	return r.queries.ListRecipesWithoutSteps(ctx)
+}
+
+// CountRecipesNeedingBackfill counts recipes written outside the API that
+// the backfill command has not converted yet
+func (r *recipesRepository) CountRecipesNeedingBackfill(ctx context.Context) (int64, error) {
+	return r.queries.CountRecipesNeedingBackfill(ctx)
 }
 
-func (r *recipesRepository) ListVariants(ctx context.Context) ([]db.Variant, error) {
-	return r.queries.ListVariants(ctx)
+func (r *recipesRepository) MarkRecipesBackfilled(ctx context.Context) error {
+	return r.queries.MarkRecipesBackfilled(ctx)
+}
+
+func (r *recipesRepository) ReplaceRecipeSteps(ctx context.Context, recipeID int32, steps []StepParams) error {
+	return r.withTx(ctx, func(q *db.Queries) error {
+		return replaceSteps(ctx, q, recipeID, steps)
+	})
+}
+
+// replaceSteps overwrites the instruction steps of a recipe, keeping the
+// given order
+func replaceSteps(ctx context.Context, q *db.Queries, recipeID int32, steps []StepParams) error {
+	if err := q.DeleteRecipeSteps(ctx, recipeID); err != nil {
+		return err
+	}
+	for i, step := range steps {
+		result, err := q.AddRecipeStep(ctx, db.AddRecipeStepParams{
+			RecipeID:           recipeID,
+			Position:           int32(i + 1),
+			Instruction:        step.Instruction,
+			DurationSeconds:    int32ToNull(step.DurationSeconds),
+			TemperatureCelsius: int32ToNull(step.TemperatureCelsius),
+		})
+		if err != nil {
+			return err
+		}
+
+		stepID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, position := range step.IngredientPositions {
+			if err := q.AddRecipeStepIngredient(ctx, db.AddRecipeStepIngredientParams{
+				StepID:             int32(stepID),
+				IngredientPosition: position,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (r *recipesRepository) CreateRecipe(ctx context.Context, params CreateRecipeParams) (int64, error) {
@@ -173,23 +617,59 @@ func (r *recipesRepository) CreateRecipe(ctx context.Context, params CreateRecip
 		imageURL = sql.NullString{String: *params.ImageURL, Valid: true}
 	}
 
-	result, err := r.queries.CreateRecipe(ctx, db.CreateRecipeParams{
-		Title:        params.Title,
-		Description:  params.Description,
-		Ingredients:  params.Ingredients,
-		Instructions: params.Instructions,
-		CookingTime:  params.CookingTime,
-		SkillLevel:   params.SkillLevel,
-		CategoryID:   params.CategoryID,
-		VariantID:    params.VariantID,
-		ImageUrl:     imageURL,
-		Servings:     params.Servings,
+	var id int64
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		result, err := q.CreateRecipe(ctx, db.CreateRecipeParams{
+			Title:        params.Title,
+			Description:  params.Description,
+			Ingredients:  params.Ingredients,
+			Instructions: params.Instructions,
+			CookingTime:  params.CookingTime,
+			SkillLevel:   params.SkillLevel,
+			CategoryID:   params.CategoryID,
+			ImageUrl:     imageURL,
+			Servings:     params.Servings,
+			Calories:     int32ToNull(params.Calories),
+			Protein:      float64ToNullDecimal(params.Protein),
+			Carbs:        float64ToNullDecimal(params.Carbs),
+			Fat:          float64ToNullDecimal(params.Fat),
+		})
+		if err != nil {
+			return err
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		if err := replaceVariants(ctx, q, int32(id), params.VariantIDs); err != nil {
+			return err
+		}
+
+		if err := replaceHealthTags(ctx, q, int32(id), params.HealthTags); err != nil {
+			return err
+		}
+
+		if err := replaceTags(ctx, q, int32(id), params.Tags); err != nil {
+			return err
+		}
+
+		if err := replaceIngredients(ctx, q, int32(id), params.IngredientItems); err != nil {
+			return err
+		}
+
+		if err := replaceAllergens(ctx, q, int32(id), params.Allergens); err != nil {
+			return err
+		}
+
+		return replaceSteps(ctx, q, int32(id), params.Steps)
 	})
 	if err != nil {
-		return 0, err
+		return 0, translateError(err)
 	}
 
-	return result.LastInsertId()
+	return id, nil
 }
 
 func (r *recipesRepository) UpdateRecipe(ctx context.Context, params UpdateRecipeParams) error {
@@ -198,21 +678,104 @@ func (r *recipesRepository) UpdateRecipe(ctx context.Context, params UpdateRecip
 		imageURL = sql.NullString{String: *params.ImageURL, Valid: true}
 	}
 
-	return r.queries.UpdateRecipe(ctx, db.UpdateRecipeParams{
-		Title:        params.Title,
-		Description:  params.Description,
-		Ingredients:  params.Ingredients,
-		Instructions: params.Instructions,
-		CookingTime:  params.CookingTime,
-		SkillLevel:   params.SkillLevel,
-		CategoryID:   params.CategoryID,
-		VariantID:    params.VariantID,
-		ImageUrl:     imageURL,
-		Servings:     params.Servings,
-		ID:           params.ID,
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		err := q.UpdateRecipe(ctx, db.UpdateRecipeParams{
+			Title:        params.Title,
+			Description:  params.Description,
+			Ingredients:  params.Ingredients,
+			Instructions: params.Instructions,
+			CookingTime:  params.CookingTime,
+			SkillLevel:   params.SkillLevel,
+			CategoryID:   params.CategoryID,
+			ImageUrl:     imageURL,
+			Servings:     params.Servings,
+			Calories:     int32ToNull(params.Calories),
+			Protein:      float64ToNullDecimal(params.Protein),
+			Carbs:        float64ToNullDecimal(params.Carbs),
+			Fat:          float64ToNullDecimal(params.Fat),
+			ID:           params.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := replaceVariants(ctx, q, params.ID, params.VariantIDs); err != nil {
+			return err
+		}
+
+		if err := replaceHealthTags(ctx, q, params.ID, params.HealthTags); err != nil {
+			return err
+		}
+
+		if err := replaceTags(ctx, q, params.ID, params.Tags); err != nil {
+			return err
+		}
+
+		if err := replaceIngredients(ctx, q, params.ID, params.IngredientItems); err != nil {
+			return err
+		}
+
+		if err := replaceAllergens(ctx, q, params.ID, params.Allergens); err != nil {
+			return err
+		}
+
+		return replaceSteps(ctx, q, params.ID, params.Steps)
 	})
+	return translateError(err)
+}
+
+// DeleteRecipe deletes a recipe. Its meal plan entries are removed with it
+// by the foreign key; the plans they belonged to are marked as updated.
+// It returns how many entries were removed.
+func (r *recipesRepository) DeleteRecipe(ctx context.Context, id int32) (int64, error) {
+	var removed int64
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		if removed, err = q.CountMealPlanEntriesWithRecipe(ctx, id); err != nil {
+			return err
+		}
+		if err := q.TouchMealPlansWithRecipe(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteRecipe(ctx, id)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
 }
 
-func (r *recipesRepository) DeleteRecipe(ctx context.Context, id int32) error {
-	return r.queries.DeleteRecipe(ctx, id)
+func (r *recipesRepository) RecordSpin(ctx context.Context, clientID string, recipeID int32) error {
+	return r.queries.RecordSpin(ctx, db.RecordSpinParams{
+		ClientID: clientID,
+		RecipeID: recipeID,
+	})
+}
+
+// ListLastSpins returns the recipes of a client's last spins, most recent
+// first. A recipe spun more than once appears more than once.
+func (r *recipesRepository) ListLastSpins(ctx context.Context, clientID string, limit int32) ([]int32, error) {
+	return r.queries.ListLastSpins(ctx, db.ListLastSpinsParams{
+		ClientID: clientID,
+		Limit:    limit,
+	})
+}
+
+// ListSpinsSince returns the distinct recipes a client spun in the last
+// days, most recently spun first
+func (r *recipesRepository) ListSpinsSince(ctx context.Context, clientID string, days int32) ([]int32, error) {
+	rows, err := r.queries.ListSpinsSince(ctx, db.ListSpinsSinceParams{
+		ClientID: clientID,
+		Days:     days,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int32, len(rows))
+	for i, row := range rows {
+		ids[i] = row.RecipeID
+	}
+	return ids, nil
 }
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// TagsRepository defines the interface for tag data operations
+type TagsRepository interface {
+	ListTags(ctx context.Context) ([]db.ListTagsRow, error)
+	GetTagByID(ctx context.Context, id int32) (db.GetTagByIDRow, error)
+	AutocompleteTags(ctx context.Context, prefix string, limit int32) ([]db.AutocompleteTagsRow, error)
+	CreateTag(ctx context.Context, name string) (int64, error)
+	UpdateTag(ctx context.Context, id int32, name string) error
+	DeleteTag(ctx context.Context, id int32) error
+}
+
+// tagsRepository implements TagsRepository
+type tagsRepository struct {
+	queries *db.Queries
+}
+
+// NewTagsRepository creates a new tags repository
+func NewTagsRepository(queries *db.Queries) TagsRepository {
+	return &tagsRepository{
+		queries: queries,
+	}
+}
+
+func (r *tagsRepository) ListTags(ctx context.Context) ([]db.ListTagsRow, error) {
+	return r.queries.ListTags(ctx)
+}
+
+func (r *tagsRepository) GetTagByID(ctx context.Context, id int32) (db.GetTagByIDRow, error) {
+	return r.queries.GetTagByID(ctx, id)
+}
+
+// AutocompleteTags returns the tags starting with prefix, most used first
+func (r *tagsRepository) AutocompleteTags(ctx context.Context, prefix string, limit int32) ([]db.AutocompleteTagsRow, error) {
+	return r.queries.AutocompleteTags(ctx, db.AutocompleteTagsParams{
+		Prefix: prefix,
+		Limit:  limit,
+	})
+}
+
+func (r *tagsRepository) CreateTag(ctx context.Context, name string) (int64, error) {
+	result, err := r.queries.CreateTag(ctx, name)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *tagsRepository) UpdateTag(ctx context.Context, id int32, name string) error {
+	err := r.queries.UpdateTag(ctx, db.UpdateTagParams{
+		Name: name,
+		ID:   id,
+	})
+	return translateError(err)
+}
+
+func (r *tagsRepository) DeleteTag(ctx context.Context, id int32) error {
+	return translateError(r.queries.DeleteTag(ctx, id))
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// UsersRepository defines the interface for user account and refresh
+// token data operations. Refresh tokens are looked up by their hash.
+type UsersRepository interface {
+	CreateUser(ctx context.Context, params db.CreateUserParams) (int64, error)
+	GetUserByID(ctx context.Context, id int32) (db.User, error)
+	GetUserByEmail(ctx context.Context, email string) (db.User, error)
+	CreateRefreshToken(ctx context.Context, params db.CreateRefreshTokenParams) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (db.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, id int32, next db.CreateRefreshTokenParams) (bool, error)
+	RevokeRefreshToken(ctx context.Context, id int32) (bool, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID int32) error
+}
+
+// usersRepository implements UsersRepository
+type usersRepository struct {
+	conn    *sql.DB
+	queries *db.Queries
+}
+
+// NewUsersRepository creates a new users repository
+func NewUsersRepository(conn *sql.DB, queries *db.Queries) UsersRepository {
+	return &usersRepository{
+		conn:    conn,
+		queries: queries,
+	}
+}
+
+func (r *usersRepository) CreateUser(ctx context.Context, params db.CreateUserParams) (int64, error) {
+	result, err := r.queries.CreateUser(ctx, params)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *usersRepository) GetUserByID(ctx context.Context, id int32) (db.User, error) {
+	return r.queries.GetUserByID(ctx, id)
+}
+
+func (r *usersRepository) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
+	return r.queries.GetUserByEmail(ctx, email)
+}
+
+func (r *usersRepository) CreateRefreshToken(ctx context.Context, params db.CreateRefreshTokenParams) error {
+	return translateError(r.queries.CreateRefreshToken(ctx, params))
+}
+
+func (r *usersRepository) GetRefreshToken(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	return r.queries.GetRefreshTokenByHash(ctx, tokenHash)
+}
+
+// RotateRefreshToken revokes a token and stores its replacement in one
+// transaction. It reports false, storing nothing, when the token had
+// already been revoked, such as by a concurrent refresh.
+func (r *usersRepository) RotateRefreshToken(ctx context.Context, id int32, next db.CreateRefreshTokenParams) (bool, error) {
+	var rotated bool
+	err := inTx(ctx, r.conn, r.queries, func(q *db.Queries) error {
+		revoked, err := q.RevokeRefreshToken(ctx, id)
+		if err != nil || revoked == 0 {
+			return err
+		}
+
+		rotated = true
+		return q.CreateRefreshToken(ctx, next)
+	})
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return rotated, nil
+}
+
+// RevokeRefreshToken reports false when the token was already revoked
+func (r *usersRepository) RevokeRefreshToken(ctx context.Context, id int32) (bool, error) {
+	revoked, err := r.queries.RevokeRefreshToken(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return revoked > 0, nil
+}
+
+func (r *usersRepository) RevokeUserRefreshTokens(ctx context.Context, userID int32) error {
+	return r.queries.RevokeUserRefreshTokens(ctx, userID)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// VariantsRepository defines the interface for variant data operations
+type VariantsRepository interface {
+	ListVariants(ctx context.Context) ([]db.ListVariantsRow, error)
+	GetVariantByID(ctx context.Context, id int32) (db.GetVariantByIDRow, error)
+	CreateVariant(ctx context.Context, params VariantParams) (int64, error)
+	UpdateVariant(ctx context.Context, id int32, params VariantParams) error
+	DeleteVariant(ctx context.Context, id int32) error
+}
+
+// VariantParams holds parameters for creating or updating a variant
+type VariantParams struct {
+	Name        string
+	Description *string
+}
+
+// variantsRepository implements VariantsRepository
+type variantsRepository struct {
+	queries *db.Queries
+}
+
+// NewVariantsRepository creates a new variants repository
+func NewVariantsRepository(queries *db.Queries) VariantsRepository {
+	return &variantsRepository{
+		queries: queries,
+	}
+}
+
+func (r *variantsRepository) ListVariants(ctx context.Context) ([]db.ListVariantsRow, error) {
+	return r.queries.ListVariants(ctx)
+}
+
+func (r *variantsRepository) GetVariantByID(ctx context.Context, id int32) (db.GetVariantByIDRow, error) {
+	return r.queries.GetVariantByID(ctx, id)
+}
+
+func (r *variantsRepository) CreateVariant(ctx context.Context, params VariantParams) (int64, error) {
+	result, err := r.queries.CreateVariant(ctx, db.CreateVariantParams{
+		Name:        params.Name,
+		Description: stringToNull(params.Description),
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *variantsRepository) UpdateVariant(ctx context.Context, id int32, params VariantParams) error {
+	err := r.queries.UpdateVariant(ctx, db.UpdateVariantParams{
+		Name:        params.Name,
+		Description: stringToNull(params.Description),
+		ID:          id,
+	})
+	return translateError(err)
+}
+
+func (r *variantsRepository) DeleteVariant(ctx context.Context, id int32) error {
+	return translateError(r.queries.DeleteVariant(ctx, id))
+}
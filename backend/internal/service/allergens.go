@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// allergenRule lists the ingredient words that indicate an allergen.
+// Phrases in except are removed from the name first so that, for example,
+// "coconut milk" is not read as dairy.
+type allergenRule struct {
+	keywords []string
+	except   []string
+}
+
+// allergenRules is the allergen vocabulary. Keywords are singular; names
+// are singularized before matching.
+var allergenRules = map[string]allergenRule{
+	"peanut": {
+		keywords: []string{"peanut", "groundnut", "satay sauce"},
+	},
+	"tree-nut": {
+		keywords: []string{"almond", "cashew", "walnut", "pecan", "hazelnut", "pistachio", "macadamia", "candlenut"},
+	},
+	"shellfish": {
+		keywords: []string{"shrimp", "prawn", "crab", "lobster", "squid", "clam", "mussel", "oyster", "scallop", "terasi", "belacan", "oyster sauce"},
+	},
+	"fish": {
+		keywords: []string{"fish", "anchovy", "tuna", "salmon", "mackerel", "snapper", "tilapia", "catfish", "milkfish", "sardine"},
+	},
+	"dairy": {
+		keywords: []string{"milk", "butter", "cheese", "cream", "yogurt", "ghee", "parmesan", "mozzarella"},
+		except:   []string{"coconut milk", "coconut cream", "peanut butter", "soy milk", "almond milk", "oat milk", "cream of coconut"},
+	},
+	"egg": {
+		keywords: []string{"egg", "mayonnaise"},
+	},
+	"gluten": {
+		keywords: []string{"wheat", "flour", "bread", "breadcrumb", "panko", "noodle", "pasta", "spaghetti", "fettuccine", "macaroni", "barley", "rye", "soy sauce", "kecap", "tempura", "oyster sauce"},
+		except:   []string{"rice flour", "glutinous rice flour", "tapioca flour", "corn flour", "cornflour", "rice noodle", "glass noodle", "rice vermicelli", "gluten-free", "tamari"},
+	},
+	"soy": {
+		keywords: []string{"soy", "soya", "tofu", "tempeh", "edamame", "miso", "kecap"},
+	},
+	"sesame": {
+		keywords: []string{"sesame", "tahini"},
+	},
+}
+
+// Allergens returns the allergen vocabulary in alphabetical order
+func Allergens() []string {
+	names := make([]string, 0, len(allergenRules))
+	for name := range allergenRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectAllergens derives allergens from ingredient names, returned sorted
+func DetectAllergens(items []Ingredient) []string {
+	found := map[string]bool{}
+	for _, item := range items {
+		name := foodName(item.Name)
+		for allergen, rule := range allergenRules {
+			if !found[allergen] && rule.matches(name) {
+				found[allergen] = true
+			}
+		}
+	}
+
+	allergens := make([]string, 0, len(found))
+	for allergen := range found {
+		allergens = append(allergens, allergen)
+	}
+	sort.Strings(allergens)
+	return allergens
+}
+
+func (r allergenRule) matches(name string) bool {
+	for _, phrase := range r.except {
+		name = strings.ReplaceAll(name, foodName(phrase), " ")
+	}
+	for _, keyword := range r.keywords {
+		if containsWord(name, foodName(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeAllergens lowercases and de-duplicates allergens, rejecting
+// anything outside the vocabulary
+func normalizeAllergens(allergens []string) ([]string, error) {
+	normalized := make([]string, 0, len(allergens))
+	seen := make(map[string]bool, len(allergens))
+	for _, allergen := range allergens {
+		allergen = strings.ToLower(strings.TrimSpace(allergen))
+		if allergen == "" || seen[allergen] {
+			continue
+		}
+		if _, ok := allergenRules[allergen]; !ok {
+			return nil, fmt.Errorf("%w: unknown allergen %q (valid: %s)", ErrInvalidParams, allergen, strings.Join(Allergens(), ", "))
+		}
+		seen[allergen] = true
+		normalized = append(normalized, allergen)
+	}
+	return normalized, nil
+}
+
+// normalizeExclusions lowercases, singularizes and de-duplicates excluded
+// ingredient words. The database matches them at the start of a word, so
+// "peanut" also excludes "roasted peanuts".
+func normalizeExclusions(terms []string) ([]string, error) {
+	if len(terms) > maxPantryItems {
+		return nil, fmt.Errorf("%w: at most %d excluded ingredients are allowed", ErrInvalidParams, maxPantryItems)
+	}
+
+	normalized := make([]string, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		term = foodName(term)
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		normalized = append(normalized, term)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectAllergens(t *testing.T) {
+	tests := []struct {
+		ingredients string
+		want        []string
+	}{
+		{"2 cups cooked rice, 2 eggs, 2 tbsp sweet soy sauce, 1 tsp shrimp paste", []string{"egg", "gluten", "shellfish", "soy"}},
+		{"400ml coconut milk, 1 cup rice flour, 200g rice noodles", []string{}},
+		{"2 tbsp peanut butter, 100g butter, 1 cup flour", []string{"dairy", "gluten", "peanut"}},
+		{"1 eggplant, 2 tbsp fish sauce, 50g roasted cashews", []string{"fish", "tree-nut"}},
+		{"Tofu, tempeh, 1 tbsp sesame oil", []string{"sesame", "soy"}},
+	}
+
+	for _, tt := range tests {
+		got := DetectAllergens(ParseIngredients(tt.ingredients))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.ingredients, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+)
+
+// Account limits and token defaults. bcrypt ignores anything past 72
+// bytes of a password, so longer ones are rejected.
+const (
+	maxEmailLength         = 255
+	minPasswordLength      = 8
+	maxPasswordLength      = 72
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	refreshTokenBytes      = 32
+	accessTokenIssuer      = "masakyuk"
+)
+
+// unknownUserHash is compared against on logins with an unknown email, so
+// they take as long as a wrong password
+const unknownUserHash = "$2a$10$JtPg2boVr6xyfsYlD6/CVu10uBCpzwP36de3.vsh5JG35bE/47SYG"
+
+// User is a registered account
+type User struct {
+	ID        int32     `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Credentials holds the body of a register or login request
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// AuthTokens is issued on register, login and refresh. AccessToken is a
+// JWT to send as "Authorization: Bearer <token>" for ExpiresIn seconds;
+// RefreshToken can be exchanged once for a new pair.
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	User         User   `json:"user"`
+}
+
+// AuthService defines the interface for user accounts and tokens
+type AuthService interface {
+	Register(ctx context.Context, req Credentials) (*AuthTokens, error)
+	Login(ctx context.Context, req Credentials) (*AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*AuthTokens, error)
+	Logout(ctx context.Context, refreshToken string) error
+	// Authenticate checks an access token and returns its user's ID
+	Authenticate(accessToken string) (int32, error)
+}
+
+type authService struct {
+	repo            repository.UsersRepository
+	secret          []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	passwordCost    int
+	now             func() time.Time
+}
+
+// AuthServiceOption configures an auth service
+type AuthServiceOption func(*authService)
+
+// WithTokenLifetimes sets how long access and refresh tokens are valid,
+// 15 minutes and 30 days by default. Zero keeps the default.
+func WithTokenLifetimes(access, refresh time.Duration) AuthServiceOption {
+	return func(s *authService) {
+		if access > 0 {
+			s.accessTokenTTL = access
+		}
+		if refresh > 0 {
+			s.refreshTokenTTL = refresh
+		}
+	}
+}
+
+// NewAuthService creates a new auth service signing access tokens with
+// secret
+func NewAuthService(repo repository.UsersRepository, secret []byte, opts ...AuthServiceOption) AuthService {
+	s := &authService{
+		repo:            repo,
+		secret:          secret,
+		accessTokenTTL:  defaultAccessTokenTTL,
+		refreshTokenTTL: defaultRefreshTokenTTL,
+		passwordCost:    bcrypt.DefaultCost,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func userFromRow(row db.User) User {
+	return User{
+		ID:        row.ID,
+		Email:     row.Email,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+// Register creates an account and signs it in
+func (s *authService) Register(ctx context.Context, req Credentials) (*AuthTokens, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Password) < minPasswordLength || len(req.Password) > maxPasswordLength {
+		return nil, fmt.Errorf("%w: password must be between %d and %d characters", ErrInvalidParams, minPasswordLength, maxPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.passwordCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	id, err := s.repo.CreateUser(ctx, db.CreateUserParams{
+		Email:        email,
+		PasswordHash: string(hash),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: %s is already registered", ErrConflict, email)
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	row, err := s.repo.GetUserByID(ctx, int32(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.issueTokens(ctx, row)
+}
+
+// Login checks an email and password. An unknown email and a wrong
+// password fail alike.
+func (s *authService) Login(ctx context.Context, req Credentials) (*AuthTokens, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" || req.Password == "" {
+		return nil, fmt.Errorf("%w: email and password are required", ErrInvalidParams)
+	}
+
+	row, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = bcrypt.CompareHashAndPassword([]byte(unknownUserHash), []byte(req.Password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(row.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, row)
+}
+
+// Refresh exchanges a refresh token for a new pair. Each refresh token
+// works once: presenting a revoked one again is treated as theft and
+// signs the user out everywhere.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*AuthTokens, error) {
+	token, err := s.getRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RevokedAt.Valid {
+		return nil, s.revokeAll(ctx, token.UserID)
+	}
+	if !s.now().Before(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	row, err := s.repo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	next, params, err := s.newRefreshToken(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.repo.RotateRefreshToken(ctx, token.ID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		return nil, s.revokeAll(ctx, token.UserID)
+	}
+
+	return s.tokens(row, next)
+}
+
+// Logout revokes a refresh token. Unknown and already revoked tokens are
+// ignored; access tokens stay valid until they expire.
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.getRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil
+		}
+		return err
+	}
+
+	if _, err := s.repo.RevokeRefreshToken(ctx, token.ID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *authService) Authenticate(accessToken string) (int32, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(accessToken, &claims, s.signingKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(accessTokenIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	id, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil || id < 1 {
+		return 0, ErrInvalidToken
+	}
+
+	return int32(id), nil
+}
+
+// signingKey is the jwt.Keyfunc for access tokens; the parser has already
+// checked that they use HS256
+func (s *authService) signingKey(*jwt.Token) (interface{}, error) {
+	return s.secret, nil
+}
+
+// issueTokens signs a user in with a new refresh token
+func (s *authService) issueTokens(ctx context.Context, row db.User) (*AuthTokens, error) {
+	refreshToken, params, err := s.newRefreshToken(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRefreshToken(ctx, params); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return s.tokens(row, refreshToken)
+}
+
+// tokens signs an access token to go with a stored refresh token
+func (s *authService) tokens(row db.User, refreshToken string) (*AuthTokens, error) {
+	now := s.now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    accessTokenIssuer,
+		Subject:   strconv.Itoa(int(row.ID)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTokenTTL / time.Second),
+		RefreshToken: refreshToken,
+		User:         userFromRow(row),
+	}, nil
+}
+
+// newRefreshToken generates a random refresh token and the row storing
+// its hash
+func (s *authService) newRefreshToken(userID int32) (string, db.CreateRefreshTokenParams, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", db.CreateRefreshTokenParams{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, db.CreateRefreshTokenParams{
+		UserID:    userID,
+		TokenHash: hashRefreshToken(token),
+		ExpiresAt: s.now().Add(s.refreshTokenTTL),
+	}, nil
+}
+
+// getRefreshToken looks up a refresh token by its hash
+func (s *authService) getRefreshToken(ctx context.Context, refreshToken string) (db.RefreshToken, error) {
+	if strings.TrimSpace(refreshToken) == "" {
+		return db.RefreshToken{}, fmt.Errorf("%w: refresh_token is required", ErrInvalidParams)
+	}
+
+	token, err := s.repo.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.RefreshToken{}, ErrInvalidToken
+		}
+		return db.RefreshToken{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// revokeAll signs a user out everywhere after a refresh token was reused.
+// It returns the error to report for the reused token.
+func (s *authService) revokeAll(ctx context.Context, userID int32) error {
+	if err := s.repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return ErrInvalidToken
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail lowercases and checks an email address
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", fmt.Errorf("%w: email is required", ErrInvalidParams)
+	}
+	if len(email) > maxEmailLength {
+		return "", fmt.Errorf("%w: email must be at most %d characters", ErrInvalidParams, maxEmailLength)
+	}
+
+	// Reject display names ("Ann <ann@example.com>") and hosts without a
+	// dot, which net/mail accepts
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return "", fmt.Errorf("%w: email is not a valid address", ErrInvalidParams)
+	}
+
+	return email, nil
+}
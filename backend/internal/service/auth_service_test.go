@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Mock repository for testing, keeping users and refresh tokens in memory
+type mockUsersRepository struct {
+	users  []db.User
+	tokens []db.RefreshToken
+}
+
+func (m *mockUsersRepository) CreateUser(ctx context.Context, params db.CreateUserParams) (int64, error) {
+	for _, user := range m.users {
+		if user.Email == params.Email {
+			return 0, repository.ErrDuplicate
+		}
+	}
+	id := int32(len(m.users) + 1)
+	m.users = append(m.users, db.User{ID: id, Email: params.Email, PasswordHash: params.PasswordHash})
+	return int64(id), nil
+}
+
+func (m *mockUsersRepository) GetUserByID(ctx context.Context, id int32) (db.User, error) {
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (m *mockUsersRepository) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (m *mockUsersRepository) CreateRefreshToken(ctx context.Context, params db.CreateRefreshTokenParams) error {
+	m.tokens = append(m.tokens, db.RefreshToken{
+		ID:        int32(len(m.tokens) + 1),
+		UserID:    params.UserID,
+		TokenHash: params.TokenHash,
+		ExpiresAt: params.ExpiresAt,
+	})
+	return nil
+}
+
+func (m *mockUsersRepository) GetRefreshToken(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return db.RefreshToken{}, sql.ErrNoRows
+}
+
+func (m *mockUsersRepository) RotateRefreshToken(ctx context.Context, id int32, next db.CreateRefreshTokenParams) (bool, error) {
+	if revoked, _ := m.RevokeRefreshToken(ctx, id); !revoked {
+		return false, nil
+	}
+	return true, m.CreateRefreshToken(ctx, next)
+}
+
+func (m *mockUsersRepository) RevokeRefreshToken(ctx context.Context, id int32) (bool, error) {
+	for i := range m.tokens {
+		if m.tokens[i].ID == id && !m.tokens[i].RevokedAt.Valid {
+			m.tokens[i].RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockUsersRepository) RevokeUserRefreshTokens(ctx context.Context, userID int32) error {
+	for i := range m.tokens {
+		if m.tokens[i].UserID == userID && !m.tokens[i].RevokedAt.Valid {
+			m.tokens[i].RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+// activeTokens counts the refresh tokens that have not been revoked
+func (m *mockUsersRepository) activeTokens() int {
+	n := 0
+	for _, token := range m.tokens {
+		if !token.RevokedAt.Valid {
+			n++
+		}
+	}
+	return n
+}
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestAuthService uses the cheapest bcrypt cost and a clock the test
+// can move
+func newTestAuthService(repo repository.UsersRepository, now *time.Time) AuthService {
+	s := NewAuthService(repo, testSecret).(*authService)
+	s.passwordCost = bcrypt.MinCost
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestRegister(t *testing.T) {
+	repo := &mockUsersRepository{}
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	service := newTestAuthService(repo, &now)
+
+	tokens, err := service.Register(context.Background(), Credentials{Email: " Ann@Example.com ", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if tokens.User.ID != 1 || tokens.User.Email != "ann@example.com" {
+		t.Errorf("Expected user 1 with a lowercased email, got %+v", tokens.User)
+	}
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn != 900 || tokens.RefreshToken == "" {
+		t.Errorf("Expected a bearer token for 15 minutes and a refresh token, got %+v", tokens)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(repo.users[0].PasswordHash), []byte("correct horse")); err != nil {
+		t.Errorf("Expected the password to be stored as a bcrypt hash, got %q", repo.users[0].PasswordHash)
+	}
+	if len(repo.tokens) != 1 || repo.tokens[0].TokenHash == tokens.RefreshToken || !repo.tokens[0].ExpiresAt.Equal(now.Add(30*24*time.Hour)) {
+		t.Errorf("Expected the refresh token's hash stored for 30 days, got %+v", repo.tokens)
+	}
+
+	userID, err := service.Authenticate(tokens.AccessToken)
+	if err != nil || userID != 1 {
+		t.Errorf("Expected the access token to authenticate user 1, got %d, %v", userID, err)
+	}
+
+	_, err = service.Register(context.Background(), Credentials{Email: "ann@example.com", Password: "another password"})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for a registered email, got %v", err)
+	}
+}
+
+func TestRegister_InvalidParams(t *testing.T) {
+	now := time.Now()
+	service := newTestAuthService(&mockUsersRepository{}, &now)
+
+	tests := []struct {
+		name string
+		req  Credentials
+	}{
+		{"no email", Credentials{Password: "correct horse"}},
+		{"invalid email", Credentials{Email: "ann", Password: "correct horse"}},
+		{"display name", Credentials{Email: "Ann <ann@example.com>", Password: "correct horse"}},
+		{"host without dot", Credentials{Email: "ann@localhost", Password: "correct horse"}},
+		{"short password", Credentials{Email: "ann@example.com", Password: "short"}},
+		{"long password", Credentials{Email: "ann@example.com", Password: strings.Repeat("a", 73)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Register(context.Background(), tt.req)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	repo := &mockUsersRepository{}
+	now := time.Now()
+	service := newTestAuthService(repo, &now)
+
+	if _, err := service.Register(context.Background(), Credentials{Email: "ann@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tokens, err := service.Login(context.Background(), Credentials{Email: "ANN@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tokens.User.ID != 1 || repo.activeTokens() != 2 {
+		t.Errorf("Expected a second session for user 1, got %+v with %d active tokens", tokens.User, repo.activeTokens())
+	}
+
+	tests := []struct {
+		name string
+		req  Credentials
+		err  error
+	}{
+		{"wrong password", Credentials{Email: "ann@example.com", Password: "wrong horse"}, ErrInvalidCredentials},
+		{"unknown email", Credentials{Email: "bob@example.com", Password: "correct horse"}, ErrInvalidCredentials},
+		{"no password", Credentials{Email: "ann@example.com"}, ErrInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Login(context.Background(), tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_InvalidTokens(t *testing.T) {
+	repo := &mockUsersRepository{}
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	service := newTestAuthService(repo, &now)
+
+	tokens, err := service.Register(context.Background(), Credentials{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.RegisteredClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Expected to sign a token, got %v", err)
+		}
+		return token
+	}
+	valid := jwt.RegisteredClaims{
+		Issuer:    "masakyuk",
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+	badSubject := valid
+	badSubject.Subject = "ann"
+
+	tests := map[string]string{
+		"empty":          "",
+		"garbage":        "not.a.token",
+		"other secret":   sign(jwt.SigningMethodHS256, []byte("another secret of thirty-two byt"), valid),
+		"none algorithm": sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid),
+		"no expiry":      sign(jwt.SigningMethodHS256, testSecret, noExpiry),
+		"bad subject":    sign(jwt.SigningMethodHS256, testSecret, badSubject),
+	}
+	for name, token := range tests {
+		if _, err := service.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+
+	now = now.Add(16 * time.Minute)
+	if _, err := service.Authenticate(tokens.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected an expired access token to be rejected, got %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	repo := &mockUsersRepository{}
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	service := newTestAuthService(repo, &now)
+
+	first, err := service.Register(context.Background(), Credentials{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	second, err := service.Refresh(context.Background(), first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.User.ID != 1 {
+		t.Errorf("Expected a new refresh token for user 1, got %+v", second)
+	}
+	if userID, err := service.Authenticate(second.AccessToken); err != nil || userID != 1 {
+		t.Errorf("Expected the new access token to authenticate user 1, got %d, %v", userID, err)
+	}
+	if repo.activeTokens() != 1 {
+		t.Errorf("Expected the old refresh token to be revoked, got %d active tokens", repo.activeTokens())
+	}
+
+	// Reusing the first token revokes every session, including the second
+	if _, err := service.Refresh(context.Background(), first.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for a reused token, got %v", err)
+	}
+	if repo.activeTokens() != 0 {
+		t.Errorf("Expected every refresh token to be revoked, got %d active tokens", repo.activeTokens())
+	}
+	if _, err := service.Refresh(context.Background(), second.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken after reuse, got %v", err)
+	}
+
+	if _, err := service.Refresh(context.Background(), "unknown"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for an unknown token, got %v", err)
+	}
+	if _, err := service.Refresh(context.Background(), ""); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams without a token, got %v", err)
+	}
+}
+
+func TestRefresh_Expired(t *testing.T) {
+	repo := &mockUsersRepository{}
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	service := NewAuthService(repo, testSecret, WithTokenLifetimes(0, 24*time.Hour)).(*authService)
+	service.passwordCost = bcrypt.MinCost
+	service.now = func() time.Time { return now }
+
+	tokens, err := service.Register(context.Background(), Credentials{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tokens.ExpiresIn != 900 {
+		t.Errorf("Expected the default access token lifetime, got %d", tokens.ExpiresIn)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if _, err := service.Refresh(context.Background(), tokens.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for an expired token, got %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	repo := &mockUsersRepository{}
+	now := time.Now()
+	service := newTestAuthService(repo, &now)
+
+	tokens, err := service.Register(context.Background(), Credentials{Email: "ann@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := service.Logout(context.Background(), tokens.RefreshToken); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repo.activeTokens() != 0 {
+		t.Errorf("Expected the refresh token to be revoked, got %d active tokens", repo.activeTokens())
+	}
+	if _, err := service.Refresh(context.Background(), tokens.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken after logout, got %v", err)
+	}
+
+	if err := service.Logout(context.Background(), "unknown"); err != nil {
+		t.Errorf("Expected an unknown token to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+var ErrCategoryNotFound = errors.New("category not found")
+
+// Category represents a category in the response
+type Category struct {
+	ID          int32     `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	RecipeCount int64     `json:"recipe_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CategoryRequest holds data for creating or updating a category
+type CategoryRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// CategoriesService defines the interface for category business logic
+type CategoriesService interface {
+	ListCategories(ctx context.Context) ([]Category, error)
+	GetCategoryByID(ctx context.Context, id int32) (*Category, error)
+	CreateCategory(ctx context.Context, req CategoryRequest) (*Category, error)
+	UpdateCategory(ctx context.Context, id int32, req CategoryRequest) (*Category, error)
+	DeleteCategory(ctx context.Context, id int32) error
+}
+
+type categoriesService struct {
+	repo repository.CategoriesRepository
+}
+
+// NewCategoriesService creates a new categories service
+func NewCategoriesService(repo repository.CategoriesRepository) CategoriesService {
+	return &categoriesService{
+		repo: repo,
+	}
+}
+
+func (s *categoriesService) ListCategories(ctx context.Context) ([]Category, error) {
+	rows, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	categories := make([]Category, len(rows))
+	for i, row := range rows {
+		categories[i] = Category{
+			ID:          row.ID,
+			Name:        row.Name,
+			Description: nullStringToPtr(row.Description),
+			RecipeCount: row.RecipeCount,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		}
+	}
+
+	return categories, nil
+}
+
+func (s *categoriesService) GetCategoryByID(ctx context.Context, id int32) (*Category, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid category ID", ErrInvalidParams)
+	}
+
+	row, err := s.repo.GetCategoryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return &Category{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: nullStringToPtr(row.Description),
+		RecipeCount: row.RecipeCount,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
+}
+
+// validateCategoryRequest trims the name and checks it fits the column
+func validateCategoryRequest(req *CategoryRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidParams)
+	}
+	if len(req.Name) > 100 {
+		return fmt.Errorf("%w: name must be at most 100 characters", ErrInvalidParams)
+	}
+	return nil
+}
+
+func (s *categoriesService) CreateCategory(ctx context.Context, req CategoryRequest) (*Category, error) {
+	if err := validateCategoryRequest(&req); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreateCategory(ctx, repository.CategoryParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: category %q already exists", ErrConflict, req.Name)
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return s.GetCategoryByID(ctx, int32(id))
+}
+
+func (s *categoriesService) UpdateCategory(ctx context.Context, id int32, req CategoryRequest) (*Category, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid category ID", ErrInvalidParams)
+	}
+
+	if err := validateCategoryRequest(&req); err != nil {
+		return nil, err
+	}
+
+	// Check if category exists
+	if _, err := s.GetCategoryByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.UpdateCategory(ctx, id, repository.CategoryParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: category %q already exists", ErrConflict, req.Name)
+		}
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	return s.GetCategoryByID(ctx, id)
+}
+
+func (s *categoriesService) DeleteCategory(ctx context.Context, id int32) error {
+	existing, err := s.GetCategoryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Recipes reference categories with ON DELETE RESTRICT
+	if existing.RecipeCount > 0 {
+		return fmt.Errorf("%w: category is used by %d recipe(s)", ErrConflict, existing.RecipeCount)
+	}
+
+	if err := s.repo.DeleteCategory(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrReferenced) {
+			return fmt.Errorf("%w: category is still used by recipes", ErrConflict)
+		}
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Mock repository for testing
+type mockCategoriesRepository struct {
+	listCategoriesFunc  func(ctx context.Context) ([]db.ListCategoriesRow, error)
+	getCategoryByIDFunc func(ctx context.Context, id int32) (db.GetCategoryByIDRow, error)
+	createCategoryFunc  func(ctx context.Context, params repository.CategoryParams) (int64, error)
+	deleteCategoryFunc  func(ctx context.Context, id int32) error
+}
+
+func (m *mockCategoriesRepository) ListCategories(ctx context.Context) ([]db.ListCategoriesRow, error) {
+	if m.listCategoriesFunc != nil {
+		return m.listCategoriesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockCategoriesRepository) GetCategoryByID(ctx context.Context, id int32) (db.GetCategoryByIDRow, error) {
+	if m.getCategoryByIDFunc != nil {
+		return m.getCategoryByIDFunc(ctx, id)
+	}
+	return db.GetCategoryByIDRow{ID: id, Name: "Indonesian"}, nil
+}
+
+func (m *mockCategoriesRepository) CreateCategory(ctx context.Context, params repository.CategoryParams) (int64, error) {
+	if m.createCategoryFunc != nil {
+		return m.createCategoryFunc(ctx, params)
+	}
+	return 1, nil
+}
+
+func (m *mockCategoriesRepository) UpdateCategory(ctx context.Context, id int32, params repository.CategoryParams) error {
+	return nil
+}
+
+func (m *mockCategoriesRepository) DeleteCategory(ctx context.Context, id int32) error {
+	if m.deleteCategoryFunc != nil {
+		return m.deleteCategoryFunc(ctx, id)
+	}
+	return nil
+}
+
+func TestListCategories_RecipeCounts(t *testing.T) {
+	mockRepo := &mockCategoriesRepository{
+		listCategoriesFunc: func(ctx context.Context) ([]db.ListCategoriesRow, error) {
+			return []db.ListCategoriesRow{
+				{ID: 1, Name: "Indonesian", RecipeCount: 18},
+				{ID: 4, Name: "Dessert", RecipeCount: 0},
+			}, nil
+		},
+	}
+
+	service := NewCategoriesService(mockRepo)
+
+	categories, err := service.ListCategories(context.Background())
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(categories) != 2 || categories[0].RecipeCount != 18 {
+		t.Errorf("Expected recipe counts to be mapped, got %+v", categories)
+	}
+}
+
+func TestGetCategoryByID_NotFound(t *testing.T) {
+	mockRepo := &mockCategoriesRepository{
+		getCategoryByIDFunc: func(ctx context.Context, id int32) (db.GetCategoryByIDRow, error) {
+			return db.GetCategoryByIDRow{}, sql.ErrNoRows
+		},
+	}
+
+	service := NewCategoriesService(mockRepo)
+
+	_, err := service.GetCategoryByID(context.Background(), 99)
+
+	if !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("Expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestCreateCategory_Validation(t *testing.T) {
+	service := NewCategoriesService(&mockCategoriesRepository{})
+
+	_, err := service.CreateCategory(context.Background(), CategoryRequest{Name: "   "})
+
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+}
+
+func TestCreateCategory_Duplicate(t *testing.T) {
+	mockRepo := &mockCategoriesRepository{
+		createCategoryFunc: func(ctx context.Context, params repository.CategoryParams) (int64, error) {
+			return 0, repository.ErrDuplicate
+		},
+	}
+
+	service := NewCategoriesService(mockRepo)
+
+	_, err := service.CreateCategory(context.Background(), CategoryRequest{Name: "Indonesian"})
+
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestDeleteCategory_InUse(t *testing.T) {
+	deleted := false
+	mockRepo := &mockCategoriesRepository{
+		getCategoryByIDFunc: func(ctx context.Context, id int32) (db.GetCategoryByIDRow, error) {
+			return db.GetCategoryByIDRow{ID: id, Name: "Indonesian", RecipeCount: 3}, nil
+		},
+		deleteCategoryFunc: func(ctx context.Context, id int32) error {
+			deleted = true
+			return nil
+		},
+	}
+
+	service := NewCategoriesService(mockRepo)
+
+	err := service.DeleteCategory(context.Background(), 1)
+
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	if deleted {
+		t.Error("Expected category in use not to be deleted")
+	}
+}
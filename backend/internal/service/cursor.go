@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// listCursor is the decoded form of a next_cursor token. It records the
+// sort it was issued for so a cursor cannot be replayed under another order.
+type listCursor struct {
+	Field string `json:"f"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    int32  `json:"id"`
+}
+
+// encodeCursor returns the opaque token for the position after row
+func encodeCursor(sort repository.RecipeSort, row repository.RecipeRow) string {
+	c := listCursor{Field: string(sort.Field), Desc: sort.Desc, ID: row.ID}
+
+	switch v := sort.Value(row).(type) {
+	case time.Time:
+		c.Value = v.UTC().Format(time.RFC3339Nano)
+	case int32:
+		c.Value = strconv.FormatInt(int64(v), 10)
+	case int64:
+		c.Value = strconv.FormatInt(v, 10)
+	case string:
+		c.Value = v
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token issued by encodeCursor for the same sort
+func decodeCursor(token string, sort repository.RecipeSort) (*repository.RecipeCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidParams)
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID < 1 {
+		return nil, invalid
+	}
+	if c.Field != string(sort.Field) || c.Desc != sort.Desc {
+		return nil, fmt.Errorf("%w: cursor was issued for a different sort", ErrInvalidParams)
+	}
+
+	var value interface{}
+	switch sort.Field {
+	case repository.SortCookingTime, repository.SortCalories:
+		value, err = strconv.ParseInt(c.Value, 10, 32)
+	case repository.SortRelevance:
+		value, err = strconv.ParseInt(c.Value, 10, 64)
+	case repository.SortTitle:
+		value = c.Value
+	default:
+		value, err = time.Parse(time.RFC3339Nano, c.Value)
+	}
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &repository.RecipeCursor{Value: value, ID: c.ID}, nil
+}
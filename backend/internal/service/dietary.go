@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+)
+
+// Dietary check modes for CreateRecipe and UpdateRecipe
+const (
+	DietaryCheckStrict = "strict"
+	DietaryCheckWarn   = "warn"
+)
+
+// dietaryGroups are ingredient families some variants forbid that are not
+// allergens. Matching works as for allergenRules.
+var dietaryGroups = map[string]allergenRule{
+	"meat": {
+		keywords: []string{"chicken", "beef", "lamb", "mutton", "goat", "veal", "duck", "turkey", "venison", "meat", "meatball", "sausage", "oxtail", "tripe", "liver", "gizzard", "bone broth", "ayam", "daging", "sapi", "kambing", "bebek", "bakso"},
+		except:   []string{"goat cheese", "goat milk", "duck egg", "beef tomato", "beefsteak tomato"},
+	},
+	"pork": {
+		keywords: []string{"pork", "bacon", "ham", "lard", "lardon", "prosciutto", "pancetta", "chorizo", "salami", "pepperoni", "char siu", "babi"},
+	},
+	"alcohol": {
+		keywords: []string{"wine", "beer", "rum", "sake", "mirin", "brandy", "vodka", "whisky", "whiskey", "bourbon", "sherry", "liqueur", "shaoxing", "ang ciu", "arak", "tuak"},
+		except:   []string{"wine vinegar", "sherry vinegar"},
+	},
+	"gelatin": {
+		keywords: []string{"gelatin", "gelatine", "isinglass"},
+	},
+	"honey": {
+		keywords: []string{"honey", "royal jelly", "beeswax"},
+	},
+}
+
+// dietaryRules maps a variant name (lowercase) to what it forbids, checked
+// in order. Each entry names an allergenRules or dietaryGroups entry.
+// Variants without a rule, like Regular, accept any ingredient.
+var dietaryRules = map[string][]string{
+	"vegan":       {"meat", "pork", "fish", "shellfish", "dairy", "egg", "gelatin", "honey"},
+	"vegetarian":  {"meat", "pork", "fish", "shellfish", "gelatin"},
+	"halal":       {"pork", "alcohol"},
+	"gluten-free": {"gluten"},
+}
+
+// DietaryViolation is an ingredient a recipe's variant forbids
+type DietaryViolation struct {
+	Variant    string `json:"variant"`
+	Ingredient string `json:"ingredient"`
+	// Reason is the forbidden group the ingredient belongs to, e.g. "meat"
+	// or "shellfish"
+	Reason string `json:"reason"`
+}
+
+// DietaryAuditEntry is a saved recipe whose ingredients conflict with its
+// variants
+type DietaryAuditEntry struct {
+	RecipeID   int32              `json:"recipe_id"`
+	Title      string             `json:"title"`
+	Violations []DietaryViolation `json:"violations"`
+}
+
+// DietaryAuditResponse represents the response for a dietary audit
+type DietaryAuditResponse struct {
+	Data []DietaryAuditEntry `json:"data"`
+}
+
+// dietaryViolations checks ingredient names against each variant's rule.
+// An ingredient is reported once per variant, with the first group it
+// matches.
+func dietaryViolations(variants []RecipeVariant, ingredients []string) []DietaryViolation {
+	violations := []DietaryViolation{}
+	for _, variant := range variants {
+		forbidden := dietaryRules[strings.ToLower(variant.Name)]
+		if len(forbidden) == 0 {
+			continue
+		}
+		for _, ingredient := range ingredients {
+			name := foodName(ingredient)
+			for _, reason := range forbidden {
+				if forbiddenRule(reason).matches(name) {
+					violations = append(violations, DietaryViolation{
+						Variant:    variant.Name,
+						Ingredient: ingredient,
+						Reason:     reason,
+					})
+					break
+				}
+			}
+		}
+	}
+	return violations
+}
+
+func forbiddenRule(name string) allergenRule {
+	if rule, ok := allergenRules[name]; ok {
+		return rule
+	}
+	return dietaryGroups[name]
+}
+
+// checkDietary validates the ingredients of a recipe being saved against
+// its variants. In strict mode (the default) any violation rejects the
+// recipe; in warn mode the violations are returned instead.
+func (s *recipesService) checkDietary(ctx context.Context, mode string, variantIDs []int32, items []Ingredient) ([]DietaryViolation, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = DietaryCheckStrict
+	}
+	if mode != DietaryCheckStrict && mode != DietaryCheckWarn {
+		return nil, fmt.Errorf("%w: dietary_check must be %q or %q", ErrInvalidParams, DietaryCheckStrict, DietaryCheckWarn)
+	}
+
+	rows, err := s.repo.ListVariantNames(ctx, variantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+
+	violations := dietaryViolations(variantsFromRows(rows), names)
+	if len(violations) == 0 {
+		return nil, nil
+	}
+	if mode == DietaryCheckWarn {
+		return violations, nil
+	}
+
+	details := make([]string, len(violations))
+	for i, v := range violations {
+		details[i] = fmt.Sprintf("%s: %q (%s)", v.Variant, v.Ingredient, v.Reason)
+	}
+	return nil, fmt.Errorf("%w: ingredients conflict with the recipe's variants: %s", ErrInvalidParams, strings.Join(details, "; "))
+}
+
+// AuditDietary lists saved recipes whose structured ingredients conflict
+// with their variants, ordered by recipe ID. Recipes without structured
+// ingredients are not checked.
+func (s *recipesService) AuditDietary(ctx context.Context) (*DietaryAuditResponse, error) {
+	rows, err := s.repo.ListAllRecipeIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ingredients: %w", err)
+	}
+
+	ingredients := make(map[int32][]string)
+	var ids []int32
+	for _, row := range rows {
+		if _, ok := ingredients[row.RecipeID]; !ok {
+			ids = append(ids, row.RecipeID)
+		}
+		ingredients[row.RecipeID] = append(ingredients[row.RecipeID], row.Name)
+	}
+
+	variantRows, err := s.repo.ListRecipeVariants(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+
+	variants := make(map[int32][]RecipeVariant)
+	for _, row := range variantRows {
+		variants[row.RecipeID] = append(variants[row.RecipeID], RecipeVariant{ID: row.VariantID, Name: row.Name})
+	}
+
+	violations := make(map[int32][]DietaryViolation)
+	var flagged []int32
+	for _, id := range ids {
+		if found := dietaryViolations(variants[id], ingredients[id]); len(found) > 0 {
+			violations[id] = found
+			flagged = append(flagged, id)
+		}
+	}
+	sort.Slice(flagged, func(i, j int) bool { return flagged[i] < flagged[j] })
+
+	titles, err := s.recipeTitles(ctx, flagged)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]DietaryAuditEntry, len(flagged))
+	for i, id := range flagged {
+		entries[i] = DietaryAuditEntry{RecipeID: id, Title: titles[id], Violations: violations[id]}
+	}
+
+	return &DietaryAuditResponse{Data: entries}, nil
+}
+
+// recipeTitles loads the titles of the given recipes
+func (s *recipesService) recipeTitles(ctx context.Context, ids []int32) (map[int32]string, error) {
+	rows, err := s.repo.ListRecipesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recipes: %w", err)
+	}
+
+	titles := make(map[int32]string, len(rows))
+	for _, row := range rows {
+		titles[row.ID] = row.Title
+	}
+	return titles, nil
+}
+
+// variantsFromRows converts variant name rows in the order given
+func variantsFromRows(rows []db.ListVariantNamesRow) []RecipeVariant {
+	variants := make([]RecipeVariant, len(rows))
+	for i, row := range rows {
+		variants[i] = RecipeVariant{ID: row.ID, Name: row.Name}
+	}
+	return variants
+}
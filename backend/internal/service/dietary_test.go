@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+func TestDietaryViolations(t *testing.T) {
+	tests := []struct {
+		variant     string
+		ingredients []string
+		expected    []string // reasons, in ingredient order
+	}{
+		{"Vegan", []string{"tofu", "eggs", "shrimp paste", "coconut milk"}, []string{"egg", "shellfish"}},
+		{"Vegan", []string{"honey", "butter"}, []string{"honey", "dairy"}},
+		{"Vegetarian", []string{"eggs", "goat cheese", "fish sauce"}, []string{"fish"}},
+		{"Vegetarian", []string{"chickpeas", "eggplant", "chicken stock"}, []string{"meat"}},
+		{"Halal", []string{"bacon", "rice wine vinegar", "Shaoxing wine"}, []string{"pork", "alcohol"}},
+		{"Gluten-Free", []string{"rice noodles", "sweet soy sauce"}, []string{"gluten"}},
+		{"Regular", []string{"pork belly", "beer"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.variant, func(t *testing.T) {
+			violations := dietaryViolations([]RecipeVariant{{ID: 1, Name: tt.variant}}, tt.ingredients)
+
+			var reasons []string
+			for _, v := range violations {
+				if v.Variant != tt.variant {
+					t.Errorf("Expected variant %s, got %s", tt.variant, v.Variant)
+				}
+				reasons = append(reasons, v.Reason)
+			}
+			if !reflect.DeepEqual(reasons, tt.expected) {
+				t.Errorf("Ingredients %v: expected %v, got %+v", tt.ingredients, tt.expected, violations)
+			}
+		})
+	}
+}
+
+func TestCreateRecipe_DietaryCheck(t *testing.T) {
+	newRepo := func(created *bool) *mockRecipesRepository {
+		return &mockRecipesRepository{
+			variantNames: []db.ListVariantNamesRow{{ID: 3, Name: "Vegan"}},
+			createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+				*created = true
+				return 7, nil
+			},
+		}
+	}
+
+	req := validCreateRequest()
+	req.VariantIDs = []int32{3}
+	req.IngredientItems = []Ingredient{{Name: "tofu"}, {Name: "eggs"}}
+
+	t.Run("strict rejects", func(t *testing.T) {
+		var created bool
+		service := NewRecipesService(newRepo(&created))
+
+		_, err := service.CreateRecipe(context.Background(), req)
+		if !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("Expected ErrInvalidParams, got %v", err)
+		}
+		if created {
+			t.Error("Expected recipe not to be saved")
+		}
+	})
+
+	t.Run("warn saves", func(t *testing.T) {
+		var created bool
+		service := NewRecipesService(newRepo(&created))
+
+		warnReq := req
+		warnReq.DietaryCheck = "warn"
+
+		recipe, err := service.CreateRecipe(context.Background(), warnReq)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := []DietaryViolation{{Variant: "Vegan", Ingredient: "eggs", Reason: "egg"}}
+		if !created || !reflect.DeepEqual(recipe.DietaryWarnings, expected) {
+			t.Errorf("Expected saved recipe with warnings %v, got %v", expected, recipe.DietaryWarnings)
+		}
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		var created bool
+		service := NewRecipesService(newRepo(&created))
+
+		badReq := req
+		badReq.DietaryCheck = "lenient"
+
+		if _, err := service.CreateRecipe(context.Background(), badReq); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("Expected ErrInvalidParams, got %v", err)
+		}
+	})
+}
+
+func TestAuditDietary(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		listAllIngredients: []db.RecipeIngredient{
+			{RecipeID: 5, Name: "tempeh"},
+			{RecipeID: 5, Name: "shrimp paste"},
+			{RecipeID: 2, Name: "tofu"},
+			{RecipeID: 2, Name: "kecap manis"},
+			{RecipeID: 9, Name: "rice"},
+		},
+		listVariantsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeVariantsRow, error) {
+			return []db.ListRecipeVariantsRow{
+				{RecipeID: 2, VariantID: 5, Name: "Gluten-Free"},
+				{RecipeID: 5, VariantID: 3, Name: "Vegan"},
+				{RecipeID: 9, VariantID: 3, Name: "Vegan"},
+			}, nil
+		},
+		listByIDsFunc: func(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error) {
+			return []repository.RecipeRow{{ID: 2, Title: "Tahu Bacem"}, {ID: 5, Title: "Tempe Penyet"}}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	result, err := service.AuditDietary(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []DietaryAuditEntry{
+		{RecipeID: 2, Title: "Tahu Bacem", Violations: []DietaryViolation{{Variant: "Gluten-Free", Ingredient: "kecap manis", Reason: "gluten"}}},
+		{RecipeID: 5, Title: "Tempe Penyet", Violations: []DietaryViolation{{Variant: "Vegan", Ingredient: "shrimp paste", Reason: "shellfish"}}},
+	}
+	if !reflect.DeepEqual(result.Data, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Data)
+	}
+}
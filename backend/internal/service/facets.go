@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// FacetCount is the number of recipes matching the current filters that
+// have one facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// facetOwnFilter clears the filter a facet drives, so its counts show what
+// choosing another value would return
+var facetOwnFilter = map[string]func(f *repository.RecipeFilter){
+	"category":    func(f *repository.RecipeFilter) { f.CategoryIDs = nil },
+	"variant":     func(f *repository.RecipeFilter) { f.VariantIDs = nil },
+	"skill_level": func(f *repository.RecipeFilter) { f.SkillLevels = nil },
+	"cooking_time": func(f *repository.RecipeFilter) {
+		f.MinCookingTime, f.MaxCookingTime = nil, nil
+	},
+}
+
+// normalizeFacets lowercases and de-duplicates requested facets, rejecting
+// unknown names
+func normalizeFacets(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := facetOwnFilter[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown facet %q (valid: %s)", ErrInvalidParams, name, strings.Join(facetNames(), ", "))
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized, nil
+}
+
+func facetNames() []string {
+	names := make([]string, 0, len(facetOwnFilter))
+	for name := range facetOwnFilter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// countFacets counts each facet under filter with that facet's own filter
+// removed
+func (s *recipesService) countFacets(ctx context.Context, names []string, filter repository.RecipeFilter) (map[string][]FacetCount, error) {
+	result := make(map[string][]FacetCount, len(names))
+	for _, name := range names {
+		facetFilter := filter
+		facetOwnFilter[name](&facetFilter)
+
+		rows, err := s.repo.CountRecipeFacet(ctx, repository.Facet(name), facetFilter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", name, err)
+		}
+
+		counts := make([]FacetCount, len(rows))
+		for i, row := range rows {
+			counts[i] = FacetCount{Value: row.Value, Label: row.Label, Count: row.Count}
+		}
+		result[name] = counts
+	}
+	return result, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ingredient is one structured line of a recipe's ingredient list
+type Ingredient struct {
+	Quantity *float64 `json:"quantity,omitempty"`
+	Unit     *string  `json:"unit,omitempty"`
+	Name     string   `json:"name"`
+	Note     *string  `json:"note,omitempty"`
+	Group    *string  `json:"group,omitempty"`
+}
+
+// knownUnits are words that, right after a quantity, are read as the unit
+// rather than as part of the ingredient name
+var knownUnits = map[string]bool{
+	"g": true, "kg": true, "mg": true, "ml": true, "l": true,
+	"liter": true, "liters": true, "litre": true, "litres": true,
+	"cup": true, "cups": true, "tbsp": true, "tsp": true,
+	"oz": true, "lb": true, "lbs": true,
+	"clove": true, "cloves": true, "stalk": true, "stalks": true,
+	"cm": true, "slice": true, "slices": true, "piece": true, "pieces": true,
+	"pinch": true, "can": true, "cans": true, "bunch": true, "bunches": true,
+	"sheet": true, "sheets": true, "whole": true,
+	"gram": true, "grams": true, "kilogram": true, "kilograms": true,
+	"ounce": true, "ounces": true, "pound": true, "pounds": true,
+	"teaspoon": true, "teaspoons": true, "tablespoon": true, "tablespoons": true,
+	"milliliter": true, "milliliters": true, "millilitre": true, "millilitres": true,
+}
+
+// compactUnits are rendered without a space after the quantity (300g)
+var compactUnits = map[string]bool{
+	"g": true, "kg": true, "mg": true, "ml": true, "l": true,
+}
+
+var (
+	// quantity at the start of a line: "1 1/2", "1/2", "2", "0.5"
+	quantityPattern = regexp.MustCompile(`^(\d+\s+\d+/\d+|\d+/\d+|\d+(?:\.\d+)?)\s*`)
+	// trailing usage notes: "to taste", "for frying", "for garnish"
+	usageNotePattern = regexp.MustCompile(`(?i)\s+(to taste|for [a-z]+)$`)
+	// "Pinch of salt"
+	pinchPattern = regexp.MustCompile(`(?i)^(pinch)\s+of\s+`)
+)
+
+// ParseIngredients converts a free-text, comma-separated ingredient list
+// such as "2 cups cooked rice (day-old), For sambal: 10 red chilies" into
+// structured ingredients. Commas inside parentheses do not split items and
+// a "Label:" prefix starts a group that applies to the following items.
+func ParseIngredients(text string) []Ingredient {
+	items := []Ingredient{}
+	var group *string
+
+	for _, part := range splitTopLevel(text, ',') {
+		part = strings.TrimSpace(part)
+		if label, rest, ok := cutGroupLabel(part); ok {
+			group = &label
+			part = rest
+		}
+		if part == "" {
+			continue
+		}
+
+		item := parseIngredientLine(part)
+		item.Group = group
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// parseIngredientLine parses a single item without group handling
+func parseIngredientLine(line string) Ingredient {
+	var notes []string
+
+	// Parenthesised preparation notes: "(minced)", "(boiled, cubed)"
+	for {
+		open := strings.Index(line, "(")
+		if open < 0 {
+			break
+		}
+		end := matchingParen(line, open)
+		if end < 0 {
+			break
+		}
+		notes = append(notes, strings.TrimSpace(line[open+1:end]))
+		line = strings.TrimSpace(line[:open] + " " + line[end+1:])
+	}
+	line = strings.Join(strings.Fields(line), " ")
+
+	// Trailing usage notes: "Salt and pepper to taste", "Oil for frying"
+	if m := usageNotePattern.FindStringSubmatchIndex(line); m != nil {
+		notes = append(notes, strings.ToLower(line[m[2]:m[3]]))
+		line = strings.TrimSpace(line[:m[0]])
+	}
+
+	item := Ingredient{}
+
+	if m := pinchPattern.FindStringSubmatch(line); m != nil {
+		one := 1.0
+		unit := strings.ToLower(m[1])
+		item.Quantity = &one
+		item.Unit = &unit
+		line = line[len(m[0]):]
+	} else if m := quantityPattern.FindStringSubmatch(line); m != nil {
+		if qty, ok := parseQuantity(m[1]); ok {
+			item.Quantity = &qty
+			line = line[len(m[0]):]
+
+			if word, rest, _ := strings.Cut(line, " "); knownUnits[strings.ToLower(word)] && rest != "" {
+				unit := NormalizeUnit(word)
+				item.Unit = &unit
+				line = rest
+			}
+		}
+	}
+
+	item.Name = strings.TrimSpace(line)
+	if len(notes) > 0 {
+		note := strings.Join(notes, ", ")
+		item.Note = &note
+	}
+
+	return item
+}
+
+// parseQuantity reads whole numbers, decimals, fractions and mixed numbers
+func parseQuantity(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+
+	if whole, frac, ok := strings.Cut(s, " "); ok {
+		w, err := strconv.ParseFloat(whole, 64)
+		if err != nil {
+			return 0, false
+		}
+		f, ok := parseQuantity(frac)
+		return w + f, ok
+	}
+
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// splitTopLevel splits on sep, ignoring separators inside parentheses
+func splitTopLevel(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + len(string(r))
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// cutGroupLabel splits "For spice paste: 10 shallots" into its label and item
+func cutGroupLabel(part string) (label, rest string, ok bool) {
+	idx := strings.Index(part, ":")
+	if idx <= 0 || strings.Contains(part[:idx], "(") {
+		return "", part, false
+	}
+	label = strings.TrimSpace(part[:idx])
+	if label == "" || quantityPattern.MatchString(label) {
+		return "", part, false
+	}
+	return label, strings.TrimSpace(part[idx+1:]), true
+}
+
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// FormatQuantity renders a quantity using common kitchen fractions
+// (0.5 → "1/2", 1.25 → "1 1/4") and at most two decimals otherwise
+func FormatQuantity(q float64) string {
+	fractions := []struct {
+		value float64
+		text  string
+	}{
+		{1.0 / 8, "1/8"}, {1.0 / 4, "1/4"}, {1.0 / 3, "1/3"}, {1.0 / 2, "1/2"},
+		{2.0 / 3, "2/3"}, {3.0 / 4, "3/4"},
+	}
+
+	whole, frac := math.Modf(q)
+	if frac < 0.01 {
+		return strconv.FormatFloat(whole, 'f', -1, 64)
+	}
+	if frac > 0.99 {
+		return strconv.FormatFloat(whole+1, 'f', -1, 64)
+	}
+	for _, f := range fractions {
+		if math.Abs(frac-f.value) < 0.01 {
+			if whole == 0 {
+				return f.text
+			}
+			return fmt.Sprintf("%d %s", int(whole), f.text)
+		}
+	}
+
+	return strconv.FormatFloat(math.Round(q*100)/100, 'f', -1, 64)
+}
+
+// FormatIngredient renders one ingredient back into a readable line
+func FormatIngredient(item Ingredient) string {
+	var b strings.Builder
+
+	if item.Quantity != nil {
+		b.WriteString(FormatQuantity(*item.Quantity))
+		if item.Unit != nil {
+			unit := NormalizeUnit(*item.Unit)
+			if !compactUnits[unit] {
+				b.WriteString(" ")
+			}
+			b.WriteString(displayUnit(unit, *item.Quantity))
+		}
+		b.WriteString(" ")
+	}
+
+	b.WriteString(item.Name)
+
+	if item.Note != nil {
+		note := *item.Note
+		if strings.HasPrefix(note, "to ") || strings.HasPrefix(note, "for ") {
+			b.WriteString(" " + note)
+		} else {
+			b.WriteString(" (" + note + ")")
+		}
+	}
+
+	return b.String()
+}
+
+// FormatIngredients renders a structured list as the comma-separated text
+// stored in recipes.ingredients, writing a group's label before its first
+// item as "Label: item". A label stays in effect until the next one, so
+// ParseIngredients only reads the text back unchanged when ungrouped items
+// come before every group.
+func FormatIngredients(items []Ingredient) string {
+	parts := make([]string, len(items))
+	var group *string
+	for i, item := range items {
+		line := FormatIngredient(item)
+		if item.Group != nil && (group == nil || *group != *item.Group) {
+			line = *item.Group + ": " + line
+		}
+		group = item.Group
+		parts[i] = line
+	}
+	return strings.Join(parts, ", ")
+}
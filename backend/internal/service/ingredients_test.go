@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParseIngredients(t *testing.T) {
+	text := "2 cups cooked rice (day-old), 1 cup mixed vegetables (carrots, peas, cabbage), " +
+		"300g egg noodles, 1/2 tsp turmeric powder, Salt and pepper to taste, " +
+		"For peanut sauce: 200g roasted peanuts, Pinch of salt, Oil for frying"
+
+	items := ParseIngredients(text)
+
+	if len(items) != 8 {
+		t.Fatalf("Expected 8 ingredients, got %d: %+v", len(items), items)
+	}
+
+	tests := []struct {
+		index    int
+		quantity *float64
+		unit     *string
+		name     string
+		note     *string
+		group    *string
+	}{
+		{0, floatPtr(2), strPtr("cup"), "cooked rice", strPtr("day-old"), nil},
+		{1, floatPtr(1), strPtr("cup"), "mixed vegetables", strPtr("carrots, peas, cabbage"), nil},
+		{2, floatPtr(300), strPtr("g"), "egg noodles", nil, nil},
+		{3, floatPtr(0.5), strPtr("tsp"), "turmeric powder", nil, nil},
+		{4, nil, nil, "Salt and pepper", strPtr("to taste"), nil},
+		{5, floatPtr(200), strPtr("g"), "roasted peanuts", nil, strPtr("For peanut sauce")},
+		{6, floatPtr(1), strPtr("pinch"), "salt", nil, strPtr("For peanut sauce")},
+		{7, nil, nil, "Oil", strPtr("for frying"), strPtr("For peanut sauce")},
+	}
+
+	for _, tt := range tests {
+		got := items[tt.index]
+		if got.Name != tt.name {
+			t.Errorf("item %d: expected name %q, got %q", tt.index, tt.name, got.Name)
+		}
+		if !equalFloatPtr(got.Quantity, tt.quantity) {
+			t.Errorf("item %d: expected quantity %v, got %v", tt.index, deref(tt.quantity), deref(got.Quantity))
+		}
+		if !equalStrPtr(got.Unit, tt.unit) {
+			t.Errorf("item %d: expected unit %v, got %v", tt.index, tt.unit, got.Unit)
+		}
+		if !equalStrPtr(got.Note, tt.note) {
+			t.Errorf("item %d: expected note %v, got %v", tt.index, tt.note, got.Note)
+		}
+		if !equalStrPtr(got.Group, tt.group) {
+			t.Errorf("item %d: expected group %v, got %v", tt.index, tt.group, got.Group)
+		}
+	}
+}
+
+func TestFormatIngredients_RoundTrip(t *testing.T) {
+	text := "2 cups cooked rice (day-old), 300g egg noodles, 1 1/2 tsp salt, " +
+		"Salt and pepper to taste, For spice paste: 10 shallots, 3 cm ginger"
+
+	if got := FormatIngredients(ParseIngredients(text)); got != text {
+		t.Errorf("Expected round trip to preserve text\n got: %s\nwant: %s", got, text)
+	}
+}
+
+func TestFormatIngredients_ParseRoundTrip(t *testing.T) {
+	sambal := "For sambal"
+	sauce := "For sauce"
+	qty := func(v float64) *float64 { return &v }
+	items := []Ingredient{
+		{Quantity: qty(2), Unit: strPtr("cup"), Name: "cooked rice"},
+		{Name: "salt", Note: strPtr("to taste")},
+		{Quantity: qty(10), Name: "red chilies", Group: &sambal},
+		{Quantity: qty(3), Name: "shallots", Group: &sambal},
+		{Quantity: qty(2), Unit: strPtr("tbsp"), Name: "sweet soy sauce", Group: &sauce},
+	}
+
+	got := ParseIngredients(FormatIngredients(items))
+	if len(got) != len(items) {
+		t.Fatalf("Expected %d items, got %d", len(items), len(got))
+	}
+	for i := range items {
+		if got[i].Name != items[i].Name || !equalStrPtr(got[i].Group, items[i].Group) {
+			t.Errorf("item %d: expected %s in group %v, got %s in group %v", i, items[i].Name, items[i].Group, got[i].Name, got[i].Group)
+		}
+	}
+}
+
+func TestNormalizeIngredients_UngroupedAfterGroup(t *testing.T) {
+	sambal := "For sambal"
+	items := []Ingredient{
+		{Name: "red chilies", Group: &sambal},
+		{Name: "cooked rice"},
+	}
+
+	if _, _, err := normalizeIngredients("", items); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+
+	blank := " "
+	items = []Ingredient{{Name: "cooked rice", Group: &blank}, {Name: "red chilies", Group: &sambal}}
+	text, cleaned, err := normalizeIngredients("", items)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cleaned[0].Group != nil || text != "cooked rice, For sambal: red chilies" {
+		t.Errorf("Expected a blank group to be dropped, got %q", text)
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	tests := map[float64]string{
+		2:     "2",
+		0.5:   "1/2",
+		1.25:  "1 1/4",
+		0.333: "1/3",
+		1.8:   "1.8",
+	}
+
+	for in, want := range tests {
+		if got := FormatQuantity(in); got != want {
+			t.Errorf("FormatQuantity(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+func deref(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalStrPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Meal plan limits
+const (
+	defaultPlanDays     = 7
+	maxPlanDays         = 14
+	maxPlanMeals        = 4
+	maxMealNameLength   = 20
+	maxDailyCookingTime = 1440
+	defaultPlanMeal     = "dinner"
+)
+
+// MealPlanRequest holds the constraints for generating a meal plan
+type MealPlanRequest struct {
+	// Days is the number of days to plan, 1-14 (default 7)
+	Days int
+	// Meals names the slots of each day, in order (default dinner)
+	Meals []string
+	// MaxDailyCookingTime caps the total cooking time of a day; zero means
+	// no cap
+	MaxDailyCookingTime int32
+	// Filters apply to every slot. Only the filters, Weights and Seed are
+	// used; spin history is not.
+	Filters RecipeFilters
+	// DayFilters narrow Filters on some days, keyed by day number from 1
+	DayFilters map[int]RecipeFilters
+}
+
+// MealPlanRerollRequest re-rolls one slot of a plan under the constraints
+// it was generated with. Days and Meals come from the plan.
+type MealPlanRerollRequest struct {
+	MealPlanRequest
+	Plan MealPlan
+	Day  int
+	Meal string
+}
+
+// MealPlan is a spun plan of Days × Meals recipes, all different
+type MealPlan struct {
+	Meals []string      `json:"meals"`
+	Days  []MealPlanDay `json:"days"`
+	// Seed replays the draw that produced the plan: the generation, or
+	// the last re-roll when sent with the same plan
+	Seed int64 `json:"seed"`
+}
+
+// MealPlanDay is one day of a meal plan
+type MealPlanDay struct {
+	Day         int            `json:"day"`
+	Slots       []MealPlanSlot `json:"slots"`
+	CookingTime int32          `json:"cooking_time"`
+}
+
+// MealPlanSlot is one meal of a day
+type MealPlanSlot struct {
+	Meal     string  `json:"meal"`
+	RecipeID int32   `json:"recipe_id"`
+	Recipe   *Recipe `json:"recipe,omitempty"`
+	// CategoryRepeat is set when the recipe shares its category with the
+	// same meal on an adjacent day because nothing else fit
+	CategoryRepeat bool `json:"category_repeat,omitempty"`
+}
+
+// mealPlanner draws slots for a plan grid of days × meals
+type mealPlanner struct {
+	pools    [][]repository.SpinCandidate
+	maxDaily int32
+	weights  *SpinWeights
+	// grid holds the chosen recipe of each slot; ID 0 is an empty slot
+	grid [][]repository.SpinCandidate
+	used map[int32]bool
+}
+
+// GenerateMealPlan spins a recipe for every meal of every day. Slots are
+// filled day by day; a slot draws from the recipes matching its day's
+// filters that are not used elsewhere in the plan and fit the day's
+// remaining cooking time, preferring categories the same meal does not
+// have on adjacent days.
+func (s *recipesService) GenerateMealPlan(ctx context.Context, req MealPlanRequest) (*MealPlan, error) {
+	if err := validateMealPlan(&req); err != nil {
+		return nil, err
+	}
+
+	seed, err := s.resolveSpinSeed(req.Filters.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	planner, err := s.newMealPlanner(ctx, req, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	repeats := make([][]bool, req.Days)
+	for day := range planner.grid {
+		repeats[day] = make([]bool, len(req.Meals))
+		for meal := range req.Meals {
+			chosen, repeat, err := planner.choose(day, meal, rng.Float64())
+			if err != nil {
+				return nil, fmt.Errorf("%w: not enough recipes for %s on day %d", err, req.Meals[meal], day+1)
+			}
+			planner.place(day, meal, chosen)
+			repeats[day][meal] = repeat
+		}
+	}
+
+	return s.buildMealPlan(ctx, req.Meals, planner.grid, repeats, seed)
+}
+
+// RerollMealPlanSlot replaces the recipe in one slot of a plan, keeping the
+// other slots as they are
+func (s *recipesService) RerollMealPlanSlot(ctx context.Context, req MealPlanRerollRequest) (*MealPlan, error) {
+	req.Days = len(req.Plan.Days)
+	req.Meals = req.Plan.Meals
+	if req.Days == 0 {
+		return nil, fmt.Errorf("%w: plan has no days", ErrInvalidParams)
+	}
+	if err := validateMealPlan(&req.MealPlanRequest); err != nil {
+		return nil, err
+	}
+
+	day := req.Day - 1
+	if day < 0 || day >= req.Days {
+		return nil, fmt.Errorf("%w: day must be between 1 and %d", ErrInvalidParams, req.Days)
+	}
+	meal := -1
+	for i, name := range req.Meals {
+		if name == strings.ToLower(strings.TrimSpace(req.Meal)) {
+			meal = i
+		}
+	}
+	if meal < 0 {
+		return nil, fmt.Errorf("%w: the plan has no meal %q", ErrInvalidParams, req.Meal)
+	}
+
+	seed, err := s.resolveSpinSeed(req.Filters.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	planner, err := s.newMealPlanner(ctx, req.MealPlanRequest, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	current, repeats, err := s.loadMealPlanGrid(ctx, req.Plan)
+	if err != nil {
+		return nil, err
+	}
+	for d := range current {
+		for m, c := range current[d] {
+			planner.place(d, m, c)
+		}
+	}
+
+	// The recipe being replaced stays used, so the re-roll changes it
+	planner.grid[day][meal] = repository.SpinCandidate{}
+	chosen, repeat, err := planner.choose(day, meal, rand.New(rand.NewSource(seed)).Float64())
+	if err != nil {
+		return nil, fmt.Errorf("%w: no other recipe fits %s on day %d", err, req.Meals[meal], req.Day)
+	}
+	planner.place(day, meal, chosen)
+	repeats[day][meal] = repeat
+
+	return s.buildMealPlan(ctx, req.Meals, planner.grid, repeats, seed)
+}
+
+// validateMealPlan checks the plan shape and filters, filling in defaults
+func validateMealPlan(req *MealPlanRequest) error {
+	if req.Days == 0 {
+		req.Days = defaultPlanDays
+	}
+	if req.Days < 1 || req.Days > maxPlanDays {
+		return fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidParams, maxPlanDays)
+	}
+
+	meals := make([]string, 0, len(req.Meals))
+	seen := make(map[string]bool, len(req.Meals))
+	for _, meal := range req.Meals {
+		meal, err := normalizeMealName(meal)
+		if err != nil {
+			return err
+		}
+		if seen[meal] {
+			return fmt.Errorf("%w: meal %q is listed twice", ErrInvalidParams, meal)
+		}
+		seen[meal] = true
+		meals = append(meals, meal)
+	}
+	if len(meals) == 0 {
+		meals = []string{defaultPlanMeal}
+	}
+	if len(meals) > maxPlanMeals {
+		return fmt.Errorf("%w: at most %d meals per day", ErrInvalidParams, maxPlanMeals)
+	}
+	req.Meals = meals
+
+	if req.MaxDailyCookingTime < 0 || req.MaxDailyCookingTime > maxDailyCookingTime {
+		return fmt.Errorf("%w: max_daily_cooking_time must be between 0 and %d", ErrInvalidParams, maxDailyCookingTime)
+	}
+
+	if err := validateFilters(&req.Filters); err != nil {
+		return err
+	}
+	if err := validateSpinWeights(&req.Filters); err != nil {
+		return err
+	}
+
+	dayFilters := make(map[int]RecipeFilters, len(req.DayFilters))
+	for day, filters := range req.DayFilters {
+		if day < 1 || day > req.Days {
+			return fmt.Errorf("%w: day_filters keys must be days between 1 and %d", ErrInvalidParams, req.Days)
+		}
+		if err := validateFilters(&filters); err != nil {
+			return fmt.Errorf("day %d: %w", day, err)
+		}
+		dayFilters[day] = filters
+	}
+	req.DayFilters = dayFilters
+	return nil
+}
+
+// normalizeMealName lowercases and checks a meal name such as "Lunch"
+func normalizeMealName(meal string) (string, error) {
+	meal = strings.ToLower(strings.TrimSpace(meal))
+	if meal == "" {
+		return "", fmt.Errorf("%w: meal names must not be empty", ErrInvalidParams)
+	}
+	if len(meal) > maxMealNameLength {
+		return "", fmt.Errorf("%w: meal %q must be at most %d characters", ErrInvalidParams, meal, maxMealNameLength)
+	}
+	return meal, nil
+}
+
+// newMealPlanner loads the candidate pool of each day: the recipes matching
+// the plan's filters, intersected with the day's own filters. Large pools
+// are sampled by seed.
+func (s *recipesService) newMealPlanner(ctx context.Context, req MealPlanRequest, seed int64) (*mealPlanner, error) {
+	filter, err := candidateFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.candidatePool(ctx, repository.SpinCandidatesParams{RecipeFilter: filter, Seed: seed})
+	if err != nil {
+		return nil, err
+	}
+	baseIDs := make([]int32, len(base))
+	for i, c := range base {
+		baseIDs[i] = c.ID
+	}
+
+	p := &mealPlanner{
+		pools:    make([][]repository.SpinCandidate, req.Days),
+		maxDaily: req.MaxDailyCookingTime,
+		weights:  req.Filters.Weights,
+		grid:     make([][]repository.SpinCandidate, req.Days),
+		used:     make(map[int32]bool),
+	}
+	for day := range p.pools {
+		p.pools[day] = base
+		p.grid[day] = make([]repository.SpinCandidate, len(req.Meals))
+	}
+
+	for day, filters := range req.DayFilters {
+		filter, err := candidateFilter(filters)
+		if err != nil {
+			return nil, fmt.Errorf("day %d: %w", day, err)
+		}
+		filter.RecipeIDs = baseIDs
+		matches, err := s.candidatePool(ctx, repository.SpinCandidatesParams{RecipeFilter: filter, Seed: seed})
+		if err != nil {
+			return nil, fmt.Errorf("day %d: %w", day, err)
+		}
+		matching := make(map[int32]bool, len(matches))
+		for _, c := range matches {
+			matching[c.ID] = true
+		}
+
+		pool := make([]repository.SpinCandidate, 0, len(matches))
+		for _, c := range base {
+			if matching[c.ID] {
+				pool = append(pool, c)
+			}
+		}
+		p.pools[day-1] = pool
+	}
+	return p, nil
+}
+
+// place puts a recipe in a slot
+func (p *mealPlanner) place(day, meal int, c repository.SpinCandidate) {
+	p.grid[day][meal] = c
+	p.used[c.ID] = true
+}
+
+// choose draws the recipe for an empty slot with the uniform value r. It
+// returns ErrRecipeNotFound when no unused recipe fits the day's cooking
+// time.
+func (p *mealPlanner) choose(day, meal int, r float64) (repository.SpinCandidate, bool, error) {
+	var unused []repository.SpinCandidate
+	for _, c := range p.pools[day] {
+		if !p.used[c.ID] {
+			unused = append(unused, c)
+		}
+	}
+
+	fitting := unused
+	if p.maxDaily > 0 {
+		// Leave room for the day's other empty slots at the shortest
+		// cooking time on offer
+		remaining := p.maxDaily
+		var shortest int32
+		for i, c := range unused {
+			if i == 0 || c.CookingTime < shortest {
+				shortest = c.CookingTime
+			}
+		}
+		for m, c := range p.grid[day] {
+			if m == meal {
+				continue
+			}
+			if c.ID != 0 {
+				remaining -= c.CookingTime
+			} else {
+				remaining -= shortest
+			}
+		}
+
+		fitting = nil
+		for _, c := range unused {
+			if c.CookingTime <= remaining {
+				fitting = append(fitting, c)
+			}
+		}
+	}
+	if len(fitting) == 0 {
+		return repository.SpinCandidate{}, false, ErrRecipeNotFound
+	}
+
+	// Avoid the categories of the same meal on the days either side
+	adjacent := make(map[int32]bool, 2)
+	for _, d := range []int{day - 1, day + 1} {
+		if d >= 0 && d < len(p.grid) && p.grid[d][meal].ID != 0 {
+			adjacent[p.grid[d][meal].CategoryID] = true
+		}
+	}
+	var varied []repository.SpinCandidate
+	for _, c := range fitting {
+		if !adjacent[c.CategoryID] {
+			varied = append(varied, c)
+		}
+	}
+
+	pool, repeat := varied, false
+	if len(varied) == 0 {
+		pool, repeat = fitting, true
+	}
+
+	chosen, _ := drawWeighted(spinWeights(pool, p.weights, nil), r)
+	return pool[chosen], repeat, nil
+}
+
+// loadMealPlanGrid looks up the cooking time and category of every recipe
+// in a plan, checking the plan has one recipe per day and meal
+func (s *recipesService) loadMealPlanGrid(ctx context.Context, plan MealPlan) ([][]repository.SpinCandidate, [][]bool, error) {
+	var ids []int32
+	for i, day := range plan.Days {
+		if len(day.Slots) != len(plan.Meals) {
+			return nil, nil, fmt.Errorf("%w: day %d must have one slot per meal", ErrInvalidParams, i+1)
+		}
+		for _, slot := range day.Slots {
+			ids = append(ids, slot.RecipeID)
+		}
+	}
+
+	rows, err := s.repo.ListRecipesByIDs(ctx, uniqueIDs(ids))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load plan recipes: %w", err)
+	}
+	found := make(map[int32]repository.RecipeRow, len(rows))
+	for _, row := range rows {
+		found[row.ID] = row
+	}
+
+	grid := make([][]repository.SpinCandidate, len(plan.Days))
+	repeats := make([][]bool, len(plan.Days))
+	for d, day := range plan.Days {
+		grid[d] = make([]repository.SpinCandidate, len(day.Slots))
+		repeats[d] = make([]bool, len(day.Slots))
+		for m, slot := range day.Slots {
+			row, ok := found[slot.RecipeID]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: recipe %d in the plan does not exist", ErrInvalidParams, slot.RecipeID)
+			}
+			grid[d][m] = repository.SpinCandidate{ID: row.ID, CookingTime: row.CookingTime, CategoryID: row.CategoryID}
+			repeats[d][m] = slot.CategoryRepeat
+		}
+	}
+	return grid, repeats, nil
+}
+
+// buildMealPlan loads the recipes of a filled grid into a plan
+func (s *recipesService) buildMealPlan(ctx context.Context, meals []string, grid [][]repository.SpinCandidate, repeats [][]bool, seed int64) (*MealPlan, error) {
+	var ids []int32
+	for _, day := range grid {
+		for _, c := range day {
+			ids = append(ids, c.ID)
+		}
+	}
+
+	rows, err := s.repo.ListRecipesByIDs(ctx, uniqueIDs(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan recipes: %w", err)
+	}
+	recipes := make(map[int32]*Recipe, len(rows))
+	list := make([]*Recipe, len(rows))
+	for i, row := range rows {
+		recipe := recipeFromRow(row)
+		list[i] = &recipe
+		recipes[row.ID] = &recipe
+	}
+	if err := s.enrichRecipes(ctx, list...); err != nil {
+		return nil, err
+	}
+
+	plan := &MealPlan{Meals: meals, Days: make([]MealPlanDay, len(grid)), Seed: seed}
+	for d, day := range grid {
+		planDay := MealPlanDay{Day: d + 1, Slots: make([]MealPlanSlot, len(day))}
+		for m, c := range day {
+			recipe, ok := recipes[c.ID]
+			if !ok {
+				return nil, fmt.Errorf("%w: recipe %d was removed while planning", ErrRecipeNotFound, c.ID)
+			}
+			planDay.Slots[m] = MealPlanSlot{
+				Meal:           meals[m],
+				RecipeID:       c.ID,
+				Recipe:         recipe,
+				CategoryRepeat: repeats[d][m],
+			}
+			planDay.CookingTime += recipe.CookingTime
+		}
+		plan.Days[d] = planDay
+	}
+	return plan, nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// mealPlanRepository serves catalog as the spin candidates, narrowed to the
+// filter's categories when it has any
+func mealPlanRepository(catalog []repository.SpinCandidate) *mockRecipesRepository {
+	return &mockRecipesRepository{
+		spinCandidatesFunc: func(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error) {
+			var candidates []repository.SpinCandidate
+			for _, c := range catalog {
+				if len(params.CategoryIDs) == 0 || containsID(params.CategoryIDs, c.CategoryID) {
+					candidates = append(candidates, c)
+				}
+			}
+			return candidates, nil
+		},
+		listByIDsFunc: func(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error) {
+			var rows []repository.RecipeRow
+			for _, c := range catalog {
+				if containsID(recipeIDs, c.ID) {
+					rows = append(rows, repository.RecipeRow{ID: c.ID, CookingTime: c.CookingTime, CategoryID: c.CategoryID})
+				}
+			}
+			return rows, nil
+		},
+	}
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// planRecipeIDs lists a plan's recipe IDs day by day
+func planRecipeIDs(plan *MealPlan) []int32 {
+	var ids []int32
+	for _, day := range plan.Days {
+		for _, slot := range day.Slots {
+			ids = append(ids, slot.RecipeID)
+		}
+	}
+	return ids
+}
+
+func mixedCatalog() []repository.SpinCandidate {
+	return []repository.SpinCandidate{
+		{ID: 1, CookingTime: 20, CategoryID: 1},
+		{ID: 2, CookingTime: 30, CategoryID: 1},
+		{ID: 3, CookingTime: 40, CategoryID: 1},
+		{ID: 4, CookingTime: 25, CategoryID: 2},
+		{ID: 5, CookingTime: 35, CategoryID: 2},
+		{ID: 6, CookingTime: 45, CategoryID: 2},
+		{ID: 7, CookingTime: 15, CategoryID: 3},
+		{ID: 8, CookingTime: 60, CategoryID: 3},
+	}
+}
+
+func TestGenerateMealPlan_NoDuplicates(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()))
+
+	plan, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{
+		Days:  4,
+		Meals: []string{"Lunch", " dinner "},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(plan.Days) != 4 || len(plan.Meals) != 2 || plan.Meals[0] != "lunch" || plan.Meals[1] != "dinner" {
+		t.Fatalf("Expected 4 days of lunch and dinner, got %d days of %v", len(plan.Days), plan.Meals)
+	}
+
+	seen := make(map[int32]bool)
+	for _, id := range planRecipeIDs(plan) {
+		if seen[id] {
+			t.Errorf("Recipe %d is planned twice", id)
+		}
+		seen[id] = true
+	}
+
+	for _, day := range plan.Days {
+		var total int32
+		for _, slot := range day.Slots {
+			total += slot.Recipe.CookingTime
+		}
+		if day.CookingTime != total {
+			t.Errorf("Day %d: expected cooking time %d, got %d", day.Day, total, day.CookingTime)
+		}
+	}
+}
+
+func TestGenerateMealPlan_NotEnoughRecipes(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()[:3]))
+
+	_, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{Days: 4})
+	if !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound, got %v", err)
+	}
+}
+
+func TestGenerateMealPlan_MaxDailyCookingTime(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()))
+
+	for seed := int64(0); seed < 20; seed++ {
+		seed := seed
+		plan, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{
+			Days:                2,
+			Meals:               []string{"lunch", "dinner"},
+			MaxDailyCookingTime: 60,
+			Filters:             RecipeFilters{Seed: &seed},
+		})
+		if err != nil {
+			t.Fatalf("Seed %d: expected no error, got %v", seed, err)
+		}
+		for _, day := range plan.Days {
+			if day.CookingTime > 60 {
+				t.Errorf("Seed %d: day %d takes %d minutes, over the 60 allowed", seed, day.Day, day.CookingTime)
+			}
+		}
+	}
+}
+
+func TestGenerateMealPlan_CategoryVariety(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()[:6]))
+
+	for seed := int64(0); seed < 20; seed++ {
+		seed := seed
+		plan, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{
+			Days:    6,
+			Filters: RecipeFilters{Seed: &seed},
+		})
+		if err != nil {
+			t.Fatalf("Seed %d: expected no error, got %v", seed, err)
+		}
+
+		// Two categories of three recipes each can only alternate
+		for i := 1; i < len(plan.Days); i++ {
+			prev, cur := plan.Days[i-1].Slots[0].Recipe, plan.Days[i].Slots[0].Recipe
+			if prev.CategoryID == cur.CategoryID {
+				t.Errorf("Seed %d: days %d and %d are both category %d", seed, i, i+1, cur.CategoryID)
+			}
+		}
+	}
+
+	// With a single category, repeats are allowed and flagged
+	service = NewRecipesService(mealPlanRepository(mixedCatalog()[:3]))
+	plan, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{Days: 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if plan.Days[0].Slots[0].CategoryRepeat || !plan.Days[1].Slots[0].CategoryRepeat || !plan.Days[2].Slots[0].CategoryRepeat {
+		t.Errorf("Expected days 2 and 3 to be flagged as category repeats, got %+v", plan.Days)
+	}
+}
+
+func TestGenerateMealPlan_DayFilters(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()))
+
+	plan, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{
+		Days:       3,
+		Filters:    RecipeFilters{CategoryIDs: []int32{1, 3}},
+		DayFilters: map[int]RecipeFilters{2: {CategoryIDs: []int32{3}}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, day := range plan.Days {
+		category := day.Slots[0].Recipe.CategoryID
+		if category == 2 {
+			t.Errorf("Day %d: category 2 is excluded by the plan filters", day.Day)
+		}
+		if day.Day == 2 && category != 3 {
+			t.Errorf("Day 2: expected category 3, got %d", category)
+		}
+	}
+}
+
+func TestGenerateMealPlan_Seed(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()))
+
+	seed := int64(42)
+	first, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{Days: 7, Filters: RecipeFilters{Seed: &seed}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{Days: 7, Filters: RecipeFilters{Seed: &seed}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if first.Seed != 42 || second.Seed != 42 {
+		t.Errorf("Expected seed 42, got %d and %d", first.Seed, second.Seed)
+	}
+	a, b := planRecipeIDs(first), planRecipeIDs(second)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Expected the same plan for the same seed, got %v and %v", a, b)
+		}
+	}
+}
+
+func TestRerollMealPlanSlot(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()))
+
+	req := MealPlanRequest{Days: 3, Meals: []string{"lunch", "dinner"}}
+	plan, err := service.GenerateMealPlan(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	rerolled, err := service.RerollMealPlanSlot(context.Background(), MealPlanRerollRequest{
+		MealPlanRequest: req,
+		Plan:            *plan,
+		Day:             2,
+		Meal:            "Dinner",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	before, after := planRecipeIDs(plan), planRecipeIDs(rerolled)
+	seen := make(map[int32]bool)
+	for i := range after {
+		if i == 3 {
+			if after[i] == before[i] {
+				t.Errorf("Expected day 2 dinner to change, still recipe %d", after[i])
+			}
+		} else if after[i] != before[i] {
+			t.Errorf("Slot %d: expected recipe %d to stay, got %d", i, before[i], after[i])
+		}
+		if seen[after[i]] {
+			t.Errorf("Recipe %d is planned twice after the re-roll", after[i])
+		}
+		seen[after[i]] = true
+	}
+}
+
+func TestMealPlan_InvalidParams(t *testing.T) {
+	service := NewRecipesService(mealPlanRepository(mixedCatalog()))
+
+	tests := []struct {
+		name string
+		req  MealPlanRequest
+	}{
+		{"too many days", MealPlanRequest{Days: 15}},
+		{"duplicate meal", MealPlanRequest{Meals: []string{"dinner", "Dinner"}}},
+		{"too many meals", MealPlanRequest{Meals: []string{"a", "b", "c", "d", "e"}}},
+		{"negative cooking time cap", MealPlanRequest{MaxDailyCookingTime: -1}},
+		{"day filter out of range", MealPlanRequest{Days: 3, DayFilters: map[int]RecipeFilters{4: {}}}},
+		{"invalid day filter", MealPlanRequest{DayFilters: map[int]RecipeFilters{1: {SkillLevels: []string{"expert"}}}}},
+		{"recent penalty", MealPlanRequest{Filters: RecipeFilters{Weights: &SpinWeights{RecentPenalty: 0.5}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GenerateMealPlan(context.Background(), tt.req)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+
+	plan, err := service.GenerateMealPlan(context.Background(), MealPlanRequest{Days: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, reroll := range []MealPlanRerollRequest{
+		{Plan: *plan, Day: 3, Meal: "dinner"},
+		{Plan: *plan, Day: 1, Meal: "breakfast"},
+		{Plan: MealPlan{}, Day: 1, Meal: "dinner"},
+	} {
+		if _, err := service.RerollMealPlanSlot(context.Background(), reroll); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("Day %d %s: expected ErrInvalidParams, got %v", reroll.Day, reroll.Meal, err)
+		}
+	}
+}
@@ -0,0 +1,598 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+var (
+	ErrMealPlanNotFound      = errors.New("meal plan not found")
+	ErrMealPlanEntryNotFound = errors.New("meal plan entry not found")
+)
+
+// Stored meal plan limits
+const (
+	maxMealPlanNameLength = 100
+	maxMealPlanDays       = 31
+	maxMealPlanEntries    = maxMealPlanDays * maxPlanMeals
+	// mealPlanDateLayout is how plan dates are written, e.g. 2026-01-19
+	mealPlanDateLayout = "2006-01-02"
+)
+
+// mealOrder sorts the usual meals of a day; other meals come after them,
+// alphabetically
+var mealOrder = map[string]int{
+	"breakfast": 1,
+	"brunch":    2,
+	"lunch":     3,
+	"snack":     4,
+	"dinner":    5,
+}
+
+// SavedMealPlan is a stored meal plan covering StartDate to EndDate
+type SavedMealPlan struct {
+	ID         int32           `json:"id"`
+	Name       string          `json:"name"`
+	StartDate  string          `json:"start_date"`
+	EndDate    string          `json:"end_date"`
+	EntryCount int64           `json:"entry_count"`
+	Entries    []MealPlanEntry `json:"entries,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// MealPlanEntry is a recipe planned for one meal of one day
+type MealPlanEntry struct {
+	ID          int32  `json:"id"`
+	Date        string `json:"date"`
+	Meal        string `json:"meal"`
+	RecipeID    int32  `json:"recipe_id"`
+	RecipeTitle string `json:"recipe_title"`
+	CookingTime int32  `json:"cooking_time"`
+	// Servings is the entry's override when CustomServings is set, and
+	// the recipe's own servings otherwise
+	Servings       int32 `json:"servings"`
+	CustomServings bool  `json:"custom_servings"`
+}
+
+// SaveMealPlanRequest holds data for creating or updating a meal plan.
+// Entries are only read on create, e.g. to save a generated plan.
+type SaveMealPlanRequest struct {
+	Name      string                 `json:"name"`
+	StartDate string                 `json:"start_date"`
+	EndDate   string                 `json:"end_date"`
+	Entries   []MealPlanEntryRequest `json:"entries,omitempty"`
+}
+
+// MealPlanEntryRequest puts a recipe in a slot. Servings overrides the
+// recipe's servings when set.
+type MealPlanEntryRequest struct {
+	Date     string `json:"date"`
+	Meal     string `json:"meal"`
+	RecipeID int32  `json:"recipe_id"`
+	Servings *int32 `json:"servings,omitempty"`
+}
+
+// MealPlanSpinRequest fills a slot with a spin over Filters. Recipes
+// already in the plan are never drawn.
+type MealPlanSpinRequest struct {
+	Date     string
+	Meal     string
+	Servings *int32
+	Filters  RecipeFilters
+}
+
+// MealPlanMoveRequest is the slot an entry moves to
+type MealPlanMoveRequest struct {
+	Date string `json:"date"`
+	Meal string `json:"meal"`
+}
+
+// MealPlansService defines the interface for stored meal plan business
+// logic. Every call is scoped to the plans of one client.
+type MealPlansService interface {
+	ListMealPlans(ctx context.Context, clientID string) ([]SavedMealPlan, error)
+	GetMealPlan(ctx context.Context, clientID string, id int32) (*SavedMealPlan, error)
+	CreateMealPlan(ctx context.Context, clientID string, req SaveMealPlanRequest) (*SavedMealPlan, error)
+	UpdateMealPlan(ctx context.Context, clientID string, id int32, req SaveMealPlanRequest) (*SavedMealPlan, error)
+	DeleteMealPlan(ctx context.Context, clientID string, id int32) error
+	AssignMealPlanEntry(ctx context.Context, clientID string, planID int32, req MealPlanEntryRequest) (*SavedMealPlan, error)
+	SpinMealPlanEntry(ctx context.Context, clientID string, planID int32, req MealPlanSpinRequest) (*SavedMealPlan, *SpinResult, error)
+	MoveMealPlanEntry(ctx context.Context, clientID string, planID, entryID int32, req MealPlanMoveRequest) (*SavedMealPlan, error)
+	SetMealPlanEntryServings(ctx context.Context, clientID string, planID, entryID int32, servings *int32) (*SavedMealPlan, error)
+	DeleteMealPlanEntry(ctx context.Context, clientID string, planID, entryID int32) error
+	GetMealPlanShoppingList(ctx context.Context, clientID string, id int32, units string) (*ShoppingList, error)
+}
+
+type mealPlansService struct {
+	repo    repository.MealPlansRepository
+	recipes RecipesService
+}
+
+// NewMealPlansService creates a new meal plans service. Slots are spun
+// with recipes.
+func NewMealPlansService(repo repository.MealPlansRepository, recipes RecipesService) MealPlansService {
+	return &mealPlansService{
+		repo:    repo,
+		recipes: recipes,
+	}
+}
+
+// ListMealPlans returns the client's plans, latest first
+func (s *mealPlansService) ListMealPlans(ctx context.Context, clientID string) ([]SavedMealPlan, error) {
+	if err := requireClientID(clientID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.ListMealPlans(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meal plans: %w", err)
+	}
+
+	plans := make([]SavedMealPlan, len(rows))
+	for i, row := range rows {
+		plans[i] = SavedMealPlan{
+			ID:         row.ID,
+			Name:       row.Name,
+			StartDate:  row.StartDate.Format(mealPlanDateLayout),
+			EndDate:    row.EndDate.Format(mealPlanDateLayout),
+			EntryCount: row.EntryCount,
+			CreatedAt:  row.CreatedAt,
+			UpdatedAt:  row.UpdatedAt,
+		}
+	}
+
+	return plans, nil
+}
+
+// GetMealPlan returns a plan with its entries ordered by date and meal
+func (s *mealPlansService) GetMealPlan(ctx context.Context, clientID string, id int32) (*SavedMealPlan, error) {
+	plan, err := s.getPlan(ctx, clientID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.ListMealPlanEntries(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load meal plan entries: %w", err)
+	}
+
+	entries := make([]MealPlanEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = MealPlanEntry{
+			ID:             row.ID,
+			Date:           row.PlanDate.Format(mealPlanDateLayout),
+			Meal:           row.Meal,
+			RecipeID:       row.RecipeID,
+			RecipeTitle:    row.RecipeTitle,
+			CookingTime:    row.CookingTime,
+			Servings:       row.RecipeServings,
+			CustomServings: row.Servings.Valid,
+		}
+		if row.Servings.Valid {
+			entries[i].Servings = row.Servings.Int32
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		return mealLess(entries[i].Meal, entries[j].Meal)
+	})
+
+	return &SavedMealPlan{
+		ID:         plan.ID,
+		Name:       plan.Name,
+		StartDate:  plan.StartDate.Format(mealPlanDateLayout),
+		EndDate:    plan.EndDate.Format(mealPlanDateLayout),
+		EntryCount: int64(len(entries)),
+		Entries:    entries,
+		CreatedAt:  plan.CreatedAt,
+		UpdatedAt:  plan.UpdatedAt,
+	}, nil
+}
+
+func (s *mealPlansService) CreateMealPlan(ctx context.Context, clientID string, req SaveMealPlanRequest) (*SavedMealPlan, error) {
+	if err := requireClientID(clientID); err != nil {
+		return nil, err
+	}
+
+	name, start, end, err := validateMealPlanDetails(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Entries) > maxMealPlanEntries {
+		return nil, fmt.Errorf("%w: at most %d entries per plan", ErrInvalidParams, maxMealPlanEntries)
+	}
+	entries := make([]db.UpsertMealPlanEntryParams, len(req.Entries))
+	slots := make(map[string]bool, len(req.Entries))
+	for i, entry := range req.Entries {
+		params, err := validateMealPlanEntry(entry, start, end)
+		if err != nil {
+			return nil, err
+		}
+		slot := params.PlanDate.Format(mealPlanDateLayout) + " " + params.Meal
+		if slots[slot] {
+			return nil, fmt.Errorf("%w: more than one entry for %s", ErrInvalidParams, slot)
+		}
+		slots[slot] = true
+		entries[i] = params
+	}
+
+	id, err := s.repo.CreateMealPlan(ctx, db.CreateMealPlanParams{
+		ClientID:  clientID,
+		Name:      name,
+		StartDate: start,
+		EndDate:   end,
+	}, entries)
+	if err != nil {
+		if errors.Is(err, repository.ErrUnknownReference) {
+			return nil, fmt.Errorf("%w: an entry's recipe does not exist", ErrRecipeNotFound)
+		}
+		return nil, fmt.Errorf("failed to create meal plan: %w", err)
+	}
+
+	return s.GetMealPlan(ctx, clientID, int32(id))
+}
+
+// UpdateMealPlan renames a plan or changes its dates. Dates that would
+// leave entries outside the plan are rejected.
+func (s *mealPlansService) UpdateMealPlan(ctx context.Context, clientID string, id int32, req SaveMealPlanRequest) (*SavedMealPlan, error) {
+	name, start, end, err := validateMealPlanDetails(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getPlan(ctx, clientID, id); err != nil {
+		return nil, err
+	}
+
+	outside, err := s.repo.CountMealPlanEntriesOutside(ctx, id, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check meal plan entries: %w", err)
+	}
+	if outside > 0 {
+		return nil, fmt.Errorf("%w: %d entries fall outside the new dates; move or delete them first", ErrConflict, outside)
+	}
+
+	if err := s.repo.UpdateMealPlan(ctx, db.UpdateMealPlanParams{
+		Name:      name,
+		StartDate: start,
+		EndDate:   end,
+		ID:        id,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update meal plan: %w", err)
+	}
+
+	return s.GetMealPlan(ctx, clientID, id)
+}
+
+// DeleteMealPlan deletes a plan and its entries
+func (s *mealPlansService) DeleteMealPlan(ctx context.Context, clientID string, id int32) error {
+	if _, err := s.getPlan(ctx, clientID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteMealPlan(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete meal plan: %w", err)
+	}
+
+	return nil
+}
+
+// AssignMealPlanEntry puts a recipe in a slot, replacing the recipe there
+func (s *mealPlansService) AssignMealPlanEntry(ctx context.Context, clientID string, planID int32, req MealPlanEntryRequest) (*SavedMealPlan, error) {
+	plan, err := s.getPlan(ctx, clientID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := validateMealPlanEntry(req, plan.StartDate, plan.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	params.MealPlanID = planID
+
+	if err := s.repo.AssignMealPlanEntry(ctx, params); err != nil {
+		if errors.Is(err, repository.ErrUnknownReference) {
+			return nil, fmt.Errorf("%w: recipe %d does not exist", ErrRecipeNotFound, req.RecipeID)
+		}
+		return nil, fmt.Errorf("failed to assign meal plan entry: %w", err)
+	}
+
+	return s.GetMealPlan(ctx, clientID, planID)
+}
+
+// SpinMealPlanEntry fills a slot with a spin, replacing the recipe there.
+// The spin never lands on a recipe already in the plan.
+func (s *mealPlansService) SpinMealPlanEntry(ctx context.Context, clientID string, planID int32, req MealPlanSpinRequest) (*SavedMealPlan, *SpinResult, error) {
+	plan, err := s.getPlan(ctx, clientID, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check the slot before spinning, so a bad slot does not record a spin
+	if _, _, err := validateMealPlanSlot(req.Date, req.Meal, plan.StartDate, plan.EndDate); err != nil {
+		return nil, nil, err
+	}
+	if err := validateEntryServings(req.Servings); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.repo.ListMealPlanEntries(ctx, planID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load meal plan entries: %w", err)
+	}
+
+	filters := req.Filters
+	filters.ExcludeRecipeIDs = append([]int32(nil), filters.ExcludeRecipeIDs...)
+	for _, row := range rows {
+		filters.ExcludeRecipeIDs = append(filters.ExcludeRecipeIDs, row.RecipeID)
+	}
+
+	result, err := s.recipes.GetRandomRecipe(ctx, filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	saved, err := s.AssignMealPlanEntry(ctx, clientID, planID, MealPlanEntryRequest{
+		Date:     req.Date,
+		Meal:     req.Meal,
+		RecipeID: result.Recipe.ID,
+		Servings: req.Servings,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return saved, result, nil
+}
+
+// MoveMealPlanEntry moves an entry to another slot of the plan, swapping
+// with the entry already there if any
+func (s *mealPlansService) MoveMealPlanEntry(ctx context.Context, clientID string, planID, entryID int32, req MealPlanMoveRequest) (*SavedMealPlan, error) {
+	plan, entry, err := s.getEntry(ctx, clientID, planID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	date, meal, err := validateMealPlanSlot(req.Date, req.Meal, plan.StartDate, plan.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.MoveMealPlanEntry(ctx, entry, date, meal); err != nil {
+		return nil, fmt.Errorf("failed to move meal plan entry: %w", err)
+	}
+
+	return s.GetMealPlan(ctx, clientID, planID)
+}
+
+// SetMealPlanEntryServings overrides the servings of an entry; nil goes
+// back to the recipe's servings
+func (s *mealPlansService) SetMealPlanEntryServings(ctx context.Context, clientID string, planID, entryID int32, servings *int32) (*SavedMealPlan, error) {
+	if err := validateEntryServings(servings); err != nil {
+		return nil, err
+	}
+
+	_, entry, err := s.getEntry(ctx, clientID, planID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var value sql.NullInt32
+	if servings != nil {
+		value = sql.NullInt32{Int32: *servings, Valid: true}
+	}
+	if err := s.repo.UpdateMealPlanEntryServings(ctx, entry, value); err != nil {
+		return nil, fmt.Errorf("failed to update meal plan entry: %w", err)
+	}
+
+	return s.GetMealPlan(ctx, clientID, planID)
+}
+
+// DeleteMealPlanEntry empties a slot
+func (s *mealPlansService) DeleteMealPlanEntry(ctx context.Context, clientID string, planID, entryID int32) error {
+	_, entry, err := s.getEntry(ctx, clientID, planID, entryID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteMealPlanEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to delete meal plan entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetMealPlanShoppingList builds the shopping list for every entry of a
+// plan at the entry's servings. Units optionally converts quantities to
+// metric or us.
+func (s *mealPlansService) GetMealPlanShoppingList(ctx context.Context, clientID string, id int32, units string) (*ShoppingList, error) {
+	if units != "" {
+		if _, err := ParseUnitSystem(units); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := s.GetMealPlan(ctx, clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Entries) == 0 {
+		return &ShoppingList{Name: plan.Name, Recipes: []ShoppingListRecipe{}, Aisles: []ShoppingAisle{}}, nil
+	}
+
+	req := ShoppingListRequest{
+		Recipes: make([]ShoppingListRecipe, len(plan.Entries)),
+		Units:   units,
+	}
+	for i, entry := range plan.Entries {
+		req.Recipes[i] = ShoppingListRecipe{RecipeID: entry.RecipeID, Servings: entry.Servings}
+	}
+
+	list, err := s.recipes.BuildShoppingList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	list.Name = plan.Name
+
+	return list, nil
+}
+
+// getPlan loads one of the client's plans without its entries. Another
+// client's plan is reported as not found.
+func (s *mealPlansService) getPlan(ctx context.Context, clientID string, id int32) (db.MealPlan, error) {
+	if err := requireClientID(clientID); err != nil {
+		return db.MealPlan{}, err
+	}
+	if id < 1 {
+		return db.MealPlan{}, fmt.Errorf("%w: invalid meal plan ID", ErrInvalidParams)
+	}
+
+	plan, err := s.repo.GetMealPlanByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.MealPlan{}, ErrMealPlanNotFound
+		}
+		return db.MealPlan{}, fmt.Errorf("failed to get meal plan: %w", err)
+	}
+	if plan.ClientID != clientID {
+		return db.MealPlan{}, ErrMealPlanNotFound
+	}
+	return plan, nil
+}
+
+// getEntry loads an entry, checking it belongs to one of the client's plans
+func (s *mealPlansService) getEntry(ctx context.Context, clientID string, planID, entryID int32) (db.MealPlan, db.MealPlanEntry, error) {
+	plan, err := s.getPlan(ctx, clientID, planID)
+	if err != nil {
+		return db.MealPlan{}, db.MealPlanEntry{}, err
+	}
+	if entryID < 1 {
+		return db.MealPlan{}, db.MealPlanEntry{}, fmt.Errorf("%w: invalid meal plan entry ID", ErrInvalidParams)
+	}
+
+	entry, err := s.repo.GetMealPlanEntryByID(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.MealPlan{}, db.MealPlanEntry{}, ErrMealPlanEntryNotFound
+		}
+		return db.MealPlan{}, db.MealPlanEntry{}, fmt.Errorf("failed to get meal plan entry: %w", err)
+	}
+	if entry.MealPlanID != planID {
+		return db.MealPlan{}, db.MealPlanEntry{}, ErrMealPlanEntryNotFound
+	}
+	return plan, entry, nil
+}
+
+// validateMealPlanDetails checks a plan's name and date range
+func validateMealPlanDetails(req SaveMealPlanRequest) (string, time.Time, time.Time, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("%w: name is required", ErrInvalidParams)
+	}
+	if len(name) > maxMealPlanNameLength {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidParams, maxMealPlanNameLength)
+	}
+
+	start, err := parseMealPlanDate("start_date", req.StartDate)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	end, err := parseMealPlanDate("end_date", req.EndDate)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	if end.Before(start) {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidParams)
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > maxMealPlanDays {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("%w: a plan covers at most %d days", ErrInvalidParams, maxMealPlanDays)
+	}
+
+	return name, start, end, nil
+}
+
+// validateMealPlanEntry checks an entry falls within start and end and
+// converts it for the repository
+func validateMealPlanEntry(req MealPlanEntryRequest, start, end time.Time) (db.UpsertMealPlanEntryParams, error) {
+	date, meal, err := validateMealPlanSlot(req.Date, req.Meal, start, end)
+	if err != nil {
+		return db.UpsertMealPlanEntryParams{}, err
+	}
+	if req.RecipeID < 1 {
+		return db.UpsertMealPlanEntryParams{}, fmt.Errorf("%w: invalid recipe ID", ErrInvalidParams)
+	}
+	if err := validateEntryServings(req.Servings); err != nil {
+		return db.UpsertMealPlanEntryParams{}, err
+	}
+
+	params := db.UpsertMealPlanEntryParams{
+		PlanDate: date,
+		Meal:     meal,
+		RecipeID: req.RecipeID,
+	}
+	if req.Servings != nil {
+		params.Servings = sql.NullInt32{Int32: *req.Servings, Valid: true}
+	}
+	return params, nil
+}
+
+// validateMealPlanSlot parses a slot's date, checking it falls within
+// start and end, and normalizes its meal
+func validateMealPlanSlot(dateValue, meal string, start, end time.Time) (time.Time, string, error) {
+	date, err := parseMealPlanDate("date", dateValue)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	if date.Before(start) || date.After(end) {
+		return time.Time{}, "", fmt.Errorf("%w: date %s is outside the plan", ErrInvalidParams, date.Format(mealPlanDateLayout))
+	}
+
+	meal, err = normalizeMealName(meal)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return date, meal, nil
+}
+
+func validateEntryServings(servings *int32) error {
+	if servings != nil && (*servings < 1 || *servings > maxScaledServings) {
+		return fmt.Errorf("%w: servings must be between 1 and %d", ErrInvalidParams, maxScaledServings)
+	}
+	return nil
+}
+
+// parseMealPlanDate parses a YYYY-MM-DD date
+func parseMealPlanDate(field, value string) (time.Time, error) {
+	date, err := time.Parse(mealPlanDateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a date like 2026-01-19", ErrInvalidParams, field)
+	}
+	return date, nil
+}
+
+// mealLess orders meals by mealOrder, then alphabetically
+func mealLess(a, b string) bool {
+	ra, rb := mealOrder[a], mealOrder[b]
+	if ra == 0 {
+		ra = len(mealOrder) + 1
+	}
+	if rb == 0 {
+		rb = len(mealOrder) + 1
+	}
+	if ra != rb {
+		return ra < rb
+	}
+	return a < b
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Mock repository for testing. It holds plan 1 of kitchen-1, running
+// from 2026-01-19 to 2026-01-25, with the given entries.
+type mockMealPlansRepository struct {
+	entries      []db.ListMealPlanEntriesRow
+	createdPlan  *db.CreateMealPlanParams
+	outside      int64
+	assignErr    error
+	created      []db.UpsertMealPlanEntryParams
+	assigned     *db.UpsertMealPlanEntryParams
+	movedTo      *db.GetMealPlanEntryBySlotParams
+	servingsSet  *sql.NullInt32
+	updatedPlans int
+}
+
+func mealPlanDate(value string) time.Time {
+	date, _ := time.Parse(mealPlanDateLayout, value)
+	return date
+}
+
+func (m *mockMealPlansRepository) ListMealPlans(ctx context.Context, clientID string) ([]db.ListMealPlansRow, error) {
+	if clientID != "kitchen-1" {
+		return []db.ListMealPlansRow{}, nil
+	}
+	return []db.ListMealPlansRow{{ID: 1, Name: "Week 4", StartDate: mealPlanDate("2026-01-19"), EndDate: mealPlanDate("2026-01-25")}}, nil
+}
+
+func (m *mockMealPlansRepository) GetMealPlanByID(ctx context.Context, id int32) (db.MealPlan, error) {
+	if id != 1 {
+		return db.MealPlan{}, sql.ErrNoRows
+	}
+	return db.MealPlan{ID: 1, ClientID: "kitchen-1", Name: "Week 4", StartDate: mealPlanDate("2026-01-19"), EndDate: mealPlanDate("2026-01-25")}, nil
+}
+
+func (m *mockMealPlansRepository) CreateMealPlan(ctx context.Context, params db.CreateMealPlanParams, entries []db.UpsertMealPlanEntryParams) (int64, error) {
+	m.createdPlan = &params
+	m.created = entries
+	return 1, nil
+}
+
+func (m *mockMealPlansRepository) UpdateMealPlan(ctx context.Context, params db.UpdateMealPlanParams) error {
+	m.updatedPlans++
+	return nil
+}
+
+func (m *mockMealPlansRepository) DeleteMealPlan(ctx context.Context, id int32) error {
+	return nil
+}
+
+func (m *mockMealPlansRepository) CountMealPlanEntriesOutside(ctx context.Context, id int32, start, end time.Time) (int64, error) {
+	return m.outside, nil
+}
+
+func (m *mockMealPlansRepository) ListMealPlanEntries(ctx context.Context, id int32) ([]db.ListMealPlanEntriesRow, error) {
+	return m.entries, nil
+}
+
+func (m *mockMealPlansRepository) GetMealPlanEntryByID(ctx context.Context, id int32) (db.MealPlanEntry, error) {
+	for _, row := range m.entries {
+		if row.ID == id {
+			return db.MealPlanEntry{ID: row.ID, MealPlanID: row.MealPlanID, PlanDate: row.PlanDate, Meal: row.Meal, RecipeID: row.RecipeID, Servings: row.Servings}, nil
+		}
+	}
+	return db.MealPlanEntry{}, sql.ErrNoRows
+}
+
+func (m *mockMealPlansRepository) AssignMealPlanEntry(ctx context.Context, params db.UpsertMealPlanEntryParams) error {
+	if m.assignErr != nil {
+		return m.assignErr
+	}
+	m.assigned = &params
+	return nil
+}
+
+func (m *mockMealPlansRepository) MoveMealPlanEntry(ctx context.Context, entry db.MealPlanEntry, date time.Time, meal string) error {
+	m.movedTo = &db.GetMealPlanEntryBySlotParams{MealPlanID: entry.MealPlanID, PlanDate: date, Meal: meal}
+	return nil
+}
+
+func (m *mockMealPlansRepository) UpdateMealPlanEntryServings(ctx context.Context, entry db.MealPlanEntry, servings sql.NullInt32) error {
+	m.servingsSet = &servings
+	return nil
+}
+
+func (m *mockMealPlansRepository) DeleteMealPlanEntry(ctx context.Context, entry db.MealPlanEntry) error {
+	return nil
+}
+
+func planEntries() []db.ListMealPlanEntriesRow {
+	return []db.ListMealPlanEntriesRow{
+		{ID: 1, MealPlanID: 1, PlanDate: mealPlanDate("2026-01-20"), Meal: "dinner", RecipeID: 1, RecipeServings: 2},
+		{ID: 2, MealPlanID: 1, PlanDate: mealPlanDate("2026-01-20"), Meal: "breakfast", RecipeID: 2, RecipeServings: 4, Servings: sql.NullInt32{Int32: 1, Valid: true}},
+		{ID: 3, MealPlanID: 1, PlanDate: mealPlanDate("2026-01-19"), Meal: "supper", RecipeID: 3, RecipeServings: 2},
+		{ID: 4, MealPlanID: 2, PlanDate: mealPlanDate("2026-01-19"), Meal: "lunch", RecipeID: 4, RecipeServings: 2},
+	}
+}
+
+func TestGetMealPlan(t *testing.T) {
+	service := NewMealPlansService(&mockMealPlansRepository{entries: planEntries()[:3]}, nil)
+
+	plan, err := service.GetMealPlan(context.Background(), "kitchen-1", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if plan.StartDate != "2026-01-19" || plan.EndDate != "2026-01-25" || plan.EntryCount != 3 {
+		t.Errorf("Unexpected plan %+v", plan)
+	}
+
+	// By date, then breakfast before dinner
+	expected := []int32{3, 2, 1}
+	for i, entry := range plan.Entries {
+		if entry.ID != expected[i] {
+			t.Fatalf("Expected entries in order %v, got %+v", expected, plan.Entries)
+		}
+	}
+
+	if breakfast := plan.Entries[1]; breakfast.Servings != 1 || !breakfast.CustomServings {
+		t.Errorf("Expected overridden servings 1, got %+v", breakfast)
+	}
+	if dinner := plan.Entries[2]; dinner.Servings != 2 || dinner.CustomServings {
+		t.Errorf("Expected the recipe's servings 2, got %+v", dinner)
+	}
+
+	if _, err := service.GetMealPlan(context.Background(), "kitchen-1", 2); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound, got %v", err)
+	}
+}
+
+func TestCreateMealPlan(t *testing.T) {
+	mockRepo := &mockMealPlansRepository{}
+	service := NewMealPlansService(mockRepo, nil)
+
+	servings := int32(3)
+	_, err := service.CreateMealPlan(context.Background(), "kitchen-1", SaveMealPlanRequest{
+		Name:      "  Week 4 ",
+		StartDate: "2026-01-19",
+		EndDate:   "2026-01-25",
+		Entries: []MealPlanEntryRequest{
+			{Date: "2026-01-19", Meal: "Dinner", RecipeID: 5, Servings: &servings},
+			{Date: "2026-01-20", Meal: "dinner", RecipeID: 6},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if mockRepo.createdPlan.ClientID != "kitchen-1" {
+		t.Errorf("Expected a plan owned by kitchen-1, got %+v", mockRepo.createdPlan)
+	}
+	if len(mockRepo.created) != 2 || mockRepo.created[0].Meal != "dinner" || mockRepo.created[0].Servings.Int32 != 3 || mockRepo.created[1].Servings.Valid {
+		t.Errorf("Unexpected entries %+v", mockRepo.created)
+	}
+}
+
+func TestCreateMealPlan_InvalidParams(t *testing.T) {
+	service := NewMealPlansService(&mockMealPlansRepository{}, nil)
+
+	zero := int32(0)
+	tests := []struct {
+		name string
+		req  SaveMealPlanRequest
+	}{
+		{"missing name", SaveMealPlanRequest{StartDate: "2026-01-19", EndDate: "2026-01-25"}},
+		{"bad date", SaveMealPlanRequest{Name: "Plan", StartDate: "19/01/2026", EndDate: "2026-01-25"}},
+		{"end before start", SaveMealPlanRequest{Name: "Plan", StartDate: "2026-01-25", EndDate: "2026-01-19"}},
+		{"too long", SaveMealPlanRequest{Name: "Plan", StartDate: "2026-01-01", EndDate: "2026-02-01"}},
+		{"entry outside", SaveMealPlanRequest{Name: "Plan", StartDate: "2026-01-19", EndDate: "2026-01-25", Entries: []MealPlanEntryRequest{
+			{Date: "2026-01-26", Meal: "dinner", RecipeID: 1},
+		}}},
+		{"duplicate slot", SaveMealPlanRequest{Name: "Plan", StartDate: "2026-01-19", EndDate: "2026-01-25", Entries: []MealPlanEntryRequest{
+			{Date: "2026-01-19", Meal: "dinner", RecipeID: 1},
+			{Date: "2026-01-19", Meal: "Dinner", RecipeID: 2},
+		}}},
+		{"zero servings", SaveMealPlanRequest{Name: "Plan", StartDate: "2026-01-19", EndDate: "2026-01-25", Entries: []MealPlanEntryRequest{
+			{Date: "2026-01-19", Meal: "dinner", RecipeID: 1, Servings: &zero},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateMealPlan(context.Background(), "kitchen-1", tt.req); !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMealPlan_OtherClient(t *testing.T) {
+	mockRepo := &mockMealPlansRepository{entries: planEntries()}
+	service := NewMealPlansService(mockRepo, nil)
+
+	plans, err := service.ListMealPlans(context.Background(), "kitchen-2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(plans) != 0 {
+		t.Errorf("Expected no plans for kitchen-2, got %+v", plans)
+	}
+
+	if _, err := service.GetMealPlan(context.Background(), "kitchen-2", 1); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound, got %v", err)
+	}
+	if err := service.DeleteMealPlan(context.Background(), "kitchen-2", 1); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound, got %v", err)
+	}
+	if _, err := service.AssignMealPlanEntry(context.Background(), "kitchen-2", 1, MealPlanEntryRequest{Date: "2026-01-19", Meal: "lunch", RecipeID: 5}); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound, got %v", err)
+	}
+	if err := service.DeleteMealPlanEntry(context.Background(), "kitchen-2", 1, 1); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound, got %v", err)
+	}
+	if mockRepo.assigned != nil {
+		t.Error("Expected another client's plan to be left alone")
+	}
+
+	if _, err := service.ListMealPlans(context.Background(), ""); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams without a client ID, got %v", err)
+	}
+	if _, err := service.CreateMealPlan(context.Background(), "", SaveMealPlanRequest{Name: "Plan", StartDate: "2026-01-19", EndDate: "2026-01-25"}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams without a client ID, got %v", err)
+	}
+}
+
+func TestUpdateMealPlan_EntriesOutside(t *testing.T) {
+	mockRepo := &mockMealPlansRepository{outside: 2}
+	service := NewMealPlansService(mockRepo, nil)
+
+	_, err := service.UpdateMealPlan(context.Background(), "kitchen-1", 1, SaveMealPlanRequest{Name: "Short week", StartDate: "2026-01-19", EndDate: "2026-01-21"})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+	if mockRepo.updatedPlans != 0 {
+		t.Error("Expected the plan not to be updated")
+	}
+}
+
+func TestAssignMealPlanEntry_UnknownRecipe(t *testing.T) {
+	service := NewMealPlansService(&mockMealPlansRepository{assignErr: repository.ErrUnknownReference}, nil)
+
+	_, err := service.AssignMealPlanEntry(context.Background(), "kitchen-1", 1, MealPlanEntryRequest{Date: "2026-01-19", Meal: "lunch", RecipeID: 99})
+	if !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound, got %v", err)
+	}
+}
+
+func TestMoveMealPlanEntry(t *testing.T) {
+	mockRepo := &mockMealPlansRepository{entries: planEntries()}
+	service := NewMealPlansService(mockRepo, nil)
+
+	if _, err := service.MoveMealPlanEntry(context.Background(), "kitchen-1", 1, 1, MealPlanMoveRequest{Date: "2026-01-22", Meal: " Lunch"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockRepo.movedTo == nil || mockRepo.movedTo.Meal != "lunch" || !mockRepo.movedTo.PlanDate.Equal(mealPlanDate("2026-01-22")) {
+		t.Errorf("Expected a move to lunch on 2026-01-22, got %+v", mockRepo.movedTo)
+	}
+
+	// Entry 4 belongs to another plan
+	if _, err := service.MoveMealPlanEntry(context.Background(), "kitchen-1", 1, 4, MealPlanMoveRequest{Date: "2026-01-22", Meal: "lunch"}); !errors.Is(err, ErrMealPlanEntryNotFound) {
+		t.Errorf("Expected ErrMealPlanEntryNotFound, got %v", err)
+	}
+
+	if _, err := service.MoveMealPlanEntry(context.Background(), "kitchen-1", 1, 1, MealPlanMoveRequest{Date: "2026-01-26", Meal: "lunch"}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+}
+
+func TestSetMealPlanEntryServings(t *testing.T) {
+	mockRepo := &mockMealPlansRepository{entries: planEntries()}
+	service := NewMealPlansService(mockRepo, nil)
+
+	servings := int32(6)
+	if _, err := service.SetMealPlanEntryServings(context.Background(), "kitchen-1", 1, 1, &servings); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockRepo.servingsSet == nil || *mockRepo.servingsSet != (sql.NullInt32{Int32: 6, Valid: true}) {
+		t.Errorf("Expected servings 6, got %+v", mockRepo.servingsSet)
+	}
+
+	if _, err := service.SetMealPlanEntryServings(context.Background(), "kitchen-1", 1, 1, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockRepo.servingsSet.Valid {
+		t.Error("Expected the override to be cleared")
+	}
+
+	tooMany := int32(maxScaledServings + 1)
+	if _, err := service.SetMealPlanEntryServings(context.Background(), "kitchen-1", 1, 1, &tooMany); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+}
+
+func TestSpinMealPlanEntry_SkipsPlannedRecipes(t *testing.T) {
+	recipesRepo := &mockRecipesRepository{}
+	spinPool(recipesRepo, []int32{1, 2, 3, 7})
+	recipes := NewRecipesService(recipesRepo)
+	mockRepo := &mockMealPlansRepository{entries: planEntries()[:3]}
+	service := NewMealPlansService(mockRepo, recipes)
+
+	_, result, err := service.SpinMealPlanEntry(context.Background(), "kitchen-1", 1, MealPlanSpinRequest{Date: "2026-01-21", Meal: "dinner"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Recipe.ID != 7 || result.Candidates != 1 {
+		t.Errorf("Expected the only unplanned recipe 7, got %d of %d candidates", result.Recipe.ID, result.Candidates)
+	}
+	if mockRepo.assigned == nil || mockRepo.assigned.RecipeID != 7 || mockRepo.assigned.Meal != "dinner" {
+		t.Errorf("Expected recipe 7 assigned to dinner, got %+v", mockRepo.assigned)
+	}
+
+	// A slot outside the plan fails before spinning
+	if _, _, err := service.SpinMealPlanEntry(context.Background(), "kitchen-1", 1, MealPlanSpinRequest{Date: "2026-02-01", Meal: "dinner"}); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+}
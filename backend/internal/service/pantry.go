@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxPantryItems   = 100
+	maxMissingLimit  = 20
+	defaultPantryHit = 20
+)
+
+// pantryStaples are assumed to be in every kitchen and never count as
+// required ingredients
+var pantryStaples = map[string]bool{
+	"water": true, "salt": true, "pepper": true, "salt and pepper": true,
+	"black pepper": true, "ice": true,
+}
+
+// PantrySearchRequest holds the ingredients a user has on hand
+type PantrySearchRequest struct {
+	Ingredients []string `json:"ingredients"`
+	// MaxMissing allows recipes that still need up to this many ingredients
+	MaxMissing int32 `json:"max_missing"`
+	Limit      int32 `json:"limit"`
+}
+
+// PantryMatch is a recipe ranked by how much of it the pantry covers
+type PantryMatch struct {
+	Recipe   Recipe   `json:"recipe"`
+	Coverage float64  `json:"coverage"`
+	Required int      `json:"required"`
+	Matched  []string `json:"matched"`
+	Missing  []string `json:"missing"`
+}
+
+// PantrySearchResponse represents the response for a pantry search
+type PantrySearchResponse struct {
+	Data []PantryMatch `json:"data"`
+}
+
+// SearchByPantry returns recipes that can be cooked with the given
+// ingredients, missing at most MaxMissing required ingredients. Results are
+// ordered by fewest missing ingredients, then by coverage.
+func (s *recipesService) SearchByPantry(ctx context.Context, req PantrySearchRequest) (*PantrySearchResponse, error) {
+	pantry, err := normalizePantry(req.Ingredients)
+	if err != nil {
+		return nil, err
+	}
+	if len(pantry) == 0 {
+		return nil, fmt.Errorf("%w: at least one pantry ingredient is required", ErrInvalidParams)
+	}
+	if req.MaxMissing < 0 || req.MaxMissing > maxMissingLimit {
+		return nil, fmt.Errorf("%w: max_missing must be between 0 and %d", ErrInvalidParams, maxMissingLimit)
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = defaultPantryHit
+	}
+
+	matches, err := s.repo.ListPantryMatches(ctx, pantry, req.MaxMissing, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match pantry: %w", err)
+	}
+
+	ids := make([]int32, len(matches))
+	for i, match := range matches {
+		ids[i] = match.RecipeID
+	}
+
+	rows, err := s.repo.ListRecipesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipes: %w", err)
+	}
+
+	recipes := make(map[int32]*Recipe, len(rows))
+	refs := make([]*Recipe, 0, len(rows))
+	for _, row := range rows {
+		recipe := new(Recipe)
+		*recipe = recipeFromRow(row)
+		recipes[row.ID] = recipe
+		refs = append(refs, recipe)
+	}
+
+	if err := s.enrichRecipes(ctx, refs...); err != nil {
+		return nil, err
+	}
+
+	data := make([]PantryMatch, 0, len(matches))
+	for _, match := range matches {
+		recipe, ok := recipes[match.RecipeID]
+		if !ok {
+			continue
+		}
+		result := PantryMatch{
+			Recipe:   *recipe,
+			Coverage: 1,
+			Required: int(match.Required),
+			Matched:  []string{},
+			Missing:  []string{},
+		}
+		if match.Required > 0 {
+			result.Coverage = float64(match.Required-match.Missing) / float64(match.Required)
+		}
+		for _, item := range recipe.IngredientItems {
+			if isOptionalIngredient(item.Name, item.Note) {
+				continue
+			}
+			if pantryHas(pantry, item.Name) {
+				result.Matched = append(result.Matched, item.Name)
+			} else {
+				result.Missing = append(result.Missing, item.Name)
+			}
+		}
+		data = append(data, result)
+	}
+
+	return &PantrySearchResponse{Data: data}, nil
+}
+
+// normalizePantry lowercases, singularizes and de-duplicates pantry items
+func normalizePantry(items []string) ([]string, error) {
+	if len(items) > maxPantryItems {
+		return nil, fmt.Errorf("%w: at most %d pantry ingredients are allowed", ErrInvalidParams, maxPantryItems)
+	}
+
+	pantry := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		name := foodName(item)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		pantry = append(pantry, name)
+	}
+	return pantry, nil
+}
+
+// isOptionalIngredient reports whether an ingredient can be left out
+// without making the recipe uncookable: staples and garnishes
+func isOptionalIngredient(name string, note *string) bool {
+	if pantryStaples[foodName(name)] {
+		return true
+	}
+	if note == nil {
+		return false
+	}
+	lower := strings.ToLower(*note)
+	return strings.Contains(lower, "to taste") ||
+		strings.Contains(lower, "for garnish") ||
+		strings.Contains(lower, "optional")
+}
+
+// pantryHas reports whether any pantry item covers the ingredient. The
+// pantry item may name the ingredient's head noun ("rice" covers "cooked
+// rice" but not "rice flour") or be more specific than it ("chicken breast"
+// covers "chicken"). The repository's pantry filter applies the same rule
+// to the stored food names.
+func pantryHas(pantry []string, ingredient string) bool {
+	name := foodName(ingredient)
+	for _, item := range pantry {
+		if name == item || strings.HasSuffix(name, " "+item) || strings.Contains(" "+item+" ", " "+name+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// IngredientMatch returns the food name and optional flag stored with a
+// structured ingredient, which the repository's pantry matching reads
+func IngredientMatch(item Ingredient) (string, bool) {
+	return foodName(item.Name), isOptionalIngredient(item.Name, item.Note)
+}
+
+// foodName lowercases a name and singularizes each word so "Eggs" and
+// "egg" compare equal
+func foodName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	for i, word := range words {
+		words[i] = singularize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func singularize(word string) string {
+	switch {
+	case len(word) <= 3 || strings.HasSuffix(word, "ss"):
+		return word
+	case strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// PantryStatus is how much of a recipe a client's stored pantry covers:
+// Have of its Required ingredients, as counted by a pantry search
+type PantryStatus struct {
+	Have     int      `json:"have"`
+	Required int      `json:"required"`
+	Missing  []string `json:"missing"`
+	// Expiring lists the recipe's ingredients covered by pantry items that
+	// expire in the next few days
+	Expiring []string `json:"expiring"`
+}
+
+// AnnotatePantry sets Pantry on recipes whose ingredients are loaded,
+// using the client's stored pantry. Used-up and expired items are not on
+// hand.
+func (s *recipesService) AnnotatePantry(ctx context.Context, clientID string, recipes ...*Recipe) error {
+	if clientID == "" {
+		return fmt.Errorf("%w: a client ID is required", ErrInvalidParams)
+	}
+	if err := validateClientID(clientID); err != nil {
+		return err
+	}
+
+	var stocked, expiring []string
+	if s.pantry != nil {
+		var err error
+		if stocked, err = s.pantry.ListStockedPantryItems(ctx, clientID); err != nil {
+			return fmt.Errorf("failed to load pantry: %w", err)
+		}
+		if expiring, err = s.pantry.ListExpiringPantryItems(ctx, clientID, defaultExpiringDays); err != nil {
+			return fmt.Errorf("failed to load pantry: %w", err)
+		}
+	}
+
+	for _, recipe := range recipes {
+		status := &PantryStatus{Missing: []string{}, Expiring: []string{}}
+		for _, item := range recipe.IngredientItems {
+			if pantryHas(expiring, item.Name) {
+				status.Expiring = append(status.Expiring, item.Name)
+			}
+			if isOptionalIngredient(item.Name, item.Note) {
+				continue
+			}
+			status.Required++
+			if pantryHas(stocked, item.Name) {
+				status.Have++
+			} else {
+				status.Missing = append(status.Missing, item.Name)
+			}
+		}
+		recipe.Pantry = status
+	}
+
+	return nil
+}
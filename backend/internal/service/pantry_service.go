@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+var ErrPantryItemNotFound = errors.New("pantry item not found")
+
+// Pantry item limits
+const (
+	maxPantryItemNameLength = 100
+	maxPantryUnitLength     = 20
+	maxPantryQuantity       = 1000000
+)
+
+// PantryItem is an ingredient a client has on hand. Name is stored
+// lowercased and singular ("Eggs" becomes "egg").
+type PantryItem struct {
+	ID       int32    `json:"id"`
+	Name     string   `json:"name"`
+	Quantity *float64 `json:"quantity"`
+	Unit     *string  `json:"unit"`
+	// ExpiresOn is a date like 2026-01-19
+	ExpiresOn *string   `json:"expires_on"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PantryItemRequest holds data for adding or replacing a pantry item. A
+// quantity of 0 records an item as used up.
+type PantryItemRequest struct {
+	Name      string   `json:"name"`
+	Quantity  *float64 `json:"quantity,omitempty"`
+	Unit      *string  `json:"unit,omitempty"`
+	ExpiresOn *string  `json:"expires_on,omitempty"`
+}
+
+// PantryService defines the interface for pantry inventory business
+// logic. Every call is scoped to one client's pantry.
+type PantryService interface {
+	ListPantryItems(ctx context.Context, clientID string) ([]PantryItem, error)
+	CreatePantryItem(ctx context.Context, clientID string, req PantryItemRequest) (*PantryItem, error)
+	UpdatePantryItem(ctx context.Context, clientID string, id int32, req PantryItemRequest) (*PantryItem, error)
+	DeletePantryItem(ctx context.Context, clientID string, id int32) error
+}
+
+type pantryService struct {
+	repo repository.PantryRepository
+}
+
+// NewPantryService creates a new pantry service
+func NewPantryService(repo repository.PantryRepository) PantryService {
+	return &pantryService{
+		repo: repo,
+	}
+}
+
+func pantryItemFromRow(row db.PantryItem) PantryItem {
+	item := PantryItem{
+		ID:        row.ID,
+		Name:      row.Name,
+		Quantity:  nullDecimalToPtr(row.Quantity),
+		Unit:      nullStringToPtr(row.Unit),
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	if row.ExpiresOn.Valid {
+		date := row.ExpiresOn.Time.Format(mealPlanDateLayout)
+		item.ExpiresOn = &date
+	}
+	return item
+}
+
+// ListPantryItems returns the client's items, soonest to expire first
+func (s *pantryService) ListPantryItems(ctx context.Context, clientID string) ([]PantryItem, error) {
+	if err := requireClientID(clientID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.ListPantryItems(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pantry items: %w", err)
+	}
+
+	items := make([]PantryItem, len(rows))
+	for i, row := range rows {
+		items[i] = pantryItemFromRow(row)
+	}
+
+	return items, nil
+}
+
+func (s *pantryService) CreatePantryItem(ctx context.Context, clientID string, req PantryItemRequest) (*PantryItem, error) {
+	if err := requireClientID(clientID); err != nil {
+		return nil, err
+	}
+
+	params, err := validatePantryItem(req)
+	if err != nil {
+		return nil, err
+	}
+	params.ClientID = clientID
+
+	id, err := s.repo.CreatePantryItem(ctx, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: pantry already has %q", ErrConflict, params.Name)
+		}
+		return nil, fmt.Errorf("failed to create pantry item: %w", err)
+	}
+
+	return s.getItem(ctx, clientID, int32(id))
+}
+
+// UpdatePantryItem replaces every field of an item
+func (s *pantryService) UpdatePantryItem(ctx context.Context, clientID string, id int32, req PantryItemRequest) (*PantryItem, error) {
+	if err := requireClientID(clientID); err != nil {
+		return nil, err
+	}
+
+	params, err := validatePantryItem(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getItem(ctx, clientID, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePantryItem(ctx, id, params); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: pantry already has %q", ErrConflict, params.Name)
+		}
+		return nil, fmt.Errorf("failed to update pantry item: %w", err)
+	}
+
+	return s.getItem(ctx, clientID, id)
+}
+
+func (s *pantryService) DeletePantryItem(ctx context.Context, clientID string, id int32) error {
+	if err := requireClientID(clientID); err != nil {
+		return err
+	}
+
+	if _, err := s.getItem(ctx, clientID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeletePantryItem(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete pantry item: %w", err)
+	}
+
+	return nil
+}
+
+// getItem loads one of the client's items. Another client's item is
+// reported as not found.
+func (s *pantryService) getItem(ctx context.Context, clientID string, id int32) (*PantryItem, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid pantry item ID", ErrInvalidParams)
+	}
+
+	row, err := s.repo.GetPantryItemByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPantryItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get pantry item: %w", err)
+	}
+	if row.ClientID != clientID {
+		return nil, ErrPantryItemNotFound
+	}
+
+	item := pantryItemFromRow(row)
+	return &item, nil
+}
+
+// requireClientID checks a client ID that must be present
+func requireClientID(clientID string) error {
+	if clientID == "" {
+		return fmt.Errorf("%w: a client ID is required", ErrInvalidParams)
+	}
+	return validateClientID(clientID)
+}
+
+// validatePantryItem normalises the name and unit and checks the quantity
+// and expiry date
+func validatePantryItem(req PantryItemRequest) (repository.PantryItemParams, error) {
+	name := foodName(req.Name)
+	if name == "" {
+		return repository.PantryItemParams{}, fmt.Errorf("%w: name is required", ErrInvalidParams)
+	}
+	if len(name) > maxPantryItemNameLength {
+		return repository.PantryItemParams{}, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidParams, maxPantryItemNameLength)
+	}
+	params := repository.PantryItemParams{Name: name}
+
+	if req.Quantity != nil {
+		if *req.Quantity < 0 || *req.Quantity > maxPantryQuantity {
+			return repository.PantryItemParams{}, fmt.Errorf("%w: quantity must be between 0 and %d", ErrInvalidParams, maxPantryQuantity)
+		}
+		params.Quantity = req.Quantity
+	}
+
+	if req.Unit != nil && strings.TrimSpace(*req.Unit) != "" {
+		if req.Quantity == nil {
+			return repository.PantryItemParams{}, fmt.Errorf("%w: unit needs a quantity", ErrInvalidParams)
+		}
+		unit := NormalizeUnit(*req.Unit)
+		if len(unit) > maxPantryUnitLength {
+			return repository.PantryItemParams{}, fmt.Errorf("%w: unit must be at most %d characters", ErrInvalidParams, maxPantryUnitLength)
+		}
+		params.Unit = &unit
+	}
+
+	if req.ExpiresOn != nil && strings.TrimSpace(*req.ExpiresOn) != "" {
+		date, err := parseMealPlanDate("expires_on", *req.ExpiresOn)
+		if err != nil {
+			return repository.PantryItemParams{}, err
+		}
+		params.ExpiresOn = &date
+	}
+
+	return params, nil
+}
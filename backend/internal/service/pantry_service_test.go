@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Mock repository for testing. items are keyed by ID; stocked and
+// expiring are what the date-based queries return for every client.
+type mockPantryRepository struct {
+	items        map[int32]db.PantryItem
+	stocked      []string
+	expiring     []string
+	expiringDays int32
+	created      *repository.PantryItemParams
+	updated      *repository.PantryItemParams
+	createErr    error
+	deleted      []int32
+}
+
+func (m *mockPantryRepository) ListPantryItems(ctx context.Context, clientID string) ([]db.PantryItem, error) {
+	items := []db.PantryItem{}
+	for _, item := range m.items {
+		if item.ClientID == clientID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (m *mockPantryRepository) GetPantryItemByID(ctx context.Context, id int32) (db.PantryItem, error) {
+	item, ok := m.items[id]
+	if !ok {
+		return db.PantryItem{}, sql.ErrNoRows
+	}
+	return item, nil
+}
+
+func (m *mockPantryRepository) CreatePantryItem(ctx context.Context, params repository.PantryItemParams) (int64, error) {
+	if m.createErr != nil {
+		return 0, m.createErr
+	}
+	m.created = &params
+	m.items[9] = db.PantryItem{ID: 9, ClientID: params.ClientID, Name: params.Name}
+	return 9, nil
+}
+
+func (m *mockPantryRepository) UpdatePantryItem(ctx context.Context, id int32, params repository.PantryItemParams) error {
+	m.updated = &params
+	return nil
+}
+
+func (m *mockPantryRepository) DeletePantryItem(ctx context.Context, id int32) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func (m *mockPantryRepository) ListStockedPantryItems(ctx context.Context, clientID string) ([]string, error) {
+	return m.stocked, nil
+}
+
+func (m *mockPantryRepository) ListExpiringPantryItems(ctx context.Context, clientID string, days int32) ([]string, error) {
+	m.expiringDays = days
+	return m.expiring, nil
+}
+
+func pantryItems() map[int32]db.PantryItem {
+	return map[int32]db.PantryItem{
+		1: {
+			ID: 1, ClientID: "kitchen-1", Name: "egg",
+			Quantity:  sql.NullString{String: "6.000", Valid: true},
+			ExpiresOn: sql.NullTime{Time: mealPlanDate("2026-01-20"), Valid: true},
+		},
+		2: {ID: 2, ClientID: "kitchen-2", Name: "rice"},
+	}
+}
+
+func TestListPantryItems(t *testing.T) {
+	service := NewPantryService(&mockPantryRepository{items: pantryItems()})
+
+	items, err := service.ListPantryItems(context.Background(), "kitchen-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(items) != 1 || items[0].Name != "egg" || *items[0].Quantity != 6 || *items[0].ExpiresOn != "2026-01-20" {
+		t.Errorf("Expected kitchen-1's 6 eggs expiring 2026-01-20, got %+v", items)
+	}
+
+	if _, err := service.ListPantryItems(context.Background(), ""); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams without a client ID, got %v", err)
+	}
+}
+
+func TestCreatePantryItem(t *testing.T) {
+	repo := &mockPantryRepository{items: pantryItems()}
+	service := NewPantryService(repo)
+
+	quantity := 2.0
+	unit := "Kilograms"
+	expires := "2026-01-25"
+	item, err := service.CreatePantryItem(context.Background(), "kitchen-1", PantryItemRequest{
+		Name:      " Chicken Breasts ",
+		Quantity:  &quantity,
+		Unit:      &unit,
+		ExpiresOn: &expires,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if item.ID != 9 {
+		t.Errorf("Expected the created item, got %+v", item)
+	}
+	created := repo.created
+	if created.ClientID != "kitchen-1" || created.Name != "chicken breast" || *created.Unit != "kg" || !created.ExpiresOn.Equal(mealPlanDate("2026-01-25")) {
+		t.Errorf("Expected a normalised item for kitchen-1, got %+v", created)
+	}
+
+	repo.createErr = repository.ErrDuplicate
+	if _, err := service.CreatePantryItem(context.Background(), "kitchen-1", PantryItemRequest{Name: "eggs"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for a duplicate, got %v", err)
+	}
+}
+
+func TestCreatePantryItem_InvalidParams(t *testing.T) {
+	service := NewPantryService(&mockPantryRepository{items: pantryItems()})
+
+	negative := -1.0
+	unit := "g"
+	badDate := "20/01/2026"
+	tests := []struct {
+		name     string
+		clientID string
+		req      PantryItemRequest
+	}{
+		{"no client", "", PantryItemRequest{Name: "egg"}},
+		{"invalid client", "kitchen 1", PantryItemRequest{Name: "egg"}},
+		{"no name", "kitchen-1", PantryItemRequest{Name: "  "}},
+		{"negative quantity", "kitchen-1", PantryItemRequest{Name: "egg", Quantity: &negative}},
+		{"unit without quantity", "kitchen-1", PantryItemRequest{Name: "flour", Unit: &unit}},
+		{"invalid expiry", "kitchen-1", PantryItemRequest{Name: "egg", ExpiresOn: &badDate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreatePantryItem(context.Background(), tt.clientID, tt.req)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPantryItem_OtherClient(t *testing.T) {
+	repo := &mockPantryRepository{items: pantryItems()}
+	service := NewPantryService(repo)
+
+	if _, err := service.UpdatePantryItem(context.Background(), "kitchen-1", 2, PantryItemRequest{Name: "rice"}); !errors.Is(err, ErrPantryItemNotFound) {
+		t.Errorf("Expected ErrPantryItemNotFound, got %v", err)
+	}
+	if err := service.DeletePantryItem(context.Background(), "kitchen-1", 2); !errors.Is(err, ErrPantryItemNotFound) {
+		t.Errorf("Expected ErrPantryItemNotFound, got %v", err)
+	}
+	if repo.updated != nil || len(repo.deleted) != 0 {
+		t.Error("Expected another client's item to be left alone")
+	}
+
+	if err := service.DeletePantryItem(context.Background(), "kitchen-2", 2); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestAnnotatePantry(t *testing.T) {
+	pantry := &mockPantryRepository{
+		stocked:  []string{"rice", "egg", "spinach"},
+		expiring: []string{"spinach"},
+	}
+	service := NewRecipesService(&mockRecipesRepository{}, WithPantry(pantry))
+
+	note := "to taste"
+	recipe := &Recipe{ID: 1, IngredientItems: []Ingredient{
+		{Name: "cooked rice"},
+		{Name: "Eggs"},
+		{Name: "spinach"},
+		{Name: "sweet soy sauce"},
+		{Name: "salt", Note: &note},
+	}}
+
+	if err := service.AnnotatePantry(context.Background(), "kitchen-1", recipe); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := &PantryStatus{
+		Have:     3,
+		Required: 4,
+		Missing:  []string{"sweet soy sauce"},
+		Expiring: []string{"spinach"},
+	}
+	if !reflect.DeepEqual(recipe.Pantry, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, recipe.Pantry)
+	}
+	if pantry.expiringDays != defaultExpiringDays {
+		t.Errorf("Expected items expiring within %d days, got %d", defaultExpiringDays, pantry.expiringDays)
+	}
+
+	if err := service.AnnotatePantry(context.Background(), "", recipe); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams without a client ID, got %v", err)
+	}
+}
+
+func TestGetRandomRecipe_Expiring(t *testing.T) {
+	// Recipe 2 uses one expiring item (in two ingredients) and recipe 3 two
+	mockRepo := &mockRecipesRepository{}
+	spinCatalog(mockRepo, []repository.SpinCandidate{{ID: 1}, {ID: 2, Expiring: 1}, {ID: 3, Expiring: 2}})
+	var sampled repository.SpinCandidatesParams
+	candidates := mockRepo.spinCandidatesFunc
+	mockRepo.spinCandidatesFunc = func(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error) {
+		sampled = params
+		return candidates(ctx, params)
+	}
+	service := NewRecipesService(mockRepo)
+
+	seed := int64(7)
+	result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{
+		ClientID: "kitchen-1",
+		Seed:     &seed,
+		Weights:  &SpinWeights{Expiring: 2, ExpiringDays: 5},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Weights 1, 3 and 5: seed 7 draws 0.92, which lands on recipe 3
+	if result.Recipe.ID != 3 || math.Abs(result.Probability-5.0/9) > 1e-9 {
+		t.Errorf("Expected recipe 3 with probability %v, got %d with %v", 5.0/9, result.Recipe.ID, result.Probability)
+	}
+	if sampled.ExpiringClientID != "kitchen-1" || sampled.ExpiringDays != 5 {
+		t.Errorf("Expected the client's items expiring within 5 days to be counted, got %q and %d", sampled.ExpiringClientID, sampled.ExpiringDays)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+func pantryIngredients() []db.RecipeIngredient {
+	note := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	return []db.RecipeIngredient{
+		// 1: Nasi Goreng
+		{RecipeID: 1, Position: 1, Name: "cooked rice"},
+		{RecipeID: 1, Position: 2, Name: "eggs"},
+		{RecipeID: 1, Position: 3, Name: "shallots"},
+		{RecipeID: 1, Position: 4, Name: "Salt and pepper", Note: note("to taste")},
+		// 2: Mie Goreng
+		{RecipeID: 2, Position: 1, Name: "egg noodles"},
+		{RecipeID: 2, Position: 2, Name: "eggs"},
+		{RecipeID: 2, Position: 3, Name: "sweet soy sauce"},
+		// 3: Telur Dadar
+		{RecipeID: 3, Position: 1, Name: "eggs"},
+		{RecipeID: 3, Position: 2, Name: "fried shallots", Note: note("for garnish")},
+	}
+}
+
+func TestSearchByPantry(t *testing.T) {
+	var loaded []int32
+	var pantry []string
+	var maxMissing int32
+	mockRepo := &mockRecipesRepository{
+		// Telur Dadar is fully covered and Nasi Goreng misses shallots
+		pantryMatchesFunc: func(ctx context.Context, items []string, missing, limit int32) ([]repository.PantryCoverage, error) {
+			pantry, maxMissing = items, missing
+			return []repository.PantryCoverage{
+				{RecipeID: 3, Required: 1},
+				{RecipeID: 1, Required: 3, Missing: 1},
+			}, nil
+		},
+		listIngredientsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error) {
+			return pantryIngredients(), nil
+		},
+		listByIDsFunc: func(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error) {
+			loaded = recipeIDs
+			rows := make([]repository.RecipeRow, len(recipeIDs))
+			for i, id := range recipeIDs {
+				rows[i] = repository.RecipeRow{ID: id}
+			}
+			return rows, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	result, err := service.SearchByPantry(context.Background(), PantrySearchRequest{
+		Ingredients: []string{"Rice", "egg", "chicken"},
+		MaxMissing:  1,
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(pantry, []string{"rice", "egg", "chicken"}) || maxMissing != 1 {
+		t.Fatalf("Expected the normalized pantry with max_missing 1, got %v and %d", pantry, maxMissing)
+	}
+	if !reflect.DeepEqual(loaded, []int32{3, 1}) {
+		t.Fatalf("Expected recipes [3 1], got %v", loaded)
+	}
+
+	nasi := result.Data[1]
+	if nasi.Required != 3 || nasi.Coverage < 0.66 || nasi.Coverage > 0.67 {
+		t.Errorf("Expected 2 of 3 required ingredients covered, got %+v", nasi)
+	}
+	if !reflect.DeepEqual(nasi.Matched, []string{"cooked rice", "eggs"}) ||
+		!reflect.DeepEqual(nasi.Missing, []string{"shallots"}) {
+		t.Errorf("Expected rice and eggs matched and shallots missing, got %v and %v", nasi.Matched, nasi.Missing)
+	}
+}
+
+func TestSearchByPantry_InvalidParams(t *testing.T) {
+	service := NewRecipesService(&mockRecipesRepository{})
+
+	tests := []PantrySearchRequest{
+		{},
+		{Ingredients: []string{" "}},
+		{Ingredients: []string{"rice"}, MaxMissing: -1},
+		{Ingredients: []string{"rice"}, MaxMissing: 21},
+	}
+
+	for _, req := range tests {
+		if _, err := service.SearchByPantry(context.Background(), req); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%+v: expected ErrInvalidParams, got %v", req, err)
+		}
+	}
+}
+
+func TestGetRandomRecipe_Pantry(t *testing.T) {
+	var captured repository.RecipeFilter
+	mockRepo := &mockRecipesRepository{
+		countRecipesFunc: func(ctx context.Context, filter repository.RecipeFilter) (int64, error) {
+			captured = filter
+			if filter.Pantry[0] == "tofu" {
+				return 0, nil
+			}
+			return 1, nil
+		},
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			return []repository.RecipeRow{{ID: 1}}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	_, err := service.GetRandomRecipe(context.Background(), RecipeFilters{
+		Pantry:     []string{"eggs", "cooked rice", "shallot"},
+		MaxMissing: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(captured.Pantry, []string{"egg", "cooked rice", "shallot"}) || captured.MaxMissing != 1 {
+		t.Errorf("Expected the spin filtered by the normalized pantry, got %v and %d", captured.Pantry, captured.MaxMissing)
+	}
+
+	_, err = service.GetRandomRecipe(context.Background(), RecipeFilters{Pantry: []string{"tofu"}})
+	if !errors.Is(err, ErrRecipeNotFound) {
+		t.Errorf("Expected ErrRecipeNotFound for an empty pantry match, got %v", err)
+	}
+}
+
+func TestPantryHas(t *testing.T) {
+	tests := []struct {
+		pantry     []string
+		ingredient string
+		want       bool
+	}{
+		{[]string{"rice"}, "cooked rice", true},
+		{[]string{"rice"}, "rice flour", false},
+		{[]string{"egg"}, "Eggs", true},
+		{[]string{"egg"}, "egg noodles", false},
+		{[]string{"chicken breast"}, "chicken", true},
+		{[]string{"tomato"}, "tomatoes", true},
+	}
+
+	for _, tt := range tests {
+		pantry, _ := normalizePantry(tt.pantry)
+		if got := pantryHas(pantry, tt.ingredient); got != tt.want {
+			t.Errorf("pantryHas(%v, %q): expected %v, got %v", tt.pantry, tt.ingredient, tt.want, got)
+		}
+	}
+}
+
+func TestIngredientMatch(t *testing.T) {
+	note := func(s string) *string { return &s }
+	tests := []struct {
+		item     Ingredient
+		food     string
+		optional bool
+	}{
+		{Ingredient{Name: "Shallots"}, "shallot", false},
+		{Ingredient{Name: "Cooked Rice"}, "cooked rice", false},
+		{Ingredient{Name: "Salt"}, "salt", true},
+		{Ingredient{Name: "fried shallots", Note: note("for garnish")}, "fried shallot", true},
+	}
+
+	for _, tt := range tests {
+		food, optional := IngredientMatch(tt.item)
+		if food != tt.food || optional != tt.optional {
+			t.Errorf("IngredientMatch(%q): expected %q, %v, got %q, %v", tt.item.Name, tt.food, tt.optional, food, optional)
+		}
+	}
+}
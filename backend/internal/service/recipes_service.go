@@ -5,6 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/sonyadriko/masakyuk/internal/repository"
 )
@@ -12,97 +16,250 @@ import (
 var (
 	ErrRecipeNotFound = errors.New("recipe not found")
 	ErrInvalidParams  = errors.New("invalid parameters")
+	ErrConflict       = errors.New("conflict")
 )
 
 // Recipe represents a recipe in the response
 type Recipe struct {
-	ID           int32   `json:"id"`
-	Title        string  `json:"title"`
-	Description  string  `json:"description"`
-	Ingredients  string  `json:"ingredients"`
-	Instructions string  `json:"instructions"`
-	CookingTime  int32   `json:"cooking_time"`
-	SkillLevel   string  `json:"skill_level"`
-	CategoryID   int32   `json:"category_id"`
-	CategoryName string  `json:"category_name"`
-	VariantID    int32   `json:"variant_id"`
-	VariantName  string  `json:"variant_name"`
-	ImageURL     *string `json:"image_url,omitempty"`
-	Servings     int32   `json:"servings"`
+	ID           int32    `json:"id"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Ingredients  string   `json:"ingredients"`
+	Instructions string   `json:"instructions"`
+	CookingTime  int32    `json:"cooking_time"`
+	SkillLevel   string   `json:"skill_level"`
+	CategoryID   int32    `json:"category_id"`
+	CategoryName string   `json:"category_name"`
+	ImageURL     *string  `json:"image_url,omitempty"`
+	Servings     int32    `json:"servings"`
+	Calories     *int32   `json:"calories,omitempty"`
+	Protein      *float64 `json:"protein,omitempty"`
+	Carbs        *float64 `json:"carbs,omitempty"`
+	Fat          *float64 `json:"fat,omitempty"`
+	HealthTags   []string `json:"health_tags"`
+	// Variants are the dietary variants the recipe fits, sorted by name
+	Variants []RecipeVariant `json:"variants"`
+	// Tags are free-form tag names, sorted
+	Tags []string `json:"tags"`
+	// Allergens are derived from the ingredient list
+	Allergens []string `json:"allergens"`
+
+	IngredientItems []Ingredient `json:"ingredient_items"`
+	// Steps are only loaded for single-recipe responses
+	Steps []Step `json:"steps,omitempty"`
+	// DietaryWarnings is only set by a create or update in warn mode
+	DietaryWarnings []DietaryViolation `json:"dietary_warnings,omitempty"`
+	// Pantry is only set when annotated with a client's stored pantry
+	Pantry *PantryStatus `json:"pantry,omitempty"`
+}
+
+// RecipeVariant is a dietary variant assigned to a recipe
+type RecipeVariant struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
 }
 
 // RecipesListResponse represents the response for listing recipes
 type RecipesListResponse struct {
 	Data []Recipe       `json:"data"`
 	Meta PaginationMeta `json:"meta"`
+	// Facets is present when facets were requested, keyed by facet name
+	Facets map[string][]FacetCount `json:"facets,omitempty"`
 }
 
 // PaginationMeta holds pagination metadata
 type PaginationMeta struct {
-	Total      int64 `json:"total"`
-	Page       int   `json:"page"`
-	PerPage    int   `json:"per_page"`
-	TotalPages int   `json:"total_pages"`
+	Total int64 `json:"total"`
+	// Page is omitted when the list was continued from a cursor, which
+	// ignores the page number
+	Page       int `json:"page,omitempty"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+	// NextCursor continues the list after this page in the same sort; it is
+	// empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // RecipeFilters holds filter parameters
 type RecipeFilters struct {
-	Search         *string
-	SkillLevel     *string
-	VariantID      *int32
-	CategoryID     *int32
+	Search *string
+	// SkillLevels, VariantIDs and CategoryIDs each match any of their values
+	SkillLevels    []string
+	VariantIDs     []int32
+	CategoryIDs    []int32
+	MinCookingTime *int32
 	MaxCookingTime *int32
-	Page           int
-	PerPage        int
+	MinCalories    *int32
+	MaxCalories    *int32
+	MinProtein     *float64
+	MaxCarbs       *float64
+	MaxFat         *float64
+	HealthTagsAny  []string
+	HealthTagsAll  []string
+	// TagsAny matches recipes with any of these tags, TagsAll those with
+	// every one
+	TagsAny []string
+	TagsAll []string
+	// ExcludeIngredients and ExcludeAllergens drop recipes containing them
+	ExcludeIngredients []string
+	ExcludeAllergens   []string
+	// Pantry limits spins to recipes cookable with these ingredients,
+	// missing at most MaxMissing required ones
+	Pantry     []string
+	MaxMissing int32
+	// ClientID identifies the caller in spin history; spins are recorded
+	// when it is set. ExcludeRecent avoids that client's recent spins.
+	ClientID      string
+	ExcludeRecent *RecentSpins
+	// Weights skews spins towards some recipes; nil spins uniformly
+	Weights *SpinWeights
+	// ExcludeRecipeIDs are recipes a spin must not land on, such as those
+	// already in a meal plan
+	ExcludeRecipeIDs []int32
+	// Seed makes a spin deterministic: the same seed over the same
+	// candidates lands on the same recipe. Nil picks a random seed.
+	Seed *int64
+	// Sort is one of the sortOptions keys; Order overrides its default
+	// direction with "asc" or "desc"
+	Sort  string
+	Order string
+	// Cursor is a next_cursor from a previous response. When set, the list
+	// continues after it and Page is ignored.
+	Cursor string
+	// Facets names the facet counts to return with a list
+	Facets  []string
+	Page    int
+	PerPage int
 }
 
 // CreateRecipeRequest holds data for creating a recipe
 type CreateRecipeRequest struct {
-	Title        string  `json:"title"`
-	Description  string  `json:"description"`
-	Ingredients  string  `json:"ingredients"`
-	Instructions string  `json:"instructions"`
-	CookingTime  int32   `json:"cooking_time"`
-	SkillLevel   string  `json:"skill_level"`
-	CategoryID   int32   `json:"category_id"`
-	VariantID    int32   `json:"variant_id"`
-	ImageURL     *string `json:"image_url,omitempty"`
-	Servings     int32   `json:"servings"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Ingredients  string   `json:"ingredients"`
+	Instructions string   `json:"instructions"`
+	CookingTime  int32    `json:"cooking_time"`
+	SkillLevel   string   `json:"skill_level"`
+	CategoryID   int32    `json:"category_id"`
+	ImageURL     *string  `json:"image_url,omitempty"`
+	Servings     int32    `json:"servings"`
+	Calories     *int32   `json:"calories,omitempty"`
+	Protein      *float64 `json:"protein,omitempty"`
+	Carbs        *float64 `json:"carbs,omitempty"`
+	Fat          *float64 `json:"fat,omitempty"`
+	HealthTags   []string `json:"health_tags,omitempty"`
+	// Tags are free-form; new ones are created on save
+	Tags []string `json:"tags,omitempty"`
+
+	// VariantIDs lists every variant the recipe fits (at least one).
+	// VariantID is the single-variant form older clients send; it is only
+	// used when VariantIDs is empty.
+	VariantIDs []int32 `json:"variant_ids"`
+	VariantID  *int32  `json:"variant_id,omitempty"`
+
+	// IngredientItems takes precedence over Ingredients; when it is empty
+	// the free-text Ingredients are parsed instead
+	IngredientItems []Ingredient `json:"ingredient_items,omitempty"`
+	// Steps take precedence over Instructions in the same way
+	Steps []Step `json:"steps,omitempty"`
+
+	// DietaryCheck is "strict" (default) to reject ingredients that
+	// conflict with the variants, or "warn" to save and report them
+	DietaryCheck string `json:"dietary_check,omitempty"`
 }
 
 // UpdateRecipeRequest holds data for updating a recipe
 type UpdateRecipeRequest struct {
-	Title        string  `json:"title"`
-	Description  string  `json:"description"`
-	Ingredients  string  `json:"ingredients"`
-	Instructions string  `json:"instructions"`
-	CookingTime  int32   `json:"cooking_time"`
-	SkillLevel   string  `json:"skill_level"`
-	CategoryID   int32   `json:"category_id"`
-	VariantID    int32   `json:"variant_id"`
-	ImageURL     *string `json:"image_url,omitempty"`
-	Servings     int32   `json:"servings"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Ingredients  string   `json:"ingredients"`
+	Instructions string   `json:"instructions"`
+	CookingTime  int32    `json:"cooking_time"`
+	SkillLevel   string   `json:"skill_level"`
+	CategoryID   int32    `json:"category_id"`
+	ImageURL     *string  `json:"image_url,omitempty"`
+	Servings     int32    `json:"servings"`
+	Calories     *int32   `json:"calories,omitempty"`
+	Protein      *float64 `json:"protein,omitempty"`
+	Carbs        *float64 `json:"carbs,omitempty"`
+	Fat          *float64 `json:"fat,omitempty"`
+	HealthTags   []string `json:"health_tags,omitempty"`
+	// Tags are free-form; new ones are created on save
+	Tags []string `json:"tags,omitempty"`
+
+	// VariantIDs lists every variant the recipe fits (at least one).
+	// VariantID is the single-variant form older clients send; it is only
+	// used when VariantIDs is empty.
+	VariantIDs []int32 `json:"variant_ids"`
+	VariantID  *int32  `json:"variant_id,omitempty"`
+
+	// IngredientItems takes precedence over Ingredients; when it is empty
+	// the free-text Ingredients are parsed instead
+	IngredientItems []Ingredient `json:"ingredient_items,omitempty"`
+	// Steps take precedence over Instructions in the same way
+	Steps []Step `json:"steps,omitempty"`
+
+	// DietaryCheck is "strict" (default) to reject ingredients that
+	// conflict with the variants, or "warn" to save and report them
+	DietaryCheck string `json:"dietary_check,omitempty"`
 }
 
 // RecipesService defines the interface for recipe business logic
 type RecipesService interface {
 	ListRecipes(ctx context.Context, filters RecipeFilters) (*RecipesListResponse, error)
 	GetRecipeByID(ctx context.Context, id int32) (*Recipe, error)
-	GetRandomRecipe(ctx context.Context, filters RecipeFilters) (*Recipe, error)
+	ScaleRecipe(ctx context.Context, id int32, servings int32) (*Recipe, error)
+	GetRandomRecipe(ctx context.Context, filters RecipeFilters) (*SpinResult, error)
+	GenerateMealPlan(ctx context.Context, req MealPlanRequest) (*MealPlan, error)
+	RerollMealPlanSlot(ctx context.Context, req MealPlanRerollRequest) (*MealPlan, error)
+	SearchByPantry(ctx context.Context, req PantrySearchRequest) (*PantrySearchResponse, error)
+	BuildShoppingList(ctx context.Context, req ShoppingListRequest) (*ShoppingList, error)
+	AnnotatePantry(ctx context.Context, clientID string, recipes ...*Recipe) error
+	AuditDietary(ctx context.Context) (*DietaryAuditResponse, error)
 	CreateRecipe(ctx context.Context, req CreateRecipeRequest) (*Recipe, error)
 	UpdateRecipe(ctx context.Context, id int32, req UpdateRecipeRequest) (*Recipe, error)
-	DeleteRecipe(ctx context.Context, id int32) error
+	DeleteRecipe(ctx context.Context, id int32) (int64, error)
 }
 
 type recipesService struct {
 	repo repository.RecipesRepository
+	// pantry holds clients' stored pantries; nil treats them as empty
+	pantry repository.PantryRepository
+	// spinSeed picks the seed of spins that do not give one
+	spinSeed func() int64
+}
+
+// RecipesServiceOption configures a recipes service
+type RecipesServiceOption func(*recipesService)
+
+// WithSpinSeeds sets the source of seeds for spins that do not give one,
+// which otherwise come from math/rand. Values are reduced to the range
+// accepted for seeds.
+func WithSpinSeeds(next func() int64) RecipesServiceOption {
+	return func(s *recipesService) {
+		s.spinSeed = next
+	}
+}
+
+// WithPantry gives the service clients' stored pantries, used to annotate
+// recipes and to weight spins towards items expiring soon. Without it every
+// pantry is empty.
+func WithPantry(pantry repository.PantryRepository) RecipesServiceOption {
+	return func(s *recipesService) {
+		s.pantry = pantry
+	}
 }
 
 // NewRecipesService creates a new recipes service
-func NewRecipesService(repo repository.RecipesRepository) RecipesService {
-	return &recipesService{
-		repo: repo,
+func NewRecipesService(repo repository.RecipesRepository, opts ...RecipesServiceOption) RecipesService {
+	s := &recipesService{
+		repo:     repo,
+		spinSeed: rand.Int63,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *recipesService) ListRecipes(ctx context.Context, filters RecipeFilters) (*RecipesListResponse, error) {
@@ -114,59 +271,73 @@ func (s *recipesService) ListRecipes(ctx context.Context, filters RecipeFilters)
 		filters.PerPage = 10
 	}
 
-	// Validate skill level if provided
-	if filters.SkillLevel != nil && !isValidSkillLevel(*filters.SkillLevel) {
-		return nil, fmt.Errorf("%w: invalid skill_level", ErrInvalidParams)
+	if err := validateFilters(&filters); err != nil {
+		return nil, err
+	}
+
+	ordering, err := recipeSort(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if filters.Facets, err = normalizeFacets(filters.Facets); err != nil {
+		return nil, err
+	}
+
+	var after *repository.RecipeCursor
+	if filters.Cursor != "" {
+		if after, err = decodeCursor(filters.Cursor, ordering); err != nil {
+			return nil, err
+		}
 	}
 
 	// Calculate offset
 	offset := int32((filters.Page - 1) * filters.PerPage)
 	limit := int32(filters.PerPage)
 
+	filter := toRecipeFilter(filters)
+
 	// Get total count
-	count, err := s.repo.CountRecipes(ctx, repository.CountRecipesParams{
-		Search:         filters.Search,
-		SkillLevel:     filters.SkillLevel,
-		VariantID:      filters.VariantID,
-		CategoryID:     filters.CategoryID,
-		MaxCookingTime: filters.MaxCookingTime,
-	})
+	count, err := s.repo.CountRecipes(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count recipes: %w", err)
 	}
 
-	// Get recipes
+	var facets map[string][]FacetCount
+	if len(filters.Facets) > 0 {
+		if facets, err = s.countFacets(ctx, filters.Facets, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get recipes, fetching one extra row to learn whether a next page exists
 	rows, err := s.repo.ListRecipes(ctx, repository.ListRecipesParams{
-		Search:         filters.Search,
-		SkillLevel:     filters.SkillLevel,
-		VariantID:      filters.VariantID,
-		CategoryID:     filters.CategoryID,
-		MaxCookingTime: filters.MaxCookingTime,
-		Limit:          limit,
-		Offset:         offset,
+		RecipeFilter: filter,
+		Sort:         ordering,
+		After:        after,
+		Limit:        limit + 1,
+		Offset:       offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list recipes: %w", err)
 	}
 
+	var nextCursor string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		nextCursor = encodeCursor(ordering, rows[len(rows)-1])
+	}
+
 	// Convert to response format
 	recipes := make([]Recipe, len(rows))
+	refs := make([]*Recipe, len(rows))
 	for i, row := range rows {
-		recipes[i] = Recipe{
-			ID:           row.ID,
-			Title:        row.Title,
-			Description:  row.Description,
-			Ingredients:  row.Ingredients,
-			Instructions: row.Instructions,
-			CookingTime:  row.CookingTime,
-			SkillLevel:   row.SkillLevel,
-			CategoryID:   row.CategoryID,
-			CategoryName: row.CategoryName,
-			VariantID:    row.VariantID,
-			VariantName:  row.VariantName,
-			ImageURL:     nullStringToPtr(row.ImageUrl),
-			Servings:     row.Servings,
-		}
+		recipes[i] = recipeFromRow(row)
+		refs[i] = &recipes[i]
+	}
+
+	if err := s.enrichRecipes(ctx, refs...); err != nil {
+		return nil, err
 	}
 
 	// Calculate total pages
@@ -175,14 +346,21 @@ func (s *recipesService) ListRecipes(ctx context.Context, filters RecipeFilters)
 		totalPages++
 	}
 
+	page := filters.Page
+	if after != nil {
+		page = 0
+	}
+
 	return &RecipesListResponse{
 		Data: recipes,
 		Meta: PaginationMeta{
 			Total:      count,
-			Page:       filters.Page,
+			Page:       page,
 			PerPage:    filters.PerPage,
 			TotalPages: totalPages,
+			NextCursor: nextCursor,
 		},
+		Facets: facets,
 	}, nil
 }
 
@@ -196,7 +374,7 @@ func (s *recipesService) GetRecipeByID(ctx context.Context, id int32) (*Recipe,
 		return nil, fmt.Errorf("%w: %v", ErrRecipeNotFound, err)
 	}
 
-	return &Recipe{
+	recipe := &Recipe{
 		ID:           row.ID,
 		Title:        row.Title,
 		Description:  row.Description,
@@ -206,31 +384,234 @@ func (s *recipesService) GetRecipeByID(ctx context.Context, id int32) (*Recipe,
 		SkillLevel:   row.SkillLevel,
 		CategoryID:   row.CategoryID,
 		CategoryName: row.CategoryName,
-		VariantID:    row.VariantID,
-		VariantName:  row.VariantName,
 		ImageURL:     nullStringToPtr(row.ImageUrl),
 		Servings:     row.Servings,
-	}, nil
+		Calories:     nullInt32ToPtr(row.Calories),
+		Protein:      nullDecimalToPtr(row.Protein),
+		Carbs:        nullDecimalToPtr(row.Carbs),
+		Fat:          nullDecimalToPtr(row.Fat),
+	}
+
+	if err := s.enrichRecipes(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSteps(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
 }
 
-func (s *recipesService) GetRandomRecipe(ctx context.Context, filters RecipeFilters) (*Recipe, error) {
-	// Validate skill level if provided
-	if filters.SkillLevel != nil && !isValidSkillLevel(*filters.SkillLevel) {
-		return nil, fmt.Errorf("%w: invalid skill_level", ErrInvalidParams)
+// ScaleRecipe returns the recipe with ingredient quantities rescaled from
+// its stored servings to the requested servings. Nutrition is per serving
+// and stays the same.
+func (s *recipesService) ScaleRecipe(ctx context.Context, id int32, servings int32) (*Recipe, error) {
+	if servings < 1 || servings > maxScaledServings {
+		return nil, fmt.Errorf("%w: servings must be between 1 and %d", ErrInvalidParams, maxScaledServings)
 	}
 
-	row, err := s.repo.GetRandomRecipe(ctx, repository.GetRandomRecipeParams{
-		Search:         filters.Search,
-		SkillLevel:     filters.SkillLevel,
-		VariantID:      filters.VariantID,
-		CategoryID:     filters.CategoryID,
-		MaxCookingTime: filters.MaxCookingTime,
-	})
+	recipe, err := s.GetRecipeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if recipe.Servings < 1 {
+		return nil, fmt.Errorf("%w: recipe has no servings to scale from", ErrInvalidParams)
+	}
+	if recipe.Servings == servings {
+		return recipe, nil
+	}
+
+	factor := float64(servings) / float64(recipe.Servings)
+	recipe.IngredientItems = ScaleIngredients(recipe.IngredientItems, factor)
+	recipe.Ingredients = FormatIngredients(recipe.IngredientItems)
+	recipe.Servings = servings
+
+	return recipe, nil
+}
+
+func (s *recipesService) GetRandomRecipe(ctx context.Context, filters RecipeFilters) (*SpinResult, error) {
+	if err := validateFilters(&filters); err != nil {
+		return nil, err
+	}
+	if err := validateSpinHistory(&filters); err != nil {
+		return nil, err
+	}
+	if err := validateSpinWeights(&filters); err != nil {
+		return nil, err
+	}
+	seed, err := s.resolveSpinSeed(filters.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	recent, err := s.recentRecipeIDs(ctx, filters.ClientID, filters.ExcludeRecent)
+	if err != nil {
+		return nil, err
+	}
+
+	penalized, err := s.penalizedRecipes(ctx, filters)
 	if err != nil {
+		return nil, err
+	}
+
+	filter, err := candidateFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, count, repeated, err := s.avoidRecent(ctx, filter, recent)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
 		return nil, fmt.Errorf("%w: no recipes match the criteria", ErrRecipeNotFound)
 	}
 
-	return &Recipe{
+	// A plain spin reads the drawn recipe straight from the matches; a
+	// weighted one needs the candidates' columns to compute the weights
+	var row repository.RecipeRow
+	probability, candidates := 1/float64(count), int(count)
+	if filters.Weights == nil {
+		row, err = s.pickRecipe(ctx, filter, count, spinDraw(seed))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		params := repository.SpinCandidatesParams{RecipeFilter: filter, Seed: seed}
+		if filters.Weights.Expiring > 0 {
+			params.ExpiringClientID = filters.ClientID
+			params.ExpiringDays = filters.Weights.ExpiringDays
+		}
+		pool, err := s.candidatePool(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if len(pool) == 0 {
+			return nil, fmt.Errorf("%w: no recipes match the criteria", ErrRecipeNotFound)
+		}
+
+		var chosen int
+		chosen, probability = drawWeighted(spinWeights(pool, filters.Weights, penalized), spinDraw(seed))
+		candidates = len(pool)
+
+		rows, err := s.repo.ListRecipesByIDs(ctx, []int32{pool[chosen].ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load recipe: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("%w: recipe not found", ErrRecipeNotFound)
+		}
+		row = rows[0]
+	}
+
+	if filters.ClientID != "" {
+		if err := s.repo.RecordSpin(ctx, filters.ClientID, row.ID); err != nil {
+			return nil, fmt.Errorf("failed to record spin: %w", err)
+		}
+	}
+
+	recipe := new(Recipe)
+	*recipe = recipeFromRow(row)
+
+	if err := s.enrichRecipes(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSteps(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	return &SpinResult{
+		Recipe:       recipe,
+		Probability:  probability,
+		Candidates:   candidates,
+		RecentRepeat: repeated,
+		Seed:         seed,
+	}, nil
+}
+
+// sortOptions maps the sort query values to a column and its default
+// direction
+var sortOptions = map[string]repository.RecipeSort{
+	"newest":       {Field: repository.SortCreatedAt, Desc: true},
+	"oldest":       {Field: repository.SortCreatedAt},
+	"cooking_time": {Field: repository.SortCookingTime},
+	"title":        {Field: repository.SortTitle},
+	"calories":     {Field: repository.SortCalories},
+	"relevance":    {Field: repository.SortRelevance, Desc: true},
+}
+
+// recipeSort resolves the requested sort. Searches default to relevance,
+// everything else to newest first.
+func recipeSort(filters RecipeFilters) (repository.RecipeSort, error) {
+	searching := filters.Search != nil && strings.TrimSpace(*filters.Search) != ""
+
+	key := strings.ToLower(strings.TrimSpace(filters.Sort))
+	if key == "" {
+		key = "newest"
+		if searching {
+			key = "relevance"
+		}
+	}
+
+	ordering, ok := sortOptions[key]
+	if !ok {
+		return ordering, fmt.Errorf("%w: unknown sort %q (valid: %s)", ErrInvalidParams, key, strings.Join(sortKeys(), ", "))
+	}
+	if ordering.Field == repository.SortRelevance && !searching {
+		return ordering, fmt.Errorf("%w: sort=relevance requires search", ErrInvalidParams)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(filters.Order)) {
+	case "":
+	case "asc":
+		ordering.Desc = false
+	case "desc":
+		ordering.Desc = true
+	default:
+		return ordering, fmt.Errorf("%w: order must be asc or desc", ErrInvalidParams)
+	}
+	return ordering, nil
+}
+
+func sortKeys() []string {
+	keys := make([]string, 0, len(sortOptions))
+	for key := range sortOptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toRecipeFilter maps validated filters to the repository filter shared by
+// list, count and spin
+func toRecipeFilter(filters RecipeFilters) repository.RecipeFilter {
+	return repository.RecipeFilter{
+		Search:             filters.Search,
+		SkillLevels:        filters.SkillLevels,
+		VariantIDs:         filters.VariantIDs,
+		CategoryIDs:        filters.CategoryIDs,
+		MinCookingTime:     filters.MinCookingTime,
+		MaxCookingTime:     filters.MaxCookingTime,
+		MinCalories:        filters.MinCalories,
+		MaxCalories:        filters.MaxCalories,
+		MinProtein:         filters.MinProtein,
+		MaxCarbs:           filters.MaxCarbs,
+		MaxFat:             filters.MaxFat,
+		HealthTagsAny:      filters.HealthTagsAny,
+		HealthTagsAll:      filters.HealthTagsAll,
+		TagsAny:            filters.TagsAny,
+		TagsAll:            filters.TagsAll,
+		ExcludeIngredients: filters.ExcludeIngredients,
+		ExcludeAllergens:   filters.ExcludeAllergens,
+	}
+}
+
+// recipeFromRow converts a filtered query row to the response format
+func recipeFromRow(row repository.RecipeRow) Recipe {
+	return Recipe{
 		ID:           row.ID,
 		Title:        row.Title,
 		Description:  row.Description,
@@ -240,11 +621,13 @@ func (s *recipesService) GetRandomRecipe(ctx context.Context, filters RecipeFilt
 		SkillLevel:   row.SkillLevel,
 		CategoryID:   row.CategoryID,
 		CategoryName: row.CategoryName,
-		VariantID:    row.VariantID,
-		VariantName:  row.VariantName,
-		ImageURL:     nullStringToPtr(row.ImageUrl),
+		ImageURL:     nullStringToPtr(row.ImageURL),
 		Servings:     row.Servings,
-	}, nil
+		Calories:     nullInt32ToPtr(row.Calories),
+		Protein:      nullDecimalToPtr(row.Protein),
+		Carbs:        nullDecimalToPtr(row.Carbs),
+		Fat:          nullDecimalToPtr(row.Fat),
+	}
 }
 
 func isValidSkillLevel(level string) bool {
@@ -264,6 +647,508 @@ func nullStringToPtr(ns sql.NullString) *string {
 	return &ns.String
 }
 
+// Helper function to convert sql.NullInt32 to *int32
+func nullInt32ToPtr(ni sql.NullInt32) *int32 {
+	if !ni.Valid {
+		return nil
+	}
+	return &ni.Int32
+}
+
+// Helper function to convert a DECIMAL column (scanned as string) to *float64
+func nullDecimalToPtr(ns sql.NullString) *float64 {
+	if !ns.Valid {
+		return nil
+	}
+	f, err := strconv.ParseFloat(ns.String, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// enrichRecipes loads the data that lives outside the recipes table
+func (s *recipesService) enrichRecipes(ctx context.Context, recipes ...*Recipe) error {
+	if err := s.attachVariants(ctx, recipes...); err != nil {
+		return err
+	}
+	if err := s.attachHealthTags(ctx, recipes...); err != nil {
+		return err
+	}
+	if err := s.attachTags(ctx, recipes...); err != nil {
+		return err
+	}
+	if err := s.attachAllergens(ctx, recipes...); err != nil {
+		return err
+	}
+	return s.attachIngredients(ctx, recipes...)
+}
+
+// attachSteps loads instruction steps and their ingredient references
+func (s *recipesService) attachSteps(ctx context.Context, recipes ...*Recipe) error {
+	ids := make([]int32, len(recipes))
+	byID := make(map[int32]*Recipe, len(recipes))
+	for i, recipe := range recipes {
+		recipe.Steps = []Step{}
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+	}
+
+	rows, err := s.repo.ListRecipeSteps(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load steps: %w", err)
+	}
+
+	refs, err := s.repo.ListRecipeStepIngredients(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load step ingredients: %w", err)
+	}
+
+	refsByStep := make(map[int32][]int32)
+	for _, ref := range refs {
+		refsByStep[ref.StepID] = append(refsByStep[ref.StepID], ref.IngredientPosition)
+	}
+
+	for _, row := range rows {
+		if recipe, ok := byID[row.RecipeID]; ok {
+			stepRefs := refsByStep[row.ID]
+			if stepRefs == nil {
+				stepRefs = []int32{}
+			}
+			recipe.Steps = append(recipe.Steps, Step{
+				Position:           row.Position,
+				Text:               row.Instruction,
+				DurationSeconds:    nullInt32ToPtr(row.DurationSeconds),
+				TemperatureCelsius: nullInt32ToPtr(row.TemperatureCelsius),
+				IngredientRefs:     stepRefs,
+			})
+		}
+	}
+
+	return nil
+}
+
+// attachIngredients loads structured ingredients for the given recipes
+func (s *recipesService) attachIngredients(ctx context.Context, recipes ...*Recipe) error {
+	ids := make([]int32, len(recipes))
+	byID := make(map[int32]*Recipe, len(recipes))
+	for i, recipe := range recipes {
+		recipe.IngredientItems = []Ingredient{}
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+	}
+
+	rows, err := s.repo.ListRecipeIngredients(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load ingredients: %w", err)
+	}
+
+	for _, row := range rows {
+		if recipe, ok := byID[row.RecipeID]; ok {
+			recipe.IngredientItems = append(recipe.IngredientItems, Ingredient{
+				Quantity: nullDecimalToPtr(row.Quantity),
+				Unit:     nullStringToPtr(row.Unit),
+				Name:     row.Name,
+				Note:     nullStringToPtr(row.Note),
+				Group:    nullStringToPtr(row.GroupName),
+			})
+		}
+	}
+
+	return nil
+}
+
+// attachVariants loads the dietary variants of the given recipes
+func (s *recipesService) attachVariants(ctx context.Context, recipes ...*Recipe) error {
+	ids := make([]int32, len(recipes))
+	byID := make(map[int32]*Recipe, len(recipes))
+	for i, recipe := range recipes {
+		recipe.Variants = []RecipeVariant{}
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+	}
+
+	rows, err := s.repo.ListRecipeVariants(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load variants: %w", err)
+	}
+
+	for _, row := range rows {
+		if recipe, ok := byID[row.RecipeID]; ok {
+			recipe.Variants = append(recipe.Variants, RecipeVariant{ID: row.VariantID, Name: row.Name})
+		}
+	}
+
+	return nil
+}
+
+// attachTags loads the tags of the given recipes
+func (s *recipesService) attachTags(ctx context.Context, recipes ...*Recipe) error {
+	ids := make([]int32, len(recipes))
+	byID := make(map[int32]*Recipe, len(recipes))
+	for i, recipe := range recipes {
+		recipe.Tags = []string{}
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+	}
+
+	rows, err := s.repo.ListRecipeTags(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load tags: %w", err)
+	}
+
+	for _, row := range rows {
+		if recipe, ok := byID[row.RecipeID]; ok {
+			recipe.Tags = append(recipe.Tags, row.Name)
+		}
+	}
+
+	return nil
+}
+
+// attachAllergens loads detected allergens for the given recipes
+func (s *recipesService) attachAllergens(ctx context.Context, recipes ...*Recipe) error {
+	ids := make([]int32, len(recipes))
+	byID := make(map[int32]*Recipe, len(recipes))
+	for i, recipe := range recipes {
+		recipe.Allergens = []string{}
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+	}
+
+	rows, err := s.repo.ListRecipeAllergens(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load allergens: %w", err)
+	}
+
+	for _, row := range rows {
+		if recipe, ok := byID[row.RecipeID]; ok {
+			recipe.Allergens = append(recipe.Allergens, row.Allergen)
+		}
+	}
+
+	return nil
+}
+
+// attachHealthTags loads health tags for the given recipes in a single query
+func (s *recipesService) attachHealthTags(ctx context.Context, recipes ...*Recipe) error {
+	ids := make([]int32, len(recipes))
+	byID := make(map[int32]*Recipe, len(recipes))
+	for i, recipe := range recipes {
+		recipe.HealthTags = []string{}
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+	}
+
+	rows, err := s.repo.ListRecipeHealthTags(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load health tags: %w", err)
+	}
+
+	for _, row := range rows {
+		if recipe, ok := byID[row.RecipeID]; ok {
+			recipe.HealthTags = append(recipe.HealthTags, row.Tag)
+		}
+	}
+
+	return nil
+}
+
+// Nutrition bounds are per serving
+const (
+	maxCalories = 5000
+	maxMacroG   = 1000
+)
+
+func isValidHealthTag(tag string) bool {
+	validTags := map[string]bool{
+		"low-carb":      true,
+		"high-protein":  true,
+		"low-fat":       true,
+		"low-calorie":   true,
+		"high-fiber":    true,
+		"low-sodium":    true,
+		"low-sugar":     true,
+		"keto":          true,
+		"heart-healthy": true,
+	}
+	return validTags[tag]
+}
+
+// normalizeIngredients returns the structured ingredient list for a
+// create/update request together with its rendered free-text form
+func normalizeIngredients(text string, items []Ingredient) (string, []Ingredient, error) {
+	if len(items) == 0 {
+		items = ParseIngredients(text)
+	}
+
+	cleaned := make([]Ingredient, 0, len(items))
+	grouped := false
+	for _, item := range items {
+		item.Name = strings.TrimSpace(item.Name)
+		if item.Name == "" {
+			return "", nil, fmt.Errorf("%w: every ingredient needs a name", ErrInvalidParams)
+		}
+		if item.Group != nil {
+			if group := strings.TrimSpace(*item.Group); group != "" {
+				item.Group = &group
+			} else {
+				item.Group = nil
+			}
+		}
+		// The rendered text has no way to end a group, so an ungrouped
+		// item after a grouped one would join that group when re-parsed
+		if item.Group != nil {
+			grouped = true
+		} else if grouped {
+			return "", nil, fmt.Errorf("%w: ungrouped ingredients must come before grouped ones", ErrInvalidParams)
+		}
+		if item.Quantity != nil && *item.Quantity < 0 {
+			return "", nil, fmt.Errorf("%w: ingredient quantity must not be negative", ErrInvalidParams)
+		}
+		if item.Unit != nil {
+			if unit := NormalizeUnit(*item.Unit); unit != "" {
+				item.Unit = &unit
+			} else {
+				item.Unit = nil
+			}
+		}
+		cleaned = append(cleaned, item)
+	}
+
+	if len(cleaned) == 0 {
+		return "", nil, fmt.Errorf("%w: title, description, ingredients, and instructions are required", ErrInvalidParams)
+	}
+
+	return FormatIngredients(cleaned), cleaned, nil
+}
+
+// normalizeSteps returns the instruction steps for a create/update request
+// together with their rendered text. ingredients is the already normalized
+// ingredient list, used to parse and validate ingredient references.
+func normalizeSteps(text string, steps []Step, ingredients []Ingredient) (string, []Step, error) {
+	if len(steps) == 0 {
+		steps = ParseInstructions(text, ingredients)
+	}
+
+	cleaned := make([]Step, 0, len(steps))
+	for _, step := range steps {
+		step.Text = strings.TrimSpace(step.Text)
+		if step.Text == "" {
+			return "", nil, fmt.Errorf("%w: every step needs text", ErrInvalidParams)
+		}
+		if step.DurationSeconds != nil && *step.DurationSeconds < 0 {
+			return "", nil, fmt.Errorf("%w: step duration must not be negative", ErrInvalidParams)
+		}
+		for _, ref := range step.IngredientRefs {
+			if ref < 1 || int(ref) > len(ingredients) {
+				return "", nil, fmt.Errorf("%w: step references unknown ingredient %d", ErrInvalidParams, ref)
+			}
+		}
+		if step.IngredientRefs == nil {
+			step.IngredientRefs = []int32{}
+		}
+		step.Position = int32(len(cleaned) + 1)
+		cleaned = append(cleaned, step)
+	}
+
+	if len(cleaned) == 0 {
+		return "", nil, fmt.Errorf("%w: title, description, ingredients, and instructions are required", ErrInvalidParams)
+	}
+
+	return FormatInstructions(cleaned), cleaned, nil
+}
+
+// toStepParams converts instruction steps for the repository
+func toStepParams(steps []Step) []repository.StepParams {
+	params := make([]repository.StepParams, len(steps))
+	for i, step := range steps {
+		params[i] = repository.StepParams{
+			Instruction:         step.Text,
+			DurationSeconds:     step.DurationSeconds,
+			TemperatureCelsius:  step.TemperatureCelsius,
+			IngredientPositions: step.IngredientRefs,
+		}
+	}
+	return params
+}
+
+// toIngredientParams converts structured ingredients for the repository
+func toIngredientParams(items []Ingredient) []repository.IngredientParams {
+	params := make([]repository.IngredientParams, len(items))
+	for i, item := range items {
+		params[i] = repository.IngredientParams{
+			Quantity: item.Quantity,
+			Unit:     item.Unit,
+			Name:     item.Name,
+			Note:     item.Note,
+			Group:    item.Group,
+		}
+		params[i].FoodName, params[i].Optional = IngredientMatch(item)
+	}
+	return params
+}
+
+// normalizeHealthTags lowercases, trims and de-duplicates tags, rejecting
+// anything outside the health tag vocabulary
+func normalizeHealthTags(healthTags []string) ([]string, error) {
+	tags := make([]string, 0, len(healthTags))
+	seen := make(map[string]bool, len(healthTags))
+	for _, tag := range healthTags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if !isValidHealthTag(tag) {
+			return nil, fmt.Errorf("%w: unknown health tag %q", ErrInvalidParams, tag)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// normalizeRecipeTags normalizes the tags saved with a recipe and caps
+// their number
+func normalizeRecipeTags(tags []string) ([]string, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > maxRecipeTags {
+		return nil, fmt.Errorf("%w: a recipe can have at most %d tags", ErrInvalidParams, maxRecipeTags)
+	}
+	return tags, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []int32) []int32 {
+	if len(ids) == 0 {
+		return nil
+	}
+	unique := make([]int32, 0, len(ids))
+	seen := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// normalizeVariantIDs de-duplicates a recipe's variants, falling back to
+// the legacy single variant, and requires at least one
+func normalizeVariantIDs(ids []int32, legacy *int32) ([]int32, error) {
+	if len(ids) == 0 && legacy != nil {
+		ids = []int32{*legacy}
+	}
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one variant is required", ErrInvalidParams)
+	}
+	for _, id := range ids {
+		if id < 1 {
+			return nil, fmt.Errorf("%w: invalid variant ID %d", ErrInvalidParams, id)
+		}
+	}
+	return ids, nil
+}
+
+// validateFilters checks list/spin filters and normalizes health tags in place
+func validateFilters(filters *RecipeFilters) error {
+	// Validate skill levels if provided
+	levels := make([]string, 0, len(filters.SkillLevels))
+	seen := make(map[string]bool, len(filters.SkillLevels))
+	for _, level := range filters.SkillLevels {
+		level = strings.ToLower(strings.TrimSpace(level))
+		if !isValidSkillLevel(level) {
+			return fmt.Errorf("%w: invalid skill_level", ErrInvalidParams)
+		}
+		if !seen[level] {
+			seen[level] = true
+			levels = append(levels, level)
+		}
+	}
+	filters.SkillLevels = levels
+	filters.VariantIDs = uniqueIDs(filters.VariantIDs)
+	filters.CategoryIDs = uniqueIDs(filters.CategoryIDs)
+
+	if (filters.MinCookingTime != nil && *filters.MinCookingTime < 0) || (filters.MaxCookingTime != nil && *filters.MaxCookingTime < 0) {
+		return fmt.Errorf("%w: cooking time bounds must not be negative", ErrInvalidParams)
+	}
+	if filters.MinCookingTime != nil && filters.MaxCookingTime != nil && *filters.MinCookingTime > *filters.MaxCookingTime {
+		return fmt.Errorf("%w: min_cooking_time cannot exceed max_cooking_time", ErrInvalidParams)
+	}
+
+	if (filters.MinCalories != nil && *filters.MinCalories < 0) || (filters.MaxCalories != nil && *filters.MaxCalories < 0) {
+		return fmt.Errorf("%w: calorie bounds must not be negative", ErrInvalidParams)
+	}
+	if filters.MinCalories != nil && filters.MaxCalories != nil && *filters.MinCalories > *filters.MaxCalories {
+		return fmt.Errorf("%w: min_calories cannot exceed max_calories", ErrInvalidParams)
+	}
+
+	bounds := []struct {
+		name  string
+		value *float64
+	}{
+		{"min_protein", filters.MinProtein},
+		{"max_carbs", filters.MaxCarbs},
+		{"max_fat", filters.MaxFat},
+	}
+	for _, b := range bounds {
+		if b.value != nil && *b.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidParams, b.name)
+		}
+	}
+
+	var err error
+	if filters.HealthTagsAny, err = normalizeHealthTags(filters.HealthTagsAny); err != nil {
+		return err
+	}
+	if filters.HealthTagsAll, err = normalizeHealthTags(filters.HealthTagsAll); err != nil {
+		return err
+	}
+	if filters.TagsAny, err = normalizeTags(filters.TagsAny); err != nil {
+		return err
+	}
+	if filters.TagsAll, err = normalizeTags(filters.TagsAll); err != nil {
+		return err
+	}
+	if filters.ExcludeIngredients, err = normalizeExclusions(filters.ExcludeIngredients); err != nil {
+		return err
+	}
+	if filters.ExcludeAllergens, err = normalizeAllergens(filters.ExcludeAllergens); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateNutrition checks nutrition ranges and normalizes health tags.
+// It returns the cleaned, de-duplicated tag list.
+func validateNutrition(calories *int32, protein, carbs, fat *float64, healthTags []string) ([]string, error) {
+	if calories != nil && (*calories < 0 || *calories > maxCalories) {
+		return nil, fmt.Errorf("%w: calories must be between 0 and %d", ErrInvalidParams, maxCalories)
+	}
+
+	macros := []struct {
+		name  string
+		value *float64
+	}{
+		{"protein", protein},
+		{"carbs", carbs},
+		{"fat", fat},
+	}
+	for _, m := range macros {
+		if m.value != nil && (*m.value < 0 || *m.value > maxMacroG) {
+			return nil, fmt.Errorf("%w: %s must be between 0 and %d grams", ErrInvalidParams, m.name, maxMacroG)
+		}
+	}
+
+	return normalizeHealthTags(healthTags)
+}
+
 func (s *recipesService) CreateRecipe(ctx context.Context, req CreateRecipeRequest) (*Recipe, error) {
 	// Validate skill level
 	if !isValidSkillLevel(req.SkillLevel) {
@@ -271,32 +1156,80 @@ func (s *recipesService) CreateRecipe(ctx context.Context, req CreateRecipeReque
 	}
 
 	// Validate required fields
-	if req.Title == "" || req.Description == "" || req.Ingredients == "" || req.Instructions == "" {
+	if req.Title == "" || req.Description == "" {
 		return nil, fmt.Errorf("%w: title, description, ingredients, and instructions are required", ErrInvalidParams)
 	}
 
+	ingredientsText, ingredientItems, err := normalizeIngredients(req.Ingredients, req.IngredientItems)
+	if err != nil {
+		return nil, err
+	}
+
+	instructionsText, steps, err := normalizeSteps(req.Instructions, req.Steps, ingredientItems)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.CookingTime < 1 || req.Servings < 1 {
 		return nil, fmt.Errorf("%w: cooking_time and servings must be positive", ErrInvalidParams)
 	}
 
+	healthTags, err := validateNutrition(req.Calories, req.Protein, req.Carbs, req.Fat, req.HealthTags)
+	if err != nil {
+		return nil, err
+	}
+
+	variantIDs, err := normalizeVariantIDs(req.VariantIDs, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeRecipeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := s.checkDietary(ctx, req.DietaryCheck, variantIDs, ingredientItems)
+	if err != nil {
+		return nil, err
+	}
+
 	id, err := s.repo.CreateRecipe(ctx, repository.CreateRecipeParams{
 		Title:        req.Title,
 		Description:  req.Description,
-		Ingredients:  req.Ingredients,
-		Instructions: req.Instructions,
+		Ingredients:  ingredientsText,
+		Instructions: instructionsText,
 		CookingTime:  req.CookingTime,
 		SkillLevel:   req.SkillLevel,
 		CategoryID:   req.CategoryID,
-		VariantID:    req.VariantID,
+		VariantIDs:   variantIDs,
 		ImageURL:     req.ImageURL,
 		Servings:     req.Servings,
+		Calories:     req.Calories,
+		Protein:      req.Protein,
+		Carbs:        req.Carbs,
+		Fat:          req.Fat,
+		HealthTags:   healthTags,
+		Tags:         tags,
+		Allergens:    DetectAllergens(ingredientItems),
+
+		IngredientItems: toIngredientParams(ingredientItems),
+		Steps:           toStepParams(steps),
 	})
+	if errors.Is(err, repository.ErrUnknownReference) {
+		return nil, fmt.Errorf("%w: unknown category or variant", ErrInvalidParams)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create recipe: %w", err)
 	}
 
 	// Fetch the created recipe
-	return s.GetRecipeByID(ctx, int32(id))
+	recipe, err := s.GetRecipeByID(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+	recipe.DietaryWarnings = warnings
+	return recipe, nil
 }
 
 func (s *recipesService) UpdateRecipe(ctx context.Context, id int32, req UpdateRecipeRequest) (*Recipe, error) {
@@ -311,16 +1244,46 @@ func (s *recipesService) UpdateRecipe(ctx context.Context, id int32, req UpdateR
 	}
 
 	// Validate required fields
-	if req.Title == "" || req.Description == "" || req.Ingredients == "" || req.Instructions == "" {
+	if req.Title == "" || req.Description == "" {
 		return nil, fmt.Errorf("%w: title, description, ingredients, and instructions are required", ErrInvalidParams)
 	}
 
+	ingredientsText, ingredientItems, err := normalizeIngredients(req.Ingredients, req.IngredientItems)
+	if err != nil {
+		return nil, err
+	}
+
+	instructionsText, steps, err := normalizeSteps(req.Instructions, req.Steps, ingredientItems)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.CookingTime < 1 || req.Servings < 1 {
 		return nil, fmt.Errorf("%w: cooking_time and servings must be positive", ErrInvalidParams)
 	}
 
+	healthTags, err := validateNutrition(req.Calories, req.Protein, req.Carbs, req.Fat, req.HealthTags)
+	if err != nil {
+		return nil, err
+	}
+
+	variantIDs, err := normalizeVariantIDs(req.VariantIDs, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeRecipeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := s.checkDietary(ctx, req.DietaryCheck, variantIDs, ingredientItems)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if recipe exists
-	_, err := s.repo.GetRecipeByID(ctx, id)
+	_, err = s.repo.GetRecipeByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("%w: recipe not found", ErrRecipeNotFound)
 	}
@@ -329,39 +1292,59 @@ func (s *recipesService) UpdateRecipe(ctx context.Context, id int32, req UpdateR
 		ID:           id,
 		Title:        req.Title,
 		Description:  req.Description,
-		Ingredients:  req.Ingredients,
-		Instructions: req.Instructions,
+		Ingredients:  ingredientsText,
+		Instructions: instructionsText,
 		CookingTime:  req.CookingTime,
 		SkillLevel:   req.SkillLevel,
 		CategoryID:   req.CategoryID,
-		VariantID:    req.VariantID,
+		VariantIDs:   variantIDs,
 		ImageURL:     req.ImageURL,
 		Servings:     req.Servings,
+		Calories:     req.Calories,
+		Protein:      req.Protein,
+		Carbs:        req.Carbs,
+		Fat:          req.Fat,
+		HealthTags:   healthTags,
+		Tags:         tags,
+		Allergens:    DetectAllergens(ingredientItems),
+
+		IngredientItems: toIngredientParams(ingredientItems),
+		Steps:           toStepParams(steps),
 	})
+	if errors.Is(err, repository.ErrUnknownReference) {
+		return nil, fmt.Errorf("%w: unknown category or variant", ErrInvalidParams)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update recipe: %w", err)
 	}
 
 	// Fetch the updated recipe
-	return s.GetRecipeByID(ctx, id)
+	recipe, err := s.GetRecipeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	recipe.DietaryWarnings = warnings
+	return recipe, nil
 }
 
-func (s *recipesService) DeleteRecipe(ctx context.Context, id int32) error {
+// DeleteRecipe deletes a recipe and returns how many meal plan entries
+// were removed with it
+func (s *recipesService) DeleteRecipe(ctx context.Context, id int32) (int64, error) {
 	// Validate ID
 	if id < 1 {
-		return fmt.Errorf("%w: invalid recipe ID", ErrInvalidParams)
+		return 0, fmt.Errorf("%w: invalid recipe ID", ErrInvalidParams)
 	}
 
 	// Check if recipe exists
 	_, err := s.repo.GetRecipeByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("%w: recipe not found", ErrRecipeNotFound)
+		return 0, fmt.Errorf("%w: recipe not found", ErrRecipeNotFound)
 	}
 
-	err = s.repo.DeleteRecipe(ctx, id)
+	removed, err := s.repo.DeleteRecipe(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete recipe: %w", err)
+		return 0, fmt.Errorf("failed to delete recipe: %w", err)
 	}
 
-	return nil
+	return removed, nil
 }
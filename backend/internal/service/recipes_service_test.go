@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/sonyadriko/masakyuk/internal/db"
@@ -11,26 +14,50 @@ import (
 
 // Mock repository for testing
 type mockRecipesRepository struct {
-	listRecipesFunc     func(ctx context.Context, params repository.ListRecipesParams) ([]db.ListRecipesRow, error)
-	countRecipesFunc    func(ctx context.Context, params repository.CountRecipesParams) (int64, error)
+	listRecipesFunc     func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error)
+	countRecipesFunc    func(ctx context.Context, params repository.RecipeFilter) (int64, error)
+	countFacetFunc      func(ctx context.Context, facet repository.Facet, filter repository.RecipeFilter) ([]repository.FacetCount, error)
 	getRecipeByIDFunc   func(ctx context.Context, id int32) (db.GetRecipeByIDRow, error)
-	getRandomRecipeFunc func(ctx context.Context, params repository.GetRandomRecipeParams) (db.GetRandomRecipeRow, error)
+	spinCandidatesFunc  func(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error)
+	createRecipeFunc    func(ctx context.Context, params repository.CreateRecipeParams) (int64, error)
+	updateRecipeFunc    func(ctx context.Context, params repository.UpdateRecipeParams) error
+	deleteRecipeFunc    func(ctx context.Context, id int32) (int64, error)
+	listHealthTagsFunc  func(ctx context.Context, recipeIDs []int32) ([]db.RecipeHealthTag, error)
+	listIngredientsFunc func(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error)
+	listStepsFunc       func(ctx context.Context, recipeIDs []int32) ([]db.RecipeStep, error)
+	listStepRefsFunc    func(ctx context.Context, recipeIDs []int32) ([]db.RecipeStepIngredient, error)
+	listByIDsFunc       func(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error)
+	listVariantsFunc    func(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeVariantsRow, error)
+	listTagsFunc        func(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeTagsRow, error)
+	pantryMatchesFunc   func(ctx context.Context, pantry []string, maxMissing, limit int32) ([]repository.PantryCoverage, error)
+	variantNames        []db.ListVariantNamesRow
+	lastSpins           []int32
+	spinsSince          []int32
+	recordedSpins       []int32
+	listAllIngredients  []db.RecipeIngredient
 }
 
-func (m *mockRecipesRepository) ListRecipes(ctx context.Context, params repository.ListRecipesParams) ([]db.ListRecipesRow, error) {
+func (m *mockRecipesRepository) ListRecipes(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
 	if m.listRecipesFunc != nil {
 		return m.listRecipesFunc(ctx, params)
 	}
 	return nil, nil
 }
 
-func (m *mockRecipesRepository) CountRecipes(ctx context.Context, params repository.CountRecipesParams) (int64, error) {
+func (m *mockRecipesRepository) CountRecipes(ctx context.Context, params repository.RecipeFilter) (int64, error) {
 	if m.countRecipesFunc != nil {
 		return m.countRecipesFunc(ctx, params)
 	}
 	return 0, nil
 }
 
+func (m *mockRecipesRepository) CountRecipeFacet(ctx context.Context, facet repository.Facet, filter repository.RecipeFilter) ([]repository.FacetCount, error) {
+	if m.countFacetFunc != nil {
+		return m.countFacetFunc(ctx, facet, filter)
+	}
+	return []repository.FacetCount{}, nil
+}
+
 func (m *mockRecipesRepository) GetRecipeByID(ctx context.Context, id int32) (db.GetRecipeByIDRow, error) {
 	if m.getRecipeByIDFunc != nil {
 		return m.getRecipeByIDFunc(ctx, id)
@@ -38,28 +65,157 @@ func (m *mockRecipesRepository) GetRecipeByID(ctx context.Context, id int32) (db
 	return db.GetRecipeByIDRow{}, nil
 }
 
-func (m *mockRecipesRepository) GetRandomRecipe(ctx context.Context, params repository.GetRandomRecipeParams) (db.GetRandomRecipeRow, error) {
-	if m.getRandomRecipeFunc != nil {
-		return m.getRandomRecipeFunc(ctx, params)
+func (m *mockRecipesRepository) ListSpinCandidates(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error) {
+	if m.spinCandidatesFunc != nil {
+		return m.spinCandidatesFunc(ctx, params)
+	}
+	return []repository.SpinCandidate{}, nil
+}
+
+func (m *mockRecipesRepository) CreateRecipe(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+	if m.createRecipeFunc != nil {
+		return m.createRecipeFunc(ctx, params)
 	}
-	return db.GetRandomRecipeRow{}, nil
+	return 1, nil
 }
 
-func (m *mockRecipesRepository) ListCategories(ctx context.Context) ([]db.Category, error) {
+func (m *mockRecipesRepository) UpdateRecipe(ctx context.Context, params repository.UpdateRecipeParams) error {
+	if m.updateRecipeFunc != nil {
+		return m.updateRecipeFunc(ctx, params)
+	}
+	return nil
+}
+
+func (m *mockRecipesRepository) DeleteRecipe(ctx context.Context, id int32) (int64, error) {
+	if m.deleteRecipeFunc != nil {
+		return m.deleteRecipeFunc(ctx, id)
+	}
+	return 0, nil
+}
+
+func (m *mockRecipesRepository) ListRecipeHealthTags(ctx context.Context, recipeIDs []int32) ([]db.RecipeHealthTag, error) {
+	if m.listHealthTagsFunc != nil {
+		return m.listHealthTagsFunc(ctx, recipeIDs)
+	}
+	return []db.RecipeHealthTag{}, nil
+}
+
+func (m *mockRecipesRepository) ListRecipeIngredients(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error) {
+	if m.listIngredientsFunc != nil {
+		return m.listIngredientsFunc(ctx, recipeIDs)
+	}
+	return []db.RecipeIngredient{}, nil
+}
+
+func (m *mockRecipesRepository) ListRecipesWithoutIngredients(ctx context.Context) ([]db.ListRecipesWithoutIngredientsRow, error) {
 	return nil, nil
 }
 
-func (m *mockRecipesRepository) ListVariants(ctx context.Context) ([]db.Variant, error) {
+func (m *mockRecipesRepository) ReplaceRecipeIngredients(ctx context.Context, recipeID int32, items []repository.IngredientParams) error {
+	return nil
+}
+
+func (m *mockRecipesRepository) ListRecipeAllergens(ctx context.Context, recipeIDs []int32) ([]db.RecipeAllergen, error) {
+	return []db.RecipeAllergen{}, nil
+}
+
+func (m *mockRecipesRepository) ReplaceRecipeAllergens(ctx context.Context, recipeID int32, allergens []string) error {
+	return nil
+}
+
+func (m *mockRecipesRepository) ListRecipeVariants(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeVariantsRow, error) {
+	if m.listVariantsFunc != nil {
+		return m.listVariantsFunc(ctx, recipeIDs)
+	}
+	return []db.ListRecipeVariantsRow{}, nil
+}
+
+func (m *mockRecipesRepository) RecordSpin(ctx context.Context, clientID string, recipeID int32) error {
+	m.recordedSpins = append(m.recordedSpins, recipeID)
+	return nil
+}
+
+func (m *mockRecipesRepository) ListLastSpins(ctx context.Context, clientID string, limit int32) ([]int32, error) {
+	if int(limit) < len(m.lastSpins) {
+		return m.lastSpins[:limit], nil
+	}
+	return m.lastSpins, nil
+}
+
+func (m *mockRecipesRepository) ListSpinsSince(ctx context.Context, clientID string, days int32) ([]int32, error) {
+	return m.spinsSince, nil
+}
+
+func (m *mockRecipesRepository) ListVariantNames(ctx context.Context, variantIDs []int32) ([]db.ListVariantNamesRow, error) {
+	return m.variantNames, nil
+}
+
+func (m *mockRecipesRepository) ListRecipeTags(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeTagsRow, error) {
+	if m.listTagsFunc != nil {
+		return m.listTagsFunc(ctx, recipeIDs)
+	}
+	return []db.ListRecipeTagsRow{}, nil
+}
+
+func (m *mockRecipesRepository) ListRecipesByIDs(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error) {
+	if m.listByIDsFunc != nil {
+		return m.listByIDsFunc(ctx, recipeIDs)
+	}
+	return []repository.RecipeRow{}, nil
+}
+
+func (m *mockRecipesRepository) ListAllRecipeIngredients(ctx context.Context) ([]db.RecipeIngredient, error) {
+	return m.listAllIngredients, nil
+}
+
+func (m *mockRecipesRepository) ListPantryMatches(ctx context.Context, pantry []string, maxMissing, limit int32) ([]repository.PantryCoverage, error) {
+	if m.pantryMatchesFunc != nil {
+		return m.pantryMatchesFunc(ctx, pantry, maxMissing, limit)
+	}
+	return []repository.PantryCoverage{}, nil
+}
+
+func (m *mockRecipesRepository) UpdateIngredientMatch(ctx context.Context, id int32, foodName string, optional bool) error {
+	return nil
+}
+
+func (m *mockRecipesRepository) ListRecipeSteps(ctx context.Context, recipeIDs []int32) ([]db.RecipeStep, error) {
+	if m.listStepsFunc != nil {
+		return m.listStepsFunc(ctx, recipeIDs)
+	}
+	return []db.RecipeStep{}, nil
+}
+
+func (m *mockRecipesRepository) ListRecipeStepIngredients(ctx context.Context, recipeIDs []int32) ([]db.RecipeStepIngredient, error) {
+	if m.listStepRefsFunc != nil {
+		return m.listStepRefsFunc(ctx, recipeIDs)
+	}
+	return []db.RecipeStepIngredient{}, nil
+}
+
+func (m *mockRecipesRepository) ListRecipesWithoutSteps(ctx context.Context) ([]db.ListRecipesWithoutStepsRow, error) {
 	return nil, nil
 }
 
+func (m *mockRecipesRepository) ReplaceRecipeSteps(ctx context.Context, recipeID int32, steps []repository.StepParams) error {
+	return nil
+}
+
+func (m *mockRecipesRepository) CountRecipesNeedingBackfill(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRecipesRepository) MarkRecipesBackfilled(ctx context.Context) error {
+	return nil
+}
+
 func TestListRecipes_Success(t *testing.T) {
 	mockRepo := &mockRecipesRepository{
-		countRecipesFunc: func(ctx context.Context, params repository.CountRecipesParams) (int64, error) {
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
 			return 25, nil
 		},
-		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]db.ListRecipesRow, error) {
-			return []db.ListRecipesRow{
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			return []repository.RecipeRow{
 				{
 					ID:           1,
 					Title:        "Nasi Goreng",
@@ -70,8 +226,6 @@ func TestListRecipes_Success(t *testing.T) {
 					SkillLevel:   "beginner",
 					CategoryID:   1,
 					CategoryName: "Indonesian",
-					VariantID:    1,
-					VariantName:  "Regular",
 					Servings:     2,
 				},
 			}, nil
@@ -109,31 +263,40 @@ func TestListRecipes_Success(t *testing.T) {
 }
 
 func TestListRecipes_WithFilters(t *testing.T) {
-	skillLevel := "beginner"
-	variantID := int32(1)
+	minTime := int32(15)
+	maxTime := int32(45)
 
 	mockRepo := &mockRecipesRepository{
-		countRecipesFunc: func(ctx context.Context, params repository.CountRecipesParams) (int64, error) {
-			if params.SkillLevel == nil || *params.SkillLevel != skillLevel {
-				t.Error("Expected skill_level filter to be passed")
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
+			if !reflect.DeepEqual(params.SkillLevels, []string{"beginner", "intermediate"}) {
+				t.Errorf("Expected normalized skill_level filter, got %v", params.SkillLevels)
+			}
+			if !reflect.DeepEqual(params.VariantIDs, []int32{1}) {
+				t.Errorf("Expected variant_id filter to be passed, got %v", params.VariantIDs)
 			}
-			if params.VariantID == nil || *params.VariantID != variantID {
-				t.Error("Expected variant_id filter to be passed")
+			if !reflect.DeepEqual(params.CategoryIDs, []int32{1, 3}) {
+				t.Errorf("Expected de-duplicated category_id filter, got %v", params.CategoryIDs)
+			}
+			if params.MinCookingTime == nil || *params.MinCookingTime != minTime || params.MaxCookingTime == nil || *params.MaxCookingTime != maxTime {
+				t.Error("Expected cooking time bounds to be passed")
 			}
 			return 5, nil
 		},
-		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]db.ListRecipesRow, error) {
-			return []db.ListRecipesRow{}, nil
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			return []repository.RecipeRow{}, nil
 		},
 	}
 
 	service := NewRecipesService(mockRepo)
 
 	filters := RecipeFilters{
-		SkillLevel: &skillLevel,
-		VariantID:  &variantID,
-		Page:       1,
-		PerPage:    10,
+		SkillLevels:    []string{"beginner", "Intermediate", "beginner"},
+		VariantIDs:     []int32{1},
+		CategoryIDs:    []int32{1, 3, 1},
+		MinCookingTime: &minTime,
+		MaxCookingTime: &maxTime,
+		Page:           1,
+		PerPage:        10,
 	}
 
 	_, err := service.ListRecipes(context.Background(), filters)
@@ -143,15 +306,305 @@ func TestListRecipes_WithFilters(t *testing.T) {
 	}
 }
 
+func TestListRecipes_NutritionFilters(t *testing.T) {
+	maxCalories := int32(500)
+	minProtein := 25.0
+
+	mockRepo := &mockRecipesRepository{
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
+			if params.MaxCalories == nil || *params.MaxCalories != maxCalories {
+				t.Error("Expected max_calories filter to be passed")
+			}
+			if params.MinProtein == nil || *params.MinProtein != minProtein {
+				t.Error("Expected min_protein filter to be passed")
+			}
+			if len(params.HealthTagsAll) != 1 || params.HealthTagsAll[0] != "high-protein" {
+				t.Errorf("Expected normalized health_tags_all, got %v", params.HealthTagsAll)
+			}
+			return 1, nil
+		},
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			return []repository.RecipeRow{{ID: 4, Title: "Sate Ayam"}}, nil
+		},
+		listHealthTagsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeHealthTag, error) {
+			return []db.RecipeHealthTag{{RecipeID: 4, Tag: "high-protein"}}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	filters := RecipeFilters{
+		MaxCalories:   &maxCalories,
+		MinProtein:    &minProtein,
+		HealthTagsAll: []string{"High-Protein"},
+		Page:          1,
+		PerPage:       10,
+	}
+
+	result, err := service.ListRecipes(context.Background(), filters)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Data[0].HealthTags) != 1 {
+		t.Errorf("Expected health tags on listed recipe, got %v", result.Data[0].HealthTags)
+	}
+}
+
+func TestListRecipes_Exclusions(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
+			if !reflect.DeepEqual(params.ExcludeIngredients, []string{"peanut", "shrimp paste"}) {
+				t.Errorf("Expected normalized exclude_ingredients, got %v", params.ExcludeIngredients)
+			}
+			if !reflect.DeepEqual(params.ExcludeAllergens, []string{"gluten", "shellfish"}) {
+				t.Errorf("Expected normalized exclude_allergens, got %v", params.ExcludeAllergens)
+			}
+			return 0, nil
+		},
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			if !reflect.DeepEqual(params.ExcludeAllergens, []string{"gluten", "shellfish"}) {
+				t.Errorf("Expected exclude_allergens on list, got %v", params.ExcludeAllergens)
+			}
+			return []repository.RecipeRow{}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	_, err := service.ListRecipes(context.Background(), RecipeFilters{
+		ExcludeIngredients: []string{"Peanuts", " shrimp  paste", "peanut"},
+		ExcludeAllergens:   []string{"Gluten", "shellfish"},
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestListRecipes_Sort(t *testing.T) {
+	search := "nasi goreng"
+
+	tests := []struct {
+		name     string
+		filters  RecipeFilters
+		expected repository.RecipeSort
+	}{
+		{"default", RecipeFilters{}, repository.RecipeSort{Field: repository.SortCreatedAt, Desc: true}},
+		{"default when searching", RecipeFilters{Search: &search}, repository.RecipeSort{Field: repository.SortRelevance, Desc: true}},
+		{"oldest", RecipeFilters{Sort: "oldest"}, repository.RecipeSort{Field: repository.SortCreatedAt}},
+		{"cooking time", RecipeFilters{Sort: "cooking_time"}, repository.RecipeSort{Field: repository.SortCookingTime}},
+		{"title descending", RecipeFilters{Sort: "Title", Order: "DESC"}, repository.RecipeSort{Field: repository.SortTitle, Desc: true}},
+		{"calories", RecipeFilters{Sort: "calories", Order: "asc"}, repository.RecipeSort{Field: repository.SortCalories}},
+		{"sort overrides relevance", RecipeFilters{Search: &search, Sort: "newest"}, repository.RecipeSort{Field: repository.SortCreatedAt, Desc: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured repository.RecipeSort
+			mockRepo := &mockRecipesRepository{
+				listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+					captured = params.Sort
+					return []repository.RecipeRow{}, nil
+				},
+			}
+
+			service := NewRecipesService(mockRepo)
+
+			if _, err := service.ListRecipes(context.Background(), tt.filters); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if captured != tt.expected {
+				t.Errorf("Expected sort %+v, got %+v", tt.expected, captured)
+			}
+		})
+	}
+}
+
+func TestListRecipes_InvalidSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters RecipeFilters
+	}{
+		{"unknown key", RecipeFilters{Sort: "rating"}},
+		{"relevance without search", RecipeFilters{Sort: "relevance"}},
+		{"unknown order", RecipeFilters{Sort: "title", Order: "up"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecipesService(&mockRecipesRepository{})
+
+			_, err := service.ListRecipes(context.Background(), tt.filters)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestListRecipes_Cursor(t *testing.T) {
+	var calls []repository.ListRecipesParams
+	mockRepo := &mockRecipesRepository{
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			calls = append(calls, params)
+			if params.After == nil {
+				// One row more than requested signals a next page
+				return []repository.RecipeRow{
+					{ID: 9, Title: "Bakso", CookingTime: 60},
+					{ID: 4, Title: "Sate Ayam", CookingTime: 45},
+					{ID: 7, Title: "Soto", CookingTime: 90},
+				}, nil
+			}
+			return []repository.RecipeRow{{ID: 7, Title: "Soto", CookingTime: 90}}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	first, err := service.ListRecipes(context.Background(), RecipeFilters{Sort: "cooking_time", PerPage: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(first.Data) != 2 {
+		t.Fatalf("Expected 2 recipes, got %d", len(first.Data))
+	}
+	if calls[0].Limit != 3 {
+		t.Errorf("Expected limit 3 (one extra row), got %d", calls[0].Limit)
+	}
+	if first.Meta.NextCursor == "" {
+		t.Fatal("Expected next_cursor on a non-final page")
+	}
+
+	second, err := service.ListRecipes(context.Background(), RecipeFilters{Sort: "cooking_time", PerPage: 2, Cursor: first.Meta.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	after := calls[1].After
+	if after == nil || after.ID != 4 || after.Value != int64(45) {
+		t.Errorf("Expected cursor after recipe 4 at 45 minutes, got %+v", after)
+	}
+	if second.Meta.NextCursor != "" {
+		t.Errorf("Expected no next_cursor on the last page, got %q", second.Meta.NextCursor)
+	}
+	if first.Meta.Page != 1 || second.Meta.Page != 0 {
+		t.Errorf("Expected page 1, then no page in cursor mode, got %d and %d", first.Meta.Page, second.Meta.Page)
+	}
+
+	_, err = service.ListRecipes(context.Background(), RecipeFilters{Sort: "title", Cursor: first.Meta.NextCursor})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams for a cursor from another sort, got %v", err)
+	}
+
+	_, err = service.ListRecipes(context.Background(), RecipeFilters{Cursor: "not-a-cursor"})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams for a malformed cursor, got %v", err)
+	}
+}
+
+func TestCursor_Relevance(t *testing.T) {
+	ordering := repository.RecipeSort{Field: repository.SortRelevance, Desc: true}
+
+	token := encodeCursor(ordering, repository.RecipeRow{ID: 12, Relevance: 1843920})
+	after, err := decodeCursor(token, ordering)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if after.ID != 12 || after.Value != int64(1843920) {
+		t.Errorf("Expected cursor after recipe 12 at score 1843920, got %+v", after)
+	}
+}
+
+func TestListRecipes_Facets(t *testing.T) {
+	search := "ayam"
+
+	mockRepo := &mockRecipesRepository{
+		countFacetFunc: func(ctx context.Context, facet repository.Facet, filter repository.RecipeFilter) ([]repository.FacetCount, error) {
+			if filter.Search == nil || *filter.Search != search {
+				t.Errorf("Expected %s facet to keep the search", facet)
+			}
+			switch facet {
+			case repository.FacetCategory:
+				if filter.CategoryIDs != nil || filter.SkillLevels == nil {
+					t.Errorf("Expected category facet to drop only its own filter, got %+v", filter)
+				}
+				return []repository.FacetCount{
+					{Value: "1", Label: "Indonesian", Count: 4},
+					{Value: "2", Label: "Western", Count: 1},
+				}, nil
+			case repository.FacetSkillLevel:
+				if filter.SkillLevels != nil || filter.CategoryIDs == nil {
+					t.Errorf("Expected skill_level facet to drop only its own filter, got %+v", filter)
+				}
+				return []repository.FacetCount{{Value: "beginner", Label: "beginner", Count: 3}}, nil
+			}
+			t.Errorf("Unexpected facet %s", facet)
+			return nil, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	result, err := service.ListRecipes(context.Background(), RecipeFilters{
+		Search:      &search,
+		CategoryIDs: []int32{1},
+		SkillLevels: []string{"beginner"},
+		Facets:      []string{"Category", "skill_level", "category"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Facets) != 2 {
+		t.Fatalf("Expected 2 facets, got %v", result.Facets)
+	}
+	if got := result.Facets["category"]; len(got) != 2 || got[0].Label != "Indonesian" || got[0].Count != 4 {
+		t.Errorf("Unexpected category facet %+v", got)
+	}
+
+	_, err = service.ListRecipes(context.Background(), RecipeFilters{Facets: []string{"rating"}})
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams for an unknown facet, got %v", err)
+	}
+}
+
+func TestListRecipes_InvalidNutritionFilters(t *testing.T) {
+	minCalories := int32(800)
+	maxCalories := int32(400)
+
+	tests := []struct {
+		name    string
+		filters RecipeFilters
+	}{
+		{"min above max calories", RecipeFilters{MinCalories: &minCalories, MaxCalories: &maxCalories}},
+		{"unknown health tag", RecipeFilters{HealthTagsAny: []string{"superfood"}}},
+		{"unknown allergen", RecipeFilters{ExcludeAllergens: []string{"nightshade"}}},
+		{"malformed tag", RecipeFilters{TagsAll: []string{"50%-off"}}},
+		{"min above max cooking time", RecipeFilters{MinCookingTime: &minCalories, MaxCookingTime: &maxCalories}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecipesService(&mockRecipesRepository{})
+
+			_, err := service.ListRecipes(context.Background(), tt.filters)
+
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
 func TestListRecipes_InvalidSkillLevel(t *testing.T) {
 	mockRepo := &mockRecipesRepository{}
 	service := NewRecipesService(mockRepo)
 
-	invalidSkillLevel := "expert"
 	filters := RecipeFilters{
-		SkillLevel: &invalidSkillLevel,
-		Page:       1,
-		PerPage:    10,
+		SkillLevels: []string{"beginner", "expert"},
+		Page:        1,
+		PerPage:     10,
 	}
 
 	_, err := service.ListRecipes(context.Background(), filters)
@@ -167,16 +620,16 @@ func TestListRecipes_InvalidSkillLevel(t *testing.T) {
 
 func TestListRecipes_Pagination(t *testing.T) {
 	mockRepo := &mockRecipesRepository{
-		countRecipesFunc: func(ctx context.Context, params repository.CountRecipesParams) (int64, error) {
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
 			return 100, nil
 		},
-		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]db.ListRecipesRow, error) {
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
 			// Verify offset calculation
 			expectedOffset := int32(20) // (page 3 - 1) * 10
 			if params.Offset != expectedOffset {
 				t.Errorf("Expected offset %d, got %d", expectedOffset, params.Offset)
 			}
-			return []db.ListRecipesRow{}, nil
+			return []repository.RecipeRow{}, nil
 		},
 	}
 
@@ -211,8 +664,6 @@ func TestGetRecipeByID_Success(t *testing.T) {
 				SkillLevel:   "intermediate",
 				CategoryID:   1,
 				CategoryName: "Test Category",
-				VariantID:    1,
-				VariantName:  "Test Variant",
 				Servings:     2,
 			}, nil
 		},
@@ -231,6 +682,127 @@ func TestGetRecipeByID_Success(t *testing.T) {
 	}
 }
 
+func TestGetRecipeByID_Nutrition(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		getRecipeByIDFunc: func(ctx context.Context, id int32) (db.GetRecipeByIDRow, error) {
+			return db.GetRecipeByIDRow{
+				ID:       id,
+				Title:    "Gado-Gado",
+				Calories: sql.NullInt32{Int32: 420, Valid: true},
+				Protein:  sql.NullString{String: "18.5", Valid: true},
+				Carbs:    sql.NullString{String: "32.0", Valid: true},
+			}, nil
+		},
+		listHealthTagsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeHealthTag, error) {
+			return []db.RecipeHealthTag{
+				{RecipeID: 3, Tag: "high-protein"},
+				{RecipeID: 3, Tag: "high-fiber"},
+			}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	recipe, err := service.GetRecipeByID(context.Background(), 3)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if recipe.Calories == nil || *recipe.Calories != 420 {
+		t.Errorf("Expected calories 420, got %v", recipe.Calories)
+	}
+
+	if recipe.Protein == nil || *recipe.Protein != 18.5 {
+		t.Errorf("Expected protein 18.5, got %v", recipe.Protein)
+	}
+
+	if recipe.Fat != nil {
+		t.Errorf("Expected nil fat, got %v", *recipe.Fat)
+	}
+
+	if len(recipe.HealthTags) != 2 || recipe.HealthTags[0] != "high-protein" {
+		t.Errorf("Expected health tags [high-protein high-fiber], got %v", recipe.HealthTags)
+	}
+}
+
+func TestGetRecipeByID_Steps(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		getRecipeByIDFunc: func(ctx context.Context, id int32) (db.GetRecipeByIDRow, error) {
+			return db.GetRecipeByIDRow{ID: id, Title: "Rendang"}, nil
+		},
+		listStepsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeStep, error) {
+			return []db.RecipeStep{
+				{ID: 10, RecipeID: 2, Position: 1, Instruction: "Prepare spice paste"},
+				{ID: 11, RecipeID: 2, Position: 2, Instruction: "Simmer for 2 hours", DurationSeconds: sql.NullInt32{Int32: 7200, Valid: true}},
+			}, nil
+		},
+		listStepRefsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeStepIngredient, error) {
+			return []db.RecipeStepIngredient{{StepID: 11, IngredientPosition: 1}}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	recipe, err := service.GetRecipeByID(context.Background(), 2)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(recipe.Steps) != 2 {
+		t.Fatalf("Expected 2 steps, got %d", len(recipe.Steps))
+	}
+
+	if recipe.Steps[1].DurationSeconds == nil || *recipe.Steps[1].DurationSeconds != 7200 {
+		t.Errorf("Expected 7200 second duration on step 2, got %v", recipe.Steps[1].DurationSeconds)
+	}
+
+	if len(recipe.Steps[0].IngredientRefs) != 0 || len(recipe.Steps[1].IngredientRefs) != 1 {
+		t.Errorf("Expected ingredient refs only on step 2, got %+v", recipe.Steps)
+	}
+}
+
+func TestScaleRecipe(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		getRecipeByIDFunc: func(ctx context.Context, id int32) (db.GetRecipeByIDRow, error) {
+			return db.GetRecipeByIDRow{ID: id, Title: "Nasi Goreng", Servings: 2}, nil
+		},
+		listIngredientsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error) {
+			return []db.RecipeIngredient{
+				{RecipeID: 1, Position: 1, Quantity: sql.NullString{String: "2.000", Valid: true}, Unit: sql.NullString{String: "cups", Valid: true}, Name: "cooked rice"},
+				{RecipeID: 1, Position: 2, Name: "Salt and pepper", Note: sql.NullString{String: "to taste", Valid: true}},
+			}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	recipe, err := service.ScaleRecipe(context.Background(), 1, 3)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if recipe.Servings != 3 {
+		t.Errorf("Expected 3 servings, got %d", recipe.Servings)
+	}
+
+	if recipe.Ingredients != "3 cups cooked rice, Salt and pepper to taste" {
+		t.Errorf("Unexpected scaled ingredients %q", recipe.Ingredients)
+	}
+}
+
+func TestScaleRecipe_InvalidServings(t *testing.T) {
+	service := NewRecipesService(&mockRecipesRepository{})
+
+	for _, servings := range []int32{0, -1, 101} {
+		if _, err := service.ScaleRecipe(context.Background(), 1, servings); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("servings %d: expected ErrInvalidParams, got %v", servings, err)
+		}
+	}
+}
+
 func TestGetRecipeByID_InvalidID(t *testing.T) {
 	mockRepo := &mockRecipesRepository{}
 	service := NewRecipesService(mockRepo)
@@ -246,10 +818,33 @@ func TestGetRecipeByID_InvalidID(t *testing.T) {
 	}
 }
 
+func TestDeleteRecipe_ReportsRemovedPlanEntries(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		deleteRecipeFunc: func(ctx context.Context, id int32) (int64, error) {
+			return 2, nil
+		},
+	}
+	service := NewRecipesService(mockRepo)
+
+	removed, err := service.DeleteRecipe(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 removed plan entries, got %d", removed)
+	}
+}
+
 func TestGetRandomRecipe_Success(t *testing.T) {
 	mockRepo := &mockRecipesRepository{
-		getRandomRecipeFunc: func(ctx context.Context, params repository.GetRandomRecipeParams) (db.GetRandomRecipeRow, error) {
-			return db.GetRandomRecipeRow{
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
+			return 1, nil
+		},
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			if params.Limit != 1 || params.Offset != 0 {
+				t.Errorf("Expected the only match to be read, got limit %d offset %d", params.Limit, params.Offset)
+			}
+			return []repository.RecipeRow{{
 				ID:           1,
 				Title:        "Random Recipe",
 				Description:  "Random Description",
@@ -259,30 +854,32 @@ func TestGetRandomRecipe_Success(t *testing.T) {
 				SkillLevel:   "beginner",
 				CategoryID:   1,
 				CategoryName: "Random Category",
-				VariantID:    1,
-				VariantName:  "Random Variant",
 				Servings:     2,
-			}, nil
+			}}, nil
 		},
 	}
 
 	service := NewRecipesService(mockRepo)
 
-	recipe, err := service.GetRandomRecipe(context.Background(), RecipeFilters{})
+	result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{})
 
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	if recipe.Title != "Random Recipe" {
-		t.Errorf("Expected title 'Random Recipe', got '%s'", recipe.Title)
+	if result.Recipe.Title != "Random Recipe" {
+		t.Errorf("Expected title 'Random Recipe', got '%s'", result.Recipe.Title)
+	}
+
+	if result.Probability != 1 || result.Candidates != 1 {
+		t.Errorf("Expected certain draw from 1 candidate, got %v of %d", result.Probability, result.Candidates)
 	}
 }
 
 func TestGetRandomRecipe_NoResults(t *testing.T) {
 	mockRepo := &mockRecipesRepository{
-		getRandomRecipeFunc: func(ctx context.Context, params repository.GetRandomRecipeParams) (db.GetRandomRecipeRow, error) {
-			return db.GetRandomRecipeRow{}, errors.New("no rows")
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
+			return 0, nil
 		},
 	}
 
@@ -298,3 +895,394 @@ func TestGetRandomRecipe_NoResults(t *testing.T) {
 		t.Errorf("Expected ErrRecipeNotFound, got %v", err)
 	}
 }
+
+func seededSpinRepository() *mockRecipesRepository {
+	mockRepo := &mockRecipesRepository{}
+	spinPool(mockRepo, []int32{1, 2, 3, 4})
+	return mockRepo
+}
+
+func TestGetRandomRecipe_Seed(t *testing.T) {
+	tests := []struct {
+		seed     int64
+		expected int32
+	}{
+		{1, 3},
+		{2, 1},
+		{7, 4},
+	}
+
+	service := NewRecipesService(seededSpinRepository())
+
+	for _, tt := range tests {
+		// The same seed must land on the same recipe every time
+		for i := 0; i < 2; i++ {
+			seed := tt.seed
+			result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{Seed: &seed})
+			if err != nil {
+				t.Fatalf("Seed %d: expected no error, got %v", tt.seed, err)
+			}
+			if result.Recipe.ID != tt.expected {
+				t.Errorf("Seed %d: expected recipe %d, got %d", tt.seed, tt.expected, result.Recipe.ID)
+			}
+			if result.Seed != tt.seed {
+				t.Errorf("Expected seed %d in result, got %d", tt.seed, result.Seed)
+			}
+		}
+	}
+}
+
+func TestGetRandomRecipe_InjectedSeeds(t *testing.T) {
+	service := NewRecipesService(seededSpinRepository(), WithSpinSeeds(func() int64 { return 7 }))
+
+	result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Seed != 7 || result.Recipe.ID != 4 {
+		t.Errorf("Expected seed 7 to land on recipe 4, got seed %d and recipe %d", result.Seed, result.Recipe.ID)
+	}
+
+	// Replaying the returned seed gives the same recipe
+	replay, err := NewRecipesService(seededSpinRepository()).GetRandomRecipe(context.Background(), RecipeFilters{Seed: &result.Seed})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if replay.Recipe.ID != result.Recipe.ID {
+		t.Errorf("Expected replay to land on recipe %d, got %d", result.Recipe.ID, replay.Recipe.ID)
+	}
+}
+
+func TestGetRandomRecipe_InvalidSeed(t *testing.T) {
+	service := NewRecipesService(seededSpinRepository())
+
+	for _, seed := range []int64{-1, 1 << 53} {
+		seed := seed
+		_, err := service.GetRandomRecipe(context.Background(), RecipeFilters{Seed: &seed})
+		if !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("Seed %d: expected ErrInvalidParams, got %v", seed, err)
+		}
+	}
+}
+
+func validCreateRequest() CreateRecipeRequest {
+	return CreateRecipeRequest{
+		Title:        "Gado-Gado",
+		Description:  "Vegetable salad with peanut sauce",
+		Ingredients:  "Vegetables, tofu, peanut sauce",
+		Instructions: "1. Blanch vegetables",
+		CookingTime:  30,
+		SkillLevel:   "beginner",
+		CategoryID:   1,
+		VariantIDs:   []int32{2},
+		Servings:     2,
+	}
+}
+
+func TestCreateRecipe_Nutrition(t *testing.T) {
+	calories := int32(420)
+	protein := 18.5
+
+	mockRepo := &mockRecipesRepository{
+		createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+			if params.Calories == nil || *params.Calories != calories {
+				t.Error("Expected calories to be passed")
+			}
+			if params.Protein == nil || *params.Protein != protein {
+				t.Error("Expected protein to be passed")
+			}
+			if len(params.HealthTags) != 1 || params.HealthTags[0] != "high-protein" {
+				t.Errorf("Expected normalized health tags, got %v", params.HealthTags)
+			}
+			return 7, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	req := validCreateRequest()
+	req.Calories = &calories
+	req.Protein = &protein
+	req.HealthTags = []string{" High-Protein ", "high-protein"}
+
+	if _, err := service.CreateRecipe(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestGetRecipeByID_Variants(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		getRecipeByIDFunc: func(ctx context.Context, id int32) (db.GetRecipeByIDRow, error) {
+			return db.GetRecipeByIDRow{ID: id, Title: "Tempe Goreng"}, nil
+		},
+		listVariantsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeVariantsRow, error) {
+			return []db.ListRecipeVariantsRow{
+				{RecipeID: 3, VariantID: 5, Name: "Gluten-Free"},
+				{RecipeID: 3, VariantID: 4, Name: "Halal"},
+				{RecipeID: 3, VariantID: 2, Name: "Vegetarian"},
+			}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	recipe, err := service.GetRecipeByID(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []RecipeVariant{{5, "Gluten-Free"}, {4, "Halal"}, {2, "Vegetarian"}}
+	if !reflect.DeepEqual(recipe.Variants, expected) {
+		t.Errorf("Expected variants %v, got %v", expected, recipe.Variants)
+	}
+}
+
+func TestCreateRecipe_Variants(t *testing.T) {
+	legacyVariant := int32(3)
+
+	tests := []struct {
+		name     string
+		modify   func(req *CreateRecipeRequest)
+		expected []int32
+	}{
+		{"list is de-duplicated", func(req *CreateRecipeRequest) { req.VariantIDs = []int32{2, 5, 2, 4} }, []int32{2, 5, 4}},
+		{"legacy single variant", func(req *CreateRecipeRequest) { req.VariantIDs, req.VariantID = nil, &legacyVariant }, []int32{3}},
+		{"list wins over legacy", func(req *CreateRecipeRequest) { req.VariantID = &legacyVariant }, []int32{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured []int32
+			mockRepo := &mockRecipesRepository{
+				createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+					captured = params.VariantIDs
+					return 7, nil
+				},
+			}
+
+			service := NewRecipesService(mockRepo)
+
+			req := validCreateRequest()
+			tt.modify(&req)
+
+			if _, err := service.CreateRecipe(context.Background(), req); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(captured, tt.expected) {
+				t.Errorf("Expected variants %v, got %v", tt.expected, captured)
+			}
+		})
+	}
+}
+
+func TestCreateRecipe_InvalidVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(req *CreateRecipeRequest)
+		repoErr error
+	}{
+		{"no variant", func(req *CreateRecipeRequest) { req.VariantIDs = nil }, nil},
+		{"non-positive variant", func(req *CreateRecipeRequest) { req.VariantIDs = []int32{2, 0} }, nil},
+		{"unknown variant", func(req *CreateRecipeRequest) { req.VariantIDs = []int32{99} }, repository.ErrUnknownReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockRecipesRepository{
+				createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+					return 0, tt.repoErr
+				},
+			}
+			service := NewRecipesService(mockRepo)
+
+			req := validCreateRequest()
+			tt.modify(&req)
+
+			_, err := service.CreateRecipe(context.Background(), req)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestListRecipes_TagFilters(t *testing.T) {
+	var captured repository.RecipeFilter
+	mockRepo := &mockRecipesRepository{
+		countRecipesFunc: func(ctx context.Context, params repository.RecipeFilter) (int64, error) {
+			captured = params
+			return 1, nil
+		},
+		listRecipesFunc: func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+			return []repository.RecipeRow{{ID: 6, Title: "Soto Ayam"}}, nil
+		},
+		listTagsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.ListRecipeTagsRow, error) {
+			return []db.ListRecipeTagsRow{{RecipeID: 6, Name: "comfort-food"}, {RecipeID: 6, Name: "one-pot"}}, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	result, err := service.ListRecipes(context.Background(), RecipeFilters{
+		TagsAny: []string{"One Pot", "one_pot", "Street-Food"},
+		TagsAll: []string{" comfort food "},
+		Page:    1,
+		PerPage: 10,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(captured.TagsAny, []string{"one-pot", "street-food"}) {
+		t.Errorf("Expected normalized tags_any, got %v", captured.TagsAny)
+	}
+	if !reflect.DeepEqual(captured.TagsAll, []string{"comfort-food"}) {
+		t.Errorf("Expected normalized tags_all, got %v", captured.TagsAll)
+	}
+	if !reflect.DeepEqual(result.Data[0].Tags, []string{"comfort-food", "one-pot"}) {
+		t.Errorf("Expected tags on listed recipe, got %v", result.Data[0].Tags)
+	}
+}
+
+func TestCreateRecipe_Tags(t *testing.T) {
+	var captured []string
+	mockRepo := &mockRecipesRepository{
+		createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+			captured = params.Tags
+			return 7, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	req := validCreateRequest()
+	req.Tags = []string{"Weeknight", "", "weeknight", "Sambal Lovers"}
+
+	if _, err := service.CreateRecipe(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(captured, []string{"weeknight", "sambal-lovers"}) {
+		t.Errorf("Expected normalized tags, got %v", captured)
+	}
+
+	req.Tags = make([]string, maxRecipeTags+1)
+	for i := range req.Tags {
+		req.Tags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	if _, err := service.CreateRecipe(context.Background(), req); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams for too many tags, got %v", err)
+	}
+}
+
+func TestCreateRecipe_InvalidNutrition(t *testing.T) {
+	negativeFat := -1.0
+	tooManyCalories := int32(9000)
+
+	tests := []struct {
+		name   string
+		modify func(req *CreateRecipeRequest)
+	}{
+		{"negative fat", func(req *CreateRecipeRequest) { req.Fat = &negativeFat }},
+		{"calories out of range", func(req *CreateRecipeRequest) { req.Calories = &tooManyCalories }},
+		{"unknown health tag", func(req *CreateRecipeRequest) { req.HealthTags = []string{"superfood"} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecipesService(&mockRecipesRepository{})
+
+			req := validCreateRequest()
+			tt.modify(&req)
+
+			_, err := service.CreateRecipe(context.Background(), req)
+
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateRecipe_ParsesFreeTextIngredients(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+			if len(params.IngredientItems) != 3 {
+				t.Fatalf("Expected 3 structured ingredients, got %d", len(params.IngredientItems))
+			}
+			if params.IngredientItems[2].Name != "peanut sauce" {
+				t.Errorf("Expected third ingredient 'peanut sauce', got %q", params.IngredientItems[2].Name)
+			}
+			return 7, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	if _, err := service.CreateRecipe(context.Background(), validCreateRequest()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCreateRecipe_StructuredIngredients(t *testing.T) {
+	qty := 2.0
+	unit := "cups"
+
+	mockRepo := &mockRecipesRepository{
+		createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+			if params.Ingredients != "2 cups cooked rice, 1 egg" {
+				t.Errorf("Expected rendered ingredients text, got %q", params.Ingredients)
+			}
+			return 7, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	one := 1.0
+	req := validCreateRequest()
+	req.Ingredients = ""
+	req.IngredientItems = []Ingredient{
+		{Quantity: &qty, Unit: &unit, Name: "cooked rice"},
+		{Quantity: &one, Name: "egg"},
+	}
+
+	if _, err := service.CreateRecipe(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req.IngredientItems = []Ingredient{{Name: "  "}}
+	if _, err := service.CreateRecipe(context.Background(), req); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams for unnamed ingredient, got %v", err)
+	}
+}
+
+func TestCreateRecipe_InvalidStepIngredientRef(t *testing.T) {
+	service := NewRecipesService(&mockRecipesRepository{})
+
+	req := validCreateRequest()
+	req.Steps = []Step{{Text: "Blanch vegetables", IngredientRefs: []int32{9}}}
+
+	_, err := service.CreateRecipe(context.Background(), req)
+
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+}
+
+func TestCreateRecipe_DetectsAllergens(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		createRecipeFunc: func(ctx context.Context, params repository.CreateRecipeParams) (int64, error) {
+			if !reflect.DeepEqual(params.Allergens, []string{"peanut", "soy"}) {
+				t.Errorf("Expected peanut and soy allergens, got %v", params.Allergens)
+			}
+			return 1, nil
+		},
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	if _, err := service.CreateRecipe(context.Background(), validCreateRequest()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
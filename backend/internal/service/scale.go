@@ -0,0 +1,92 @@
+package service
+
+import "math"
+
+// maxScaledServings caps the servings a recipe can be scaled to
+const maxScaledServings = 100
+
+// measuredUnits are volume units measured with spoons and cups, which are
+// rounded to the nearest eighth. Other fractions (1/3, 2/3) are kept when
+// they are closer.
+var measuredUnits = map[string]bool{
+	"cup": true, "tbsp": true, "tsp": true,
+}
+
+// ScaleIngredients multiplies every quantified ingredient by factor and
+// rounds the result to an amount that can be measured in a kitchen. Items
+// without a quantity ("salt to taste") are returned unchanged.
+func ScaleIngredients(items []Ingredient, factor float64) []Ingredient {
+	scaled := make([]Ingredient, len(items))
+	for i, item := range items {
+		scaled[i] = item
+		if item.Quantity == nil {
+			continue
+		}
+
+		unit := ""
+		if item.Unit != nil {
+			unit = NormalizeUnit(*item.Unit)
+		}
+		quantity := roundKitchen(*item.Quantity*factor, unit)
+		scaled[i].Quantity = &quantity
+	}
+	return scaled
+}
+
+// roundKitchen rounds a scaled quantity based on how the unit is measured.
+// A positive amount never rounds down to zero.
+func roundKitchen(q float64, unit string) float64 {
+	if q <= 0 {
+		return 0
+	}
+
+	var step float64
+	switch {
+	case unit == "g" || unit == "ml":
+		switch {
+		case q >= 100:
+			step = 5
+		case q >= 10:
+			step = 1
+		default:
+			step = 0.5
+		}
+	case unit == "kg" || unit == "l":
+		step = 0.05
+	case unit == "mg":
+		step = 1
+	case measuredUnits[unit]:
+		return roundToFraction(q)
+	default:
+		// Countable items: halves for small amounts, whole numbers otherwise
+		if q < 5 {
+			step = 0.5
+		} else {
+			step = 1
+		}
+	}
+
+	rounded := math.Round(q/step) * step
+	if rounded == 0 {
+		rounded = step
+	}
+	return math.Round(rounded*1000) / 1000
+}
+
+// roundToFraction rounds to the closest eighth or third
+func roundToFraction(q float64) float64 {
+	whole, frac := math.Modf(q)
+
+	best := math.Round(frac*8) / 8
+	for _, third := range []float64{1.0 / 3, 2.0 / 3} {
+		if math.Abs(frac-third) < math.Abs(frac-best) {
+			best = third
+		}
+	}
+
+	rounded := whole + best
+	if rounded == 0 {
+		rounded = 1.0 / 8
+	}
+	return rounded
+}
@@ -0,0 +1,43 @@
+package service
+
+import "testing"
+
+func TestScaleIngredients(t *testing.T) {
+	items := ParseIngredients("1/2 cup coconut milk, 300g egg noodles, 3 cloves garlic, " +
+		"1 tsp salt, 2 eggs, Salt and pepper to taste")
+
+	tests := []struct {
+		factor float64
+		want   []string
+	}{
+		{2, []string{"1 cup coconut milk", "600g egg noodles", "6 cloves garlic", "2 tsp salt", "4 eggs", "Salt and pepper to taste"}},
+		{0.5, []string{"1/4 cup coconut milk", "150g egg noodles", "1 1/2 cloves garlic", "1/2 tsp salt", "1 eggs", "Salt and pepper to taste"}},
+		{1.5, []string{"3/4 cup coconut milk", "450g egg noodles", "4 1/2 cloves garlic", "1 1/2 tsp salt", "3 eggs", "Salt and pepper to taste"}},
+		{1.0 / 3, []string{"1/8 cup coconut milk", "100g egg noodles", "1 clove garlic", "1/3 tsp salt", "1/2 eggs", "Salt and pepper to taste"}},
+	}
+
+	for _, tt := range tests {
+		scaled := ScaleIngredients(items, tt.factor)
+		for i, want := range tt.want {
+			if got := FormatIngredient(scaled[i]); got != want {
+				t.Errorf("factor %.2f, item %d: expected %q, got %q", tt.factor, i, want, got)
+			}
+		}
+	}
+
+	if *items[0].Quantity != 0.5 {
+		t.Errorf("Expected original quantities to be untouched, got %v", *items[0].Quantity)
+	}
+}
+
+func TestRoundKitchen_NeverZero(t *testing.T) {
+	if got := roundKitchen(0.01, "tsp"); got != 0.125 {
+		t.Errorf("Expected 1/8 tsp, got %v", got)
+	}
+	if got := roundKitchen(0.1, "g"); got != 0.5 {
+		t.Errorf("Expected 0.5g, got %v", got)
+	}
+	if got := roundKitchen(0.1, ""); got != 0.5 {
+		t.Errorf("Expected half an item, got %v", got)
+	}
+}
@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxShoppingListRecipes caps the recipes of one shopping list; it fits
+// every entry of the longest meal plan
+const maxShoppingListRecipes = maxMealPlanEntries
+
+// aisleOrder lists store aisles in the order a shopping list shows them,
+// with their headings
+var aisleOrder = []struct {
+	name  string
+	title string
+}{
+	{"produce", "Produce"},
+	{"meat", "Meat"},
+	{"seafood", "Seafood"},
+	{"dairy", "Dairy & eggs"},
+	{"spices", "Spices"},
+	{"pantry", "Pantry"},
+	{"other", "Other"},
+}
+
+// aisleKeywords maps ingredient words to the aisle they are bought in.
+// Keywords are singular. Longer keywords are matched first so "coconut
+// milk" goes to the pantry and "chicken stock" is not read as meat.
+var aisleKeywords = map[string][]string{
+	"produce": {
+		"garlic", "shallot", "onion", "spring onion", "scallion", "leek", "tomato",
+		"chili", "chilli", "bird's eye chili", "bell pepper", "carrot", "potato",
+		"sweet potato", "cassava", "cabbage", "bok choy", "spinach", "water spinach",
+		"kangkung", "lettuce", "broccoli", "cauliflower", "bean sprout", "green bean",
+		"long bean", "cucumber", "eggplant", "pumpkin", "corn", "mushroom", "celery",
+		"lime", "lemon", "lemongrass", "galangal", "ginger", "turmeric", "lime leaf",
+		"kaffir lime leaf", "bay leaf", "salam leaf", "pandan leaf", "basil", "mint",
+		"cilantro", "coriander leaf", "banana", "pineapple", "mango", "avocado",
+		"papaya", "jackfruit",
+	},
+	"meat": {
+		"chicken", "beef", "ground beef", "pork", "lamb", "goat", "mutton", "duck",
+		"bacon", "sausage", "meat", "liver", "oxtail",
+	},
+	"seafood": {
+		"fish", "shrimp", "prawn", "squid", "crab", "clam", "mussel", "anchovy",
+		"tuna", "salmon", "mackerel", "snapper", "tilapia", "catfish", "milkfish",
+	},
+	"dairy": {
+		"milk", "butter", "cheese", "cream", "yogurt", "ghee", "parmesan",
+		"mozzarella", "egg",
+	},
+	"spices": {
+		"salt", "pepper", "black pepper", "white pepper", "coriander",
+		"ground coriander", "coriander seed", "cumin", "nutmeg", "clove", "cinnamon",
+		"star anise", "cardamom", "ground turmeric", "turmeric powder",
+		"chili powder", "chili flake", "paprika", "candlenut", "curry powder",
+		"bouillon powder", "msg",
+	},
+	"pantry": {
+		"rice", "flour", "cornstarch", "sugar", "palm sugar", "oil", "soy sauce",
+		"sweet soy sauce", "kecap manis", "oyster sauce", "fish sauce", "vinegar",
+		"noodle", "egg noodle", "rice noodle", "pasta", "spaghetti", "coconut milk",
+		"coconut cream", "shrimp paste", "terasi", "tamarind", "stock", "broth",
+		"chicken stock", "beef stock", "chicken broth", "beef broth", "breadcrumb",
+		"peanut", "peanut butter", "cashew", "honey", "bread",
+	},
+}
+
+// aisleMatchers are aisleKeywords flattened and ordered longest first
+var aisleMatchers = func() []struct{ keyword, aisle string } {
+	var matchers []struct{ keyword, aisle string }
+	for aisle, keywords := range aisleKeywords {
+		for _, keyword := range keywords {
+			matchers = append(matchers, struct{ keyword, aisle string }{foodName(keyword), aisle})
+		}
+	}
+	sort.Slice(matchers, func(i, j int) bool {
+		if len(matchers[i].keyword) != len(matchers[j].keyword) {
+			return len(matchers[i].keyword) > len(matchers[j].keyword)
+		}
+		return matchers[i].keyword < matchers[j].keyword
+	})
+	return matchers
+}()
+
+// aisleFor returns the aisle of a food name, or "other"
+func aisleFor(name string) string {
+	for _, m := range aisleMatchers {
+		if containsWord(name, m.keyword) {
+			return m.aisle
+		}
+	}
+	return "other"
+}
+
+// usUnits are the units of US kitchens; totals made only of these stay in
+// US units
+var usUnits = map[string]bool{
+	"oz": true, "lb": true, "tsp": true, "tbsp": true, "fl oz": true, "cup": true,
+}
+
+// ShoppingListRecipe is a recipe to shop for. Servings scales its
+// ingredients; zero keeps the recipe's own servings.
+type ShoppingListRecipe struct {
+	RecipeID int32  `json:"recipe_id"`
+	Title    string `json:"title,omitempty"`
+	Servings int32  `json:"servings"`
+}
+
+// ShoppingListRequest holds the recipes to build a shopping list from.
+// Units optionally converts quantities to metric or us.
+type ShoppingListRequest struct {
+	Recipes []ShoppingListRecipe `json:"recipes"`
+	Units   string               `json:"units,omitempty"`
+}
+
+// ShoppingList is the merged ingredients of some recipes, grouped by aisle
+type ShoppingList struct {
+	// Name is the meal plan's name for a plan's list
+	Name string `json:"name,omitempty"`
+	// Recipes lists each recipe once with its total servings
+	Recipes []ShoppingListRecipe `json:"recipes"`
+	Aisles  []ShoppingAisle      `json:"aisles"`
+}
+
+// ShoppingAisle holds the items bought in one aisle
+type ShoppingAisle struct {
+	Aisle string             `json:"aisle"`
+	Title string             `json:"title"`
+	Items []ShoppingListItem `json:"items"`
+}
+
+// ShoppingListItem is one ingredient to buy. The same ingredient is listed
+// twice when its amounts cannot be added up, e.g. cloves and grams.
+type ShoppingListItem struct {
+	Name     string   `json:"name"`
+	Quantity *float64 `json:"quantity"`
+	Unit     *string  `json:"unit"`
+	// Text is the item as a readable line, e.g. "6 cloves garlic"
+	Text string `json:"text"`
+	// Staple marks pantry staples most kitchens already have
+	Staple    bool    `json:"staple"`
+	RecipeIDs []int32 `json:"recipe_ids"`
+}
+
+// shoppingAmount sums the quantities of one ingredient that can be added
+// up: mass in grams, volume in millilitres, and anything else per unit
+type shoppingAmount struct {
+	kind  unitKind
+	unit  string
+	total float64
+	// units are the units summed, which decide how the total is shown
+	units map[string]bool
+}
+
+// shoppingEntry collects one ingredient across recipes
+type shoppingEntry struct {
+	name    string
+	amounts []*shoppingAmount
+	recipes []int32
+}
+
+func (e *shoppingEntry) add(recipeID int32, quantity *float64, unit string) {
+	if len(e.recipes) == 0 || e.recipes[len(e.recipes)-1] != recipeID {
+		e.recipes = append(e.recipes, recipeID)
+	}
+	if quantity == nil {
+		return
+	}
+
+	kind, total := kindCount, *quantity
+	if info, ok := units[unit]; ok {
+		kind, total = info.kind, *quantity*info.base
+	}
+
+	for _, amount := range e.amounts {
+		if amount.kind == kind && (kind != kindCount || amount.unit == unit) {
+			amount.total += total
+			amount.units[unit] = true
+			return
+		}
+	}
+	e.amounts = append(e.amounts, &shoppingAmount{
+		kind:  kind,
+		unit:  unit,
+		total: total,
+		units: map[string]bool{unit: true},
+	})
+}
+
+// foldVolume adds a volume amount into the mass amount when the
+// ingredient's density is known, so "200 g sugar" and "1 cup sugar" make
+// one item
+func (e *shoppingEntry) foldVolume() {
+	var mass, volume *shoppingAmount
+	for _, amount := range e.amounts {
+		switch amount.kind {
+		case kindMass:
+			mass = amount
+		case kindVolume:
+			volume = amount
+		}
+	}
+	if mass == nil || volume == nil {
+		return
+	}
+	density, ok := densityFor(e.name)
+	if !ok {
+		return
+	}
+
+	mass.total += volume.total * density
+	for unit := range volume.units {
+		mass.units[unit] = true
+	}
+	amounts := e.amounts[:0]
+	for _, amount := range e.amounts {
+		if amount != volume {
+			amounts = append(amounts, amount)
+		}
+	}
+	e.amounts = amounts
+}
+
+// quantity renders the total in the single unit that was summed, or in the
+// unit system the summed units came from when they were mixed
+func (a *shoppingAmount) quantity() (float64, string) {
+	if a.kind == kindCount {
+		return roundKitchen(a.total, a.unit), a.unit
+	}
+	if len(a.units) == 1 {
+		return roundKitchen(a.total/units[a.unit].base, a.unit), a.unit
+	}
+
+	us := true
+	for unit := range a.units {
+		us = us && usUnits[unit]
+	}
+	switch {
+	case a.kind == kindMass && us:
+		if a.total >= units["lb"].base {
+			return roundKitchen(a.total/units["lb"].base, "lb"), "lb"
+		}
+		return roundKitchen(a.total/units["oz"].base, "oz"), "oz"
+	case a.kind == kindMass:
+		return metricMass(a.total)
+	case us:
+		return usVolume(a.total)
+	case a.total >= units["l"].base:
+		return roundKitchen(a.total/units["l"].base, "l"), "l"
+	}
+	return roundKitchen(a.total, "ml"), "ml"
+}
+
+// BuildShoppingList merges the ingredients of the requested recipes,
+// scaled to their servings, into one list grouped by aisle. The same
+// recipe may be requested more than once; its servings add up.
+func (s *recipesService) BuildShoppingList(ctx context.Context, req ShoppingListRequest) (*ShoppingList, error) {
+	var system UnitSystem
+	if req.Units != "" {
+		var err error
+		if system, err = ParseUnitSystem(req.Units); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Recipes) == 0 {
+		return nil, fmt.Errorf("%w: at least one recipe is required", ErrInvalidParams)
+	}
+	if len(req.Recipes) > maxShoppingListRecipes {
+		return nil, fmt.Errorf("%w: at most %d recipes are allowed", ErrInvalidParams, maxShoppingListRecipes)
+	}
+
+	ids := make([]int32, len(req.Recipes))
+	for i, item := range req.Recipes {
+		if item.RecipeID < 1 {
+			return nil, fmt.Errorf("%w: invalid recipe_id", ErrInvalidParams)
+		}
+		if item.Servings < 0 || item.Servings > maxScaledServings {
+			return nil, fmt.Errorf("%w: servings must be between 1 and %d", ErrInvalidParams, maxScaledServings)
+		}
+		ids[i] = item.RecipeID
+	}
+
+	rows, err := s.repo.ListRecipesByIDs(ctx, uniqueIDs(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipes: %w", err)
+	}
+
+	recipes := make(map[int32]*Recipe, len(rows))
+	refs := make([]*Recipe, 0, len(rows))
+	for _, row := range rows {
+		recipe := new(Recipe)
+		*recipe = recipeFromRow(row)
+		recipes[row.ID] = recipe
+		refs = append(refs, recipe)
+	}
+	if err := s.attachIngredients(ctx, refs...); err != nil {
+		return nil, err
+	}
+
+	list := &ShoppingList{Recipes: []ShoppingListRecipe{}}
+	servings := make(map[int32]int32, len(recipes))
+	for _, item := range req.Recipes {
+		recipe, ok := recipes[item.RecipeID]
+		if !ok {
+			return nil, fmt.Errorf("%w: recipe %d", ErrRecipeNotFound, item.RecipeID)
+		}
+		if _, seen := servings[recipe.ID]; !seen {
+			list.Recipes = append(list.Recipes, ShoppingListRecipe{RecipeID: recipe.ID, Title: recipe.Title})
+		}
+		if item.Servings == 0 {
+			servings[recipe.ID] += recipe.Servings
+		} else {
+			servings[recipe.ID] += item.Servings
+		}
+	}
+
+	entries := make(map[string]*shoppingEntry)
+	var order []string
+	for i := range list.Recipes {
+		recipe := recipes[list.Recipes[i].RecipeID]
+		list.Recipes[i].Servings = servings[recipe.ID]
+
+		// Recipes without servings are shopped for as written
+		factor := 1.0
+		if recipe.Servings > 0 {
+			factor = float64(servings[recipe.ID]) / float64(recipe.Servings)
+		}
+
+		for _, item := range recipe.IngredientItems {
+			key := foodName(item.Name)
+			if key == "" {
+				continue
+			}
+			entry, ok := entries[key]
+			if !ok {
+				entry = &shoppingEntry{name: strings.ToLower(strings.TrimSpace(item.Name))}
+				entries[key] = entry
+				order = append(order, key)
+			}
+
+			unit := ""
+			if item.Unit != nil {
+				unit = NormalizeUnit(*item.Unit)
+			}
+			var quantity *float64
+			if item.Quantity != nil {
+				scaled := *item.Quantity * factor
+				quantity = &scaled
+			}
+			entry.add(recipe.ID, quantity, unit)
+		}
+	}
+
+	byAisle := make(map[string][]ShoppingListItem)
+	for _, key := range order {
+		entry := entries[key]
+		entry.foldVolume()
+
+		items := make([]ShoppingListItem, 0, len(entry.amounts))
+		for _, amount := range entry.amounts {
+			quantity, unit := amount.quantity()
+			item := ShoppingListItem{Name: entry.name, Quantity: &quantity}
+			if unit != "" {
+				item.Unit = &unit
+			}
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			items = append(items, ShoppingListItem{Name: entry.name})
+		}
+
+		aisle := aisleFor(key)
+		for _, item := range items {
+			if system != "" {
+				converted := ConvertIngredients([]Ingredient{{Quantity: item.Quantity, Unit: item.Unit, Name: item.Name}}, system)[0]
+				item.Quantity, item.Unit = converted.Quantity, converted.Unit
+			}
+			item.Text = FormatIngredient(Ingredient{Quantity: item.Quantity, Unit: item.Unit, Name: item.Name})
+			item.Staple = pantryStaples[key]
+			item.RecipeIDs = entry.recipes
+			byAisle[aisle] = append(byAisle[aisle], item)
+		}
+	}
+
+	list.Aisles = []ShoppingAisle{}
+	for _, aisle := range aisleOrder {
+		items := byAisle[aisle.name]
+		if len(items) == 0 {
+			continue
+		}
+		// Staples go last since they are usually already at home
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Staple != items[j].Staple {
+				return !items[i].Staple
+			}
+			return items[i].Name < items[j].Name
+		})
+		list.Aisles = append(list.Aisles, ShoppingAisle{Aisle: aisle.name, Title: aisle.title, Items: items})
+	}
+
+	return list, nil
+}
+
+// FormatShoppingList renders a shopping list as Markdown: a section per
+// aisle with a checkbox per item. Staples are marked so they can be
+// skipped.
+func FormatShoppingList(list *ShoppingList) string {
+	var b strings.Builder
+
+	b.WriteString("# Shopping list")
+	if list.Name != "" {
+		b.WriteString(": " + list.Name)
+	}
+	b.WriteString("\n")
+
+	if len(list.Recipes) > 0 {
+		recipes := make([]string, len(list.Recipes))
+		for i, recipe := range list.Recipes {
+			recipes[i] = fmt.Sprintf("%s (%d servings)", recipe.Title, recipe.Servings)
+		}
+		b.WriteString("\nFor " + strings.Join(recipes, ", ") + "\n")
+	}
+
+	for _, aisle := range list.Aisles {
+		b.WriteString("\n## " + aisle.Title + "\n\n")
+		for _, item := range aisle.Items {
+			b.WriteString("- [ ] " + item.Text)
+			if item.Staple {
+				b.WriteString(" _(pantry staple)_")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// shoppingRepository holds Nasi Goreng (recipe 1, 2 servings) and Ayam
+// Goreng (recipe 2, 4 servings)
+func shoppingRepository() *mockRecipesRepository {
+	text := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	ingredients := []db.RecipeIngredient{
+		{RecipeID: 1, Position: 1, Quantity: text("2"), Unit: text("cups"), Name: "cooked rice"},
+		{RecipeID: 1, Position: 2, Quantity: text("3"), Unit: text("cloves"), Name: "garlic"},
+		{RecipeID: 1, Position: 3, Quantity: text("2"), Unit: text("tbsp"), Name: "sweet soy sauce"},
+		{RecipeID: 1, Position: 4, Quantity: text("100"), Unit: text("g"), Name: "chicken"},
+		{RecipeID: 1, Position: 5, Quantity: text("50"), Unit: text("g"), Name: "sugar"},
+		{RecipeID: 1, Position: 6, Quantity: text("1"), Unit: text("tbsp"), Name: "oil"},
+		{RecipeID: 1, Position: 7, Name: "salt", Note: text("to taste")},
+		{RecipeID: 2, Position: 1, Quantity: text("500"), Unit: text("g"), Name: "Chicken"},
+		{RecipeID: 2, Position: 2, Quantity: text("4"), Unit: text("cloves"), Name: "garlic"},
+		{RecipeID: 2, Position: 3, Quantity: text("0.5"), Unit: text("cup"), Name: "sugar"},
+		{RecipeID: 2, Position: 4, Quantity: text("1"), Unit: text("tsp"), Name: "oil"},
+		{RecipeID: 2, Position: 5, Quantity: text("1"), Unit: text("tsp"), Name: "salt"},
+	}
+
+	return &mockRecipesRepository{
+		listByIDsFunc: func(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error) {
+			catalog := []repository.RecipeRow{
+				{ID: 1, Title: "Nasi Goreng", Servings: 2},
+				{ID: 2, Title: "Ayam Goreng", Servings: 4},
+			}
+			var rows []repository.RecipeRow
+			for _, row := range catalog {
+				if containsID(recipeIDs, row.ID) {
+					rows = append(rows, row)
+				}
+			}
+			return rows, nil
+		},
+		listIngredientsFunc: func(ctx context.Context, recipeIDs []int32) ([]db.RecipeIngredient, error) {
+			var rows []db.RecipeIngredient
+			for _, row := range ingredients {
+				if containsID(recipeIDs, row.RecipeID) {
+					rows = append(rows, row)
+				}
+			}
+			return rows, nil
+		},
+	}
+}
+
+// shoppingItems indexes a list's items by aisle and text
+func shoppingItems(list *ShoppingList) map[string]ShoppingListItem {
+	items := make(map[string]ShoppingListItem)
+	for _, aisle := range list.Aisles {
+		for _, item := range aisle.Items {
+			items[aisle.Aisle+": "+item.Text] = item
+		}
+	}
+	return items
+}
+
+func TestBuildShoppingList(t *testing.T) {
+	service := NewRecipesService(shoppingRepository())
+
+	list, err := service.BuildShoppingList(context.Background(), ShoppingListRequest{
+		Recipes: []ShoppingListRecipe{{RecipeID: 1, Servings: 4}, {RecipeID: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedRecipes := []ShoppingListRecipe{
+		{RecipeID: 1, Title: "Nasi Goreng", Servings: 4},
+		{RecipeID: 2, Title: "Ayam Goreng", Servings: 4},
+	}
+	if !reflect.DeepEqual(list.Recipes, expectedRecipes) {
+		t.Errorf("Expected recipes %+v, got %+v", expectedRecipes, list.Recipes)
+	}
+
+	items := shoppingItems(list)
+	expected := []string{
+		"produce: 10 cloves garlic",
+		"meat: 700g chicken",
+		"pantry: 4 cups cooked rice",
+		"pantry: 4 tbsp sweet soy sauce",
+		// 100 g plus half a cup at 200 g per cup
+		"pantry: 200g sugar",
+		// 2 tbsp plus 1 tsp
+		"pantry: 2 1/3 tbsp oil",
+		"spices: 1 tsp salt",
+	}
+	for _, text := range expected {
+		if _, ok := items[text]; !ok {
+			t.Errorf("Expected item %q, got %v", text, reflect.ValueOf(items).MapKeys())
+		}
+	}
+	if len(items) != len(expected) {
+		t.Errorf("Expected %d items, got %d", len(expected), len(items))
+	}
+
+	salt := items["spices: 1 tsp salt"]
+	if !salt.Staple || !reflect.DeepEqual(salt.RecipeIDs, []int32{1, 2}) {
+		t.Errorf("Expected salt to be a staple from recipes 1 and 2, got %+v", salt)
+	}
+	if items["produce: 10 cloves garlic"].Staple {
+		t.Error("Expected garlic not to be a staple")
+	}
+
+	var aisles []string
+	for _, aisle := range list.Aisles {
+		aisles = append(aisles, aisle.Aisle)
+	}
+	if !reflect.DeepEqual(aisles, []string{"produce", "meat", "spices", "pantry"}) {
+		t.Errorf("Expected aisles in store order, got %v", aisles)
+	}
+}
+
+func TestBuildShoppingList_RepeatedRecipe(t *testing.T) {
+	service := NewRecipesService(shoppingRepository())
+
+	list, err := service.BuildShoppingList(context.Background(), ShoppingListRequest{
+		Recipes: []ShoppingListRecipe{{RecipeID: 2}, {RecipeID: 2, Servings: 2}},
+		Units:   "us",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(list.Recipes) != 1 || list.Recipes[0].Servings != 6 {
+		t.Fatalf("Expected recipe 2 once with 6 servings, got %+v", list.Recipes)
+	}
+
+	items := shoppingItems(list)
+	if _, ok := items["produce: 6 cloves garlic"]; !ok {
+		t.Errorf("Expected 6 cloves garlic, got %v", reflect.ValueOf(items).MapKeys())
+	}
+	if _, ok := items["meat: 1 1/2 lb chicken"]; !ok {
+		t.Errorf("Expected chicken in pounds, got %v", reflect.ValueOf(items).MapKeys())
+	}
+}
+
+func TestBuildShoppingList_InvalidParams(t *testing.T) {
+	service := NewRecipesService(shoppingRepository())
+
+	tests := []struct {
+		name string
+		req  ShoppingListRequest
+		err  error
+	}{
+		{"no recipes", ShoppingListRequest{}, ErrInvalidParams},
+		{"invalid recipe ID", ShoppingListRequest{Recipes: []ShoppingListRecipe{{RecipeID: 0}}}, ErrInvalidParams},
+		{"too many servings", ShoppingListRequest{Recipes: []ShoppingListRecipe{{RecipeID: 1, Servings: 101}}}, ErrInvalidParams},
+		{"invalid units", ShoppingListRequest{Recipes: []ShoppingListRecipe{{RecipeID: 1}}, Units: "cubits"}, ErrInvalidParams},
+		{"unknown recipe", ShoppingListRequest{Recipes: []ShoppingListRecipe{{RecipeID: 1}, {RecipeID: 9}}}, ErrRecipeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.BuildShoppingList(context.Background(), tt.req)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestFormatShoppingList(t *testing.T) {
+	service := NewRecipesService(shoppingRepository())
+
+	list, err := service.BuildShoppingList(context.Background(), ShoppingListRequest{
+		Recipes: []ShoppingListRecipe{{RecipeID: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	list.Name = "Week 4"
+
+	expected := `# Shopping list: Week 4
+
+For Ayam Goreng (4 servings)
+
+## Produce
+
+- [ ] 4 cloves garlic
+
+## Meat
+
+- [ ] 500g chicken
+
+## Spices
+
+- [ ] 1 tsp salt _(pantry staple)_
+
+## Pantry
+
+- [ ] 1 tsp oil
+- [ ] 1/2 cup sugar
+`
+	if got := FormatShoppingList(list); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestAisleFor(t *testing.T) {
+	tests := map[string]string{
+		"shallot":       "produce",
+		"bell pepper":   "produce",
+		"black pepper":  "spices",
+		"chicken thigh": "meat",
+		"chicken stock": "pantry",
+		"milk":          "dairy",
+		"coconut milk":  "pantry",
+		"egg":           "dairy",
+		"egg noodle":    "pantry",
+		"fish sauce":    "pantry",
+		"tofu":          "other",
+	}
+
+	for name, expected := range tests {
+		if got := aisleFor(foodName(name)); got != expected {
+			t.Errorf("%s: expected aisle %s, got %s", name, expected, got)
+		}
+	}
+}
+
+func TestGetMealPlanShoppingList(t *testing.T) {
+	repo := &mockMealPlansRepository{entries: []db.ListMealPlanEntriesRow{
+		{ID: 1, PlanDate: mealPlanDate("2026-01-19"), Meal: "dinner", RecipeID: 1, RecipeServings: 2},
+		{ID: 2, PlanDate: mealPlanDate("2026-01-21"), Meal: "dinner", RecipeID: 1, RecipeServings: 2, Servings: sql.NullInt32{Int32: 3, Valid: true}},
+	}}
+	service := NewMealPlansService(repo, NewRecipesService(shoppingRepository()))
+
+	list, err := service.GetMealPlanShoppingList(context.Background(), "kitchen-1", 1, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if list.Name != "Week 4" {
+		t.Errorf("Expected the plan's name, got %q", list.Name)
+	}
+	if len(list.Recipes) != 1 || list.Recipes[0].Servings != 5 {
+		t.Errorf("Expected recipe 1 with 5 servings, got %+v", list.Recipes)
+	}
+	if !strings.Contains(FormatShoppingList(list), "- [ ] 8 cloves garlic\n") {
+		t.Errorf("Expected 8 cloves garlic, got:\n%s", FormatShoppingList(list))
+	}
+
+	repo.entries = nil
+	list, err = service.GetMealPlanShoppingList(context.Background(), "kitchen-1", 1, "")
+	if err != nil || len(list.Aisles) != 0 {
+		t.Errorf("Expected an empty list for an empty plan, got %+v, %v", list, err)
+	}
+
+	if _, err := service.GetMealPlanShoppingList(context.Background(), "kitchen-1", 2, ""); !errors.Is(err, ErrMealPlanNotFound) {
+		t.Errorf("Expected ErrMealPlanNotFound, got %v", err)
+	}
+}
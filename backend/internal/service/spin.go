@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+const (
+	// maxSpinMultiplier bounds every weight multiplier
+	maxSpinMultiplier = 10
+	// quickCookingTime is the cooking time at which the quick bonus has
+	// worn off completely
+	quickCookingTime = 120
+	// defaultFavoriteBoost applies when favorites are given without a boost
+	defaultFavoriteBoost = 2
+	// recentPenaltySpins is how many of the client's last spins the recent
+	// penalty applies to
+	recentPenaltySpins = 10
+	// defaultExpiringDays is how far ahead pantry items count as expiring
+	// soon, and maxExpiringDays the furthest that can be asked for
+	defaultExpiringDays = 3
+	maxExpiringDays     = 30
+	// maxSpinSeed is the largest seed, the largest integer a JSON client
+	// can hold exactly
+	maxSpinSeed = 1<<53 - 1
+	// maxSpinCandidates bounds the recipes a weighted spin or a meal plan
+	// draws from. When more match, a sample chosen by the seed is used.
+	maxSpinCandidates = 1000
+)
+
+// SpinResult is the recipe a spin landed on
+type SpinResult struct {
+	Recipe *Recipe
+	// Probability is the chance the spin had of landing on Recipe, out of
+	// Candidates recipes
+	Probability float64
+	Candidates  int
+	// RecentRepeat is set when every matching recipe was spun recently, so
+	// the exclusion had to be relaxed and a recent recipe was returned
+	RecentRepeat bool
+	// Seed replays the spin when sent back with the same filters, as long
+	// as the matching recipes have not changed
+	Seed int64
+}
+
+// SpinWeights skews a spin towards some recipes. A recipe's weight starts
+// at 1 and is multiplied by each factor that applies to it; the chance of
+// landing on it is its weight divided by the total over all candidates.
+type SpinWeights struct {
+	// Quick favours short recipes: a recipe taking t minutes is weighted
+	// by 1 + Quick × (1 - t/120), with t capped at 120
+	Quick float64 `json:"quick,omitempty"`
+	// Categories maps a category ID to its multiplier
+	Categories map[int32]float64 `json:"categories,omitempty"`
+	// Favorites are recipe IDs weighted by FavoriteBoost (default 2)
+	Favorites     []int32 `json:"favorites,omitempty"`
+	FavoriteBoost float64 `json:"favorite_boost,omitempty"`
+	// RecentPenalty (0-1) multiplies recipes among the client's last 10
+	// spins
+	RecentPenalty float64 `json:"recent_penalty,omitempty"`
+	// Expiring favours recipes using the client's pantry items that expire
+	// within ExpiringDays (default 3, at most 30): a recipe using n of them
+	// is weighted by 1 + Expiring × n
+	Expiring     float64 `json:"expiring,omitempty"`
+	ExpiringDays int32   `json:"expiring_days,omitempty"`
+}
+
+// validateSpinWeights checks weight ranges and fills in defaults on a copy
+// of the weights
+func validateSpinWeights(filters *RecipeFilters) error {
+	if filters.Weights == nil {
+		return nil
+	}
+	w := *filters.Weights
+	filters.Weights = &w
+
+	if w.Quick < 0 || w.Quick > maxSpinMultiplier {
+		return fmt.Errorf("%w: weights.quick must be between 0 and %d", ErrInvalidParams, maxSpinMultiplier)
+	}
+	for id, multiplier := range w.Categories {
+		if id < 1 {
+			return fmt.Errorf("%w: invalid category ID %d in weights.categories", ErrInvalidParams, id)
+		}
+		if multiplier <= 0 || multiplier > maxSpinMultiplier {
+			return fmt.Errorf("%w: weights.categories values must be above 0 and at most %d", ErrInvalidParams, maxSpinMultiplier)
+		}
+	}
+
+	w.Favorites = uniqueIDs(w.Favorites)
+	if w.FavoriteBoost == 0 {
+		w.FavoriteBoost = defaultFavoriteBoost
+	}
+	if w.FavoriteBoost <= 0 || w.FavoriteBoost > maxSpinMultiplier {
+		return fmt.Errorf("%w: weights.favorite_boost must be above 0 and at most %d", ErrInvalidParams, maxSpinMultiplier)
+	}
+
+	if w.RecentPenalty < 0 || w.RecentPenalty > 1 {
+		return fmt.Errorf("%w: weights.recent_penalty must be between 0 and 1", ErrInvalidParams)
+	}
+	if w.RecentPenalty > 0 && filters.ClientID == "" {
+		return fmt.Errorf("%w: weights.recent_penalty needs a client ID", ErrInvalidParams)
+	}
+
+	if w.Expiring < 0 || w.Expiring > maxSpinMultiplier {
+		return fmt.Errorf("%w: weights.expiring must be between 0 and %d", ErrInvalidParams, maxSpinMultiplier)
+	}
+	if w.ExpiringDays == 0 {
+		w.ExpiringDays = defaultExpiringDays
+	}
+	if w.ExpiringDays < 1 || w.ExpiringDays > maxExpiringDays {
+		return fmt.Errorf("%w: weights.expiring_days must be between 1 and %d", ErrInvalidParams, maxExpiringDays)
+	}
+	if w.Expiring > 0 && filters.ClientID == "" {
+		return fmt.Errorf("%w: weights.expiring needs a client ID", ErrInvalidParams)
+	}
+	return nil
+}
+
+// spinWeights computes the weight of each candidate. penalized holds the
+// recipes the recent penalty applies to.
+func spinWeights(pool []repository.SpinCandidate, w *SpinWeights, penalized map[int32]bool) []float64 {
+	weights := make([]float64, len(pool))
+	var favorites map[int32]bool
+	if w != nil {
+		favorites = make(map[int32]bool, len(w.Favorites))
+		for _, id := range w.Favorites {
+			favorites[id] = true
+		}
+	}
+
+	for i, c := range pool {
+		weight := 1.0
+		if w != nil {
+			if w.Quick > 0 {
+				t := c.CookingTime
+				if t > quickCookingTime {
+					t = quickCookingTime
+				}
+				weight *= 1 + w.Quick*(1-float64(t)/quickCookingTime)
+			}
+			if multiplier, ok := w.Categories[c.CategoryID]; ok {
+				weight *= multiplier
+			}
+			if favorites[c.ID] {
+				weight *= w.FavoriteBoost
+			}
+			if w.RecentPenalty > 0 && penalized[c.ID] {
+				weight *= w.RecentPenalty
+			}
+			if w.Expiring > 0 && c.Expiring > 0 {
+				weight *= 1 + w.Expiring*float64(c.Expiring)
+			}
+		}
+		weights[i] = weight
+	}
+	return weights
+}
+
+// drawWeighted picks an index with probability proportional to its
+// weight, given a uniform random value in [0, 1). It returns the index and
+// its probability.
+func drawWeighted(weights []float64, r float64) (int, float64) {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	target := r * total
+	for i, w := range weights {
+		if target < w {
+			return i, w / total
+		}
+		target -= w
+	}
+	// Rounding can leave target just past the last weight
+	last := len(weights) - 1
+	return last, weights[last] / total
+}
+
+// candidateFilter selects the recipes a spin with validated filters can
+// land on, narrowed to what the pantry can cook when one is given
+func candidateFilter(filters RecipeFilters) (repository.RecipeFilter, error) {
+	filter := toRecipeFilter(filters)
+	filter.ExcludeRecipeIDs = filters.ExcludeRecipeIDs
+
+	if len(filters.Pantry) > 0 {
+		pantry, err := normalizePantry(filters.Pantry)
+		if err != nil {
+			return filter, err
+		}
+		if filters.MaxMissing < 0 || filters.MaxMissing > maxMissingLimit {
+			return filter, fmt.Errorf("%w: max_missing must be between 0 and %d", ErrInvalidParams, maxMissingLimit)
+		}
+		filter.Pantry = pantry
+		filter.MaxMissing = filters.MaxMissing
+	}
+	return filter, nil
+}
+
+// candidatePool lists the recipes matching params that a weighted draw is
+// made over, at most maxSpinCandidates of them sampled by its seed
+func (s *recipesService) candidatePool(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error) {
+	params.Limit = maxSpinCandidates
+	candidates, err := s.repo.ListSpinCandidates(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spin candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// pickRecipe loads the recipe at the position r (in [0, 1)) draws among
+// the count recipes matching filter, so each is equally likely
+func (s *recipesService) pickRecipe(ctx context.Context, filter repository.RecipeFilter, count int64, r float64) (repository.RecipeRow, error) {
+	offset := int64(r * float64(count))
+	if offset >= count {
+		offset = count - 1
+	}
+
+	rows, err := s.repo.ListRecipes(ctx, repository.ListRecipesParams{
+		RecipeFilter: filter,
+		Limit:        1,
+		Offset:       int32(offset),
+	})
+	if err != nil {
+		return repository.RecipeRow{}, fmt.Errorf("failed to load recipe: %w", err)
+	}
+	if len(rows) == 0 {
+		return repository.RecipeRow{}, fmt.Errorf("%w: recipe not found", ErrRecipeNotFound)
+	}
+	return rows[0], nil
+}
+
+// penalizedRecipes returns the recipes the recent penalty applies to
+func (s *recipesService) penalizedRecipes(ctx context.Context, filters RecipeFilters) (map[int32]bool, error) {
+	if filters.Weights == nil || filters.Weights.RecentPenalty == 0 {
+		return nil, nil
+	}
+
+	ids, err := s.recentRecipeIDs(ctx, filters.ClientID, &RecentSpins{Spins: recentPenaltySpins})
+	if err != nil {
+		return nil, err
+	}
+
+	penalized := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		penalized[id] = true
+	}
+	return penalized, nil
+}
+
+// resolveSpinSeed checks a requested seed, or picks one when none is given
+func (s *recipesService) resolveSpinSeed(seed *int64) (int64, error) {
+	if seed == nil {
+		return s.spinSeed() & maxSpinSeed, nil
+	}
+	if *seed < 0 || *seed > maxSpinSeed {
+		return 0, fmt.Errorf("%w: seed must be between 0 and %d", ErrInvalidParams, int64(maxSpinSeed))
+	}
+	return *seed, nil
+}
+
+// spinDraw returns the uniform value in [0, 1) a seed draws with
+func spinDraw(seed int64) float64 {
+	return rand.New(rand.NewSource(seed)).Float64()
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Bounds for ExcludeRecent
+const (
+	maxRecentSpins  = 100
+	maxRecentDays   = 90
+	maxClientIDSize = 64
+)
+
+// RecentSpins selects a client's recent spin results to avoid: those of
+// the last Spins spins and those spun in the last Days days. Zero disables
+// either window.
+type RecentSpins struct {
+	Spins int32 `json:"spins,omitempty"`
+	Days  int32 `json:"days,omitempty"`
+}
+
+// userClientIDPrefix starts the client ID of a signed-in user. X-Client-ID
+// headers cannot contain its ':', so an anonymous client cannot pass for a
+// user.
+const userClientIDPrefix = "user:"
+
+// UserClientID returns the client ID a signed-in user's spin history,
+// pantry and meal plans are stored under
+func UserClientID(userID int32) string {
+	return userClientIDPrefix + strconv.FormatInt(int64(userID), 10)
+}
+
+// IsUserClientID reports whether clientID names a signed-in user rather
+// than an anonymous client
+func IsUserClientID(clientID string) bool {
+	id, ok := strings.CutPrefix(clientID, userClientIDPrefix)
+	if !ok {
+		return false
+	}
+	userID, err := strconv.ParseInt(id, 10, 32)
+	return err == nil && userID > 0
+}
+
+// validateClientID checks an X-Client-ID value or a UserClientID; empty is
+// allowed
+func validateClientID(clientID string) error {
+	if IsUserClientID(clientID) {
+		return nil
+	}
+	if len(clientID) > maxClientIDSize {
+		return fmt.Errorf("%w: client ID must be at most %d characters", ErrInvalidParams, maxClientIDSize)
+	}
+	for _, r := range clientID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("%w: client ID may only contain letters, digits, '-' and '_'", ErrInvalidParams)
+		}
+	}
+	return nil
+}
+
+// validateSpinHistory checks the client ID and recent-spin window
+func validateSpinHistory(filters *RecipeFilters) error {
+	if err := validateClientID(filters.ClientID); err != nil {
+		return err
+	}
+
+	recent := filters.ExcludeRecent
+	if recent == nil {
+		return nil
+	}
+	if recent.Spins < 0 || recent.Spins > maxRecentSpins {
+		return fmt.Errorf("%w: exclude_recent.spins must be between 0 and %d", ErrInvalidParams, maxRecentSpins)
+	}
+	if recent.Days < 0 || recent.Days > maxRecentDays {
+		return fmt.Errorf("%w: exclude_recent.days must be between 0 and %d", ErrInvalidParams, maxRecentDays)
+	}
+	if filters.ClientID == "" && (recent.Spins > 0 || recent.Days > 0) {
+		return fmt.Errorf("%w: exclude_recent needs a client ID", ErrInvalidParams)
+	}
+	return nil
+}
+
+// recentRecipeIDs returns the distinct recipes in a client's recent-spin
+// window, most recently spun first
+func (s *recipesService) recentRecipeIDs(ctx context.Context, clientID string, recent *RecentSpins) ([]int32, error) {
+	if clientID == "" || recent == nil {
+		return nil, nil
+	}
+
+	var ids []int32
+	if recent.Spins > 0 {
+		last, err := s.repo.ListLastSpins(ctx, clientID, recent.Spins)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spin history: %w", err)
+		}
+		ids = append(ids, last...)
+	}
+	if recent.Days > 0 {
+		since, err := s.repo.ListSpinsSince(ctx, clientID, recent.Days)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spin history: %w", err)
+		}
+		ids = append(ids, since...)
+	}
+	return uniqueIDs(ids), nil
+}
+
+// avoidRecent narrows filter to leave out the recent recipes and counts
+// the recipes it still matches. When none would be left, it retries
+// avoiding only the more recent half of them, down to none, so older spins
+// are repeated first. relaxed reports that some recent recipes had to be
+// let back in.
+func (s *recipesService) avoidRecent(ctx context.Context, filter repository.RecipeFilter, recent []int32) (repository.RecipeFilter, int64, bool, error) {
+	excluded := filter.ExcludeRecipeIDs[:len(filter.ExcludeRecipeIDs):len(filter.ExcludeRecipeIDs)]
+	for n := len(recent); ; n /= 2 {
+		filter.ExcludeRecipeIDs = append(excluded, recent[:n]...)
+		count, err := s.repo.CountRecipes(ctx, filter)
+		if err != nil {
+			return filter, 0, false, fmt.Errorf("failed to count recipes: %w", err)
+		}
+		if count > 0 || n == 0 {
+			return filter, count, n < len(recent), nil
+		}
+	}
+}
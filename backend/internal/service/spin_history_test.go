@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// spinPool serves the recipes in pool as the matches of every spin
+func spinPool(m *mockRecipesRepository, pool []int32) {
+	catalog := make([]repository.SpinCandidate, len(pool))
+	for i, id := range pool {
+		catalog[i] = repository.SpinCandidate{ID: id}
+	}
+	spinCatalog(m, catalog)
+}
+
+// spinCatalog serves catalog, less the filter's excluded recipes, to plain
+// and weighted spins and loads the recipe chosen
+func spinCatalog(m *mockRecipesRepository, catalog []repository.SpinCandidate) {
+	matching := func(filter repository.RecipeFilter) []repository.SpinCandidate {
+		candidates := []repository.SpinCandidate{}
+		for _, c := range catalog {
+			if !containsID(filter.ExcludeRecipeIDs, c.ID) {
+				candidates = append(candidates, c)
+			}
+		}
+		return candidates
+	}
+	m.countRecipesFunc = func(ctx context.Context, filter repository.RecipeFilter) (int64, error) {
+		return int64(len(matching(filter))), nil
+	}
+	m.listRecipesFunc = func(ctx context.Context, params repository.ListRecipesParams) ([]repository.RecipeRow, error) {
+		candidates := matching(params.RecipeFilter)
+		if int(params.Offset) >= len(candidates) {
+			return []repository.RecipeRow{}, nil
+		}
+		return []repository.RecipeRow{{ID: candidates[params.Offset].ID}}, nil
+	}
+	m.spinCandidatesFunc = func(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error) {
+		return matching(params.RecipeFilter), nil
+	}
+	m.listByIDsFunc = func(ctx context.Context, recipeIDs []int32) ([]repository.RecipeRow, error) {
+		return []repository.RecipeRow{{ID: recipeIDs[0]}}, nil
+	}
+}
+
+func TestGetRandomRecipe_ExcludeRecent(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		lastSpins:  []int32{4, 2, 4, 7},
+		spinsSince: []int32{2, 9},
+	}
+	spinPool(mockRepo, []int32{2, 4, 5, 7, 9})
+
+	service := NewRecipesService(mockRepo)
+
+	result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{
+		ClientID:      "kitchen-1",
+		ExcludeRecent: &RecentSpins{Spins: 3, Days: 7},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Last 3 spins (4, 2) and last 7 days (2, 9) leave 5 and 7
+	if result.Candidates != 2 || result.RecentRepeat {
+		t.Errorf("Expected 2 candidates without repeat, got %d (repeat %v)", result.Candidates, result.RecentRepeat)
+	}
+	if result.Recipe.ID != 5 && result.Recipe.ID != 7 {
+		t.Errorf("Expected recipe 5 or 7, got %d", result.Recipe.ID)
+	}
+	if !reflect.DeepEqual(mockRepo.recordedSpins, []int32{result.Recipe.ID}) {
+		t.Errorf("Expected spin to be recorded, got %v", mockRepo.recordedSpins)
+	}
+}
+
+func TestGetRandomRecipe_ExcludeRecentFallback(t *testing.T) {
+	mockRepo := &mockRecipesRepository{
+		lastSpins: []int32{3, 1, 2},
+	}
+	spinPool(mockRepo, []int32{1, 2, 3})
+
+	service := NewRecipesService(mockRepo)
+
+	result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{
+		ClientID:      "kitchen-1",
+		ExcludeRecent: &RecentSpins{Spins: 10},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Halving keeps only the most recent spin (3) excluded
+	if result.Candidates != 2 || !result.RecentRepeat {
+		t.Errorf("Expected 2 repeated candidates, got %d (repeat %v)", result.Candidates, result.RecentRepeat)
+	}
+	if result.Recipe.ID == 3 {
+		t.Error("Expected the most recent spin to stay excluded")
+	}
+}
+
+func TestGetRandomRecipe_InvalidSpinHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters RecipeFilters
+	}{
+		{"exclude without client", RecipeFilters{ExcludeRecent: &RecentSpins{Spins: 5}}},
+		{"too many spins", RecipeFilters{ClientID: "abc", ExcludeRecent: &RecentSpins{Spins: 500}}},
+		{"negative days", RecipeFilters{ClientID: "abc", ExcludeRecent: &RecentSpins{Days: -1}}},
+		{"malformed client", RecipeFilters{ClientID: "a b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecipesService(&mockRecipesRepository{})
+
+			_, err := service.GetRandomRecipe(context.Background(), tt.filters)
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUserClientID(t *testing.T) {
+	clientID := UserClientID(42)
+	if clientID != "user:42" || !IsUserClientID(clientID) {
+		t.Errorf("Expected user:42 to name a user, got %q", clientID)
+	}
+	if err := validateClientID(clientID); err != nil {
+		t.Errorf("Expected a user's client ID to be valid, got %v", err)
+	}
+
+	for _, clientID := range []string{"kitchen-1", "user:", "user:0", "user:abc", "user-42"} {
+		if IsUserClientID(clientID) {
+			t.Errorf("Expected %q not to name a user", clientID)
+		}
+	}
+	if err := validateClientID("user:abc"); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams for a malformed user ID, got %v", err)
+	}
+}
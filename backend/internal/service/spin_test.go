@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+func TestSpinWeights(t *testing.T) {
+	pool := []repository.SpinCandidate{
+		{ID: 1, CookingTime: 0, CategoryID: 1},
+		{ID: 2, CookingTime: 60, CategoryID: 2},
+		{ID: 3, CookingTime: 240, CategoryID: 3, Expiring: 2},
+	}
+	weights := &SpinWeights{
+		Quick:         2,
+		Categories:    map[int32]float64{2: 3},
+		Favorites:     []int32{3},
+		FavoriteBoost: 4,
+		RecentPenalty: 0.5,
+		Expiring:      1.5,
+	}
+
+	got := spinWeights(pool, weights, map[int32]bool{1: true})
+
+	// 1: quick 3 × penalty 0.5; 2: quick 2 × category 3; 3: quick 1 ×
+	// favorite 4 × expiring (1 + 1.5 × 2)
+	expected := []float64{1.5, 6, 16}
+	for i := range expected {
+		if math.Abs(got[i]-expected[i]) > 1e-9 {
+			t.Errorf("Recipe %d: expected weight %v, got %v", pool[i].ID, expected[i], got[i])
+		}
+	}
+
+	for i, w := range spinWeights(pool, nil, nil) {
+		if w != 1 {
+			t.Errorf("Recipe %d: expected uniform weight 1, got %v", pool[i].ID, w)
+		}
+	}
+}
+
+func TestDrawWeighted(t *testing.T) {
+	weights := []float64{1, 2, 1}
+
+	tests := []struct {
+		r           float64
+		index       int
+		probability float64
+	}{
+		{0, 0, 0.25},
+		{0.24, 0, 0.25},
+		{0.25, 1, 0.5},
+		{0.74, 1, 0.5},
+		{0.99, 2, 0.25},
+	}
+
+	for _, tt := range tests {
+		index, probability := drawWeighted(weights, tt.r)
+		if index != tt.index || probability != tt.probability {
+			t.Errorf("r=%v: expected %d (%v), got %d (%v)", tt.r, tt.index, tt.probability, index, probability)
+		}
+	}
+}
+
+func TestGetRandomRecipe_Weighted(t *testing.T) {
+	mockRepo := &mockRecipesRepository{lastSpins: []int32{2}}
+	spinCatalog(mockRepo, []repository.SpinCandidate{
+		{ID: 1, CookingTime: 90, CategoryID: 1},
+		{ID: 2, CookingTime: 15, CategoryID: 4},
+		{ID: 3, CookingTime: 45, CategoryID: 4},
+	})
+	var sampled repository.SpinCandidatesParams
+	candidates := mockRepo.spinCandidatesFunc
+	mockRepo.spinCandidatesFunc = func(ctx context.Context, params repository.SpinCandidatesParams) ([]repository.SpinCandidate, error) {
+		sampled = params
+		return candidates(ctx, params)
+	}
+
+	service := NewRecipesService(mockRepo)
+
+	seed := int64(7)
+	result, err := service.GetRandomRecipe(context.Background(), RecipeFilters{
+		ClientID: "kitchen-1",
+		Seed:     &seed,
+		Weights: &SpinWeights{
+			Categories:    map[int32]float64{4: 3},
+			RecentPenalty: 0.5,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if sampled.Seed != 7 || sampled.Limit != maxSpinCandidates {
+		t.Errorf("Expected a sample of %d chosen by seed 7, got %d by seed %d", maxSpinCandidates, sampled.Limit, sampled.Seed)
+	}
+
+	// Weights 1, 1.5 (category 3 × penalty 0.5) and 3: seed 7 draws 0.92,
+	// which lands on recipe 3
+	if result.Recipe.ID != 3 || math.Abs(result.Probability-3/5.5) > 1e-9 {
+		t.Errorf("Expected recipe 3 with probability %v, got %d with %v", 3/5.5, result.Recipe.ID, result.Probability)
+	}
+}
+
+func TestGetRandomRecipe_InvalidWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights SpinWeights
+	}{
+		{"quick too high", SpinWeights{Quick: 11}},
+		{"zero category multiplier", SpinWeights{Categories: map[int32]float64{1: 0}}},
+		{"invalid category", SpinWeights{Categories: map[int32]float64{0: 2}}},
+		{"negative favorite boost", SpinWeights{Favorites: []int32{1}, FavoriteBoost: -1}},
+		{"penalty above 1", SpinWeights{RecentPenalty: 1.5}},
+		{"penalty without client", SpinWeights{RecentPenalty: 0.5}},
+		{"expiring too high", SpinWeights{Expiring: 11}},
+		{"expiring days too high", SpinWeights{ExpiringDays: 31}},
+		{"expiring without client", SpinWeights{Expiring: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecipesService(&mockRecipesRepository{})
+
+			weights := tt.weights
+			_, err := service.GetRandomRecipe(context.Background(), RecipeFilters{Weights: &weights})
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("Expected ErrInvalidParams, got %v", err)
+			}
+		})
+	}
+}
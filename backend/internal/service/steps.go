@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Step is one ordered instruction of a recipe
+type Step struct {
+	Position           int32   `json:"position"`
+	Text               string  `json:"text"`
+	DurationSeconds    *int32  `json:"duration_seconds,omitempty"`
+	TemperatureCelsius *int32  `json:"temperature_celsius,omitempty"`
+	IngredientRefs     []int32 `json:"ingredient_refs"`
+}
+
+var (
+	// leading numbering: "1. ", "2) ", "Step 3: "
+	stepNumberPattern = regexp.MustCompile(`(?i)^\s*(?:step\s*)?\d+\s*[.):-]\s*`)
+	// "30 seconds", "2-3 hours", "1.5 hours", "10 mins"
+	durationPattern = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)(?:\s*(?:-|to)\s*\d+(?:\.\d+)?)?\s*(seconds?|secs?|minutes?|mins?|hours?|hrs?)\b`)
+	// "180°C", "350 °F", "200 degrees C", "180 degrees celsius"
+	temperaturePattern = regexp.MustCompile(`(?i)\b(\d{2,3})\s*(?:°|degrees?\s*)\s*(c|f|celsius|fahrenheit)\b`)
+)
+
+// ParseInstructions splits a newline-separated instruction blob such as
+// "1. Heat oil\n2. Simmer for 2 hours" into steps, detecting the first
+// duration and temperature mentioned in each step. Ingredient references
+// are matched against the given ingredient list by name.
+func ParseInstructions(text string, ingredients []Ingredient) []Step {
+	steps := []Step{}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(stepNumberPattern.ReplaceAllString(line, ""))
+		if line == "" {
+			continue
+		}
+
+		steps = append(steps, Step{
+			Position:           int32(len(steps) + 1),
+			Text:               line,
+			DurationSeconds:    detectDuration(line),
+			TemperatureCelsius: detectTemperature(line),
+			IngredientRefs:     matchIngredientRefs(line, ingredients),
+		})
+	}
+
+	return steps
+}
+
+// detectDuration returns the first duration in seconds. For ranges such as
+// "3-4 minutes" the lower bound is used so timers fire before overcooking.
+func detectDuration(text string) *int32 {
+	m := durationPattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil
+	}
+
+	unit := strings.ToLower(m[2])
+	switch {
+	case strings.HasPrefix(unit, "h"):
+		value *= 3600
+	case strings.HasPrefix(unit, "m"):
+		value *= 60
+	}
+
+	seconds := int32(math.Round(value))
+	return &seconds
+}
+
+// detectTemperature returns the first stated temperature in Celsius
+func detectTemperature(text string) *int32 {
+	m := temperaturePattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+
+	value, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil
+	}
+
+	if strings.HasPrefix(strings.ToLower(m[2]), "f") {
+		value = int(math.Round(float64(value-32) * 5 / 9))
+	}
+
+	celsius := int32(value)
+	return &celsius
+}
+
+// matchIngredientRefs finds ingredients mentioned in a step. The full name
+// is tried first, then its last word ("cooked rice" → "rice").
+func matchIngredientRefs(text string, ingredients []Ingredient) []int32 {
+	refs := []int32{}
+	lower := strings.ToLower(text)
+
+	for i, item := range ingredients {
+		name := strings.ToLower(item.Name)
+		if name == "" {
+			continue
+		}
+
+		candidates := []string{name}
+		if words := strings.Fields(name); len(words) > 1 {
+			candidates = append(candidates, words[len(words)-1])
+		}
+
+		for _, candidate := range candidates {
+			if len(candidate) > 2 && containsWord(lower, candidate) {
+				refs = append(refs, int32(i+1))
+				break
+			}
+		}
+	}
+
+	return refs
+}
+
+// containsWord reports whether word appears in text on word boundaries
+func containsWord(text, word string) bool {
+	pattern := `\b` + regexp.QuoteMeta(word) + `\b`
+	matched, err := regexp.MatchString(pattern, text)
+	return err == nil && matched
+}
+
+// FormatInstructions renders steps as the numbered text stored in
+// recipes.instructions
+func FormatInstructions(steps []Step) string {
+	lines := make([]string, len(steps))
+	for i, step := range steps {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, step.Text)
+	}
+	return strings.Join(lines, "\n")
+}
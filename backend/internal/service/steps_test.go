@@ -0,0 +1,67 @@
+package service
+
+import "testing"
+
+func TestParseInstructions(t *testing.T) {
+	ingredients := ParseIngredients("2 cups cooked rice, 3 cloves garlic (minced), 2 shallots (sliced), 1 tsp shrimp paste")
+	text := "1. Heat oil in a wok over medium-high heat\n" +
+		"2. Sauté garlic and shallots until fragrant\n" +
+		"3. Add shrimp paste and stir for 30 seconds\n" +
+		"\n" +
+		"4. Mix well and stir-fry for 3-4 minutes\n" +
+		"5. Simmer uncovered for 2-3 hours\n" +
+		"6. Bake at 180°C for 1.5 hours\n" +
+		"7. Add rice"
+
+	steps := ParseInstructions(text, ingredients)
+
+	if len(steps) != 7 {
+		t.Fatalf("Expected 7 steps, got %d", len(steps))
+	}
+
+	if steps[0].Text != "Heat oil in a wok over medium-high heat" || steps[0].Position != 1 {
+		t.Errorf("Expected numbering to be stripped, got %+v", steps[0])
+	}
+
+	durations := map[int]int32{0: 0, 2: 30, 3: 180, 4: 7200, 5: 5400}
+	for i, want := range durations {
+		got := steps[i].DurationSeconds
+		if want == 0 {
+			if got != nil {
+				t.Errorf("step %d: expected no duration, got %d", i+1, *got)
+			}
+			continue
+		}
+		if got == nil || *got != want {
+			t.Errorf("step %d: expected duration %d, got %v", i+1, want, got)
+		}
+	}
+
+	if steps[5].TemperatureCelsius == nil || *steps[5].TemperatureCelsius != 180 {
+		t.Errorf("Expected 180°C on step 6, got %v", steps[5].TemperatureCelsius)
+	}
+
+	if refs := steps[1].IngredientRefs; len(refs) != 2 || refs[0] != 2 || refs[1] != 3 {
+		t.Errorf("Expected step 2 to reference garlic and shallots, got %v", refs)
+	}
+
+	if refs := steps[6].IngredientRefs; len(refs) != 1 || refs[0] != 1 {
+		t.Errorf("Expected step 7 to reference cooked rice, got %v", refs)
+	}
+}
+
+func TestDetectTemperature_Fahrenheit(t *testing.T) {
+	got := detectTemperature("Preheat oven to 350 °F")
+
+	if got == nil || *got != 177 {
+		t.Errorf("Expected 177°C, got %v", got)
+	}
+}
+
+func TestFormatInstructions(t *testing.T) {
+	steps := []Step{{Text: "Cook pasta"}, {Text: "Fry bacon"}}
+
+	if got := FormatInstructions(steps); got != "1. Cook pasta\n2. Fry bacon" {
+		t.Errorf("Unexpected instructions text %q", got)
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+var ErrTagNotFound = errors.New("tag not found")
+
+// Tag name and per-recipe limits
+const (
+	maxTagNameLength = 50
+	maxRecipeTags    = 20
+)
+
+// Autocomplete result limits
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+)
+
+// Tag represents a tag in the response
+type Tag struct {
+	ID          int32     `json:"id"`
+	Name        string    `json:"name"`
+	RecipeCount int64     `json:"recipe_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TagRequest holds data for creating or renaming a tag
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+// TagsService defines the interface for tag business logic
+type TagsService interface {
+	ListTags(ctx context.Context) ([]Tag, error)
+	GetTagByID(ctx context.Context, id int32) (*Tag, error)
+	AutocompleteTags(ctx context.Context, prefix string, limit int) ([]Tag, error)
+	CreateTag(ctx context.Context, req TagRequest) (*Tag, error)
+	UpdateTag(ctx context.Context, id int32, req TagRequest) (*Tag, error)
+	DeleteTag(ctx context.Context, id int32) error
+}
+
+type tagsService struct {
+	repo repository.TagsRepository
+}
+
+// NewTagsService creates a new tags service
+func NewTagsService(repo repository.TagsRepository) TagsService {
+	return &tagsService{
+		repo: repo,
+	}
+}
+
+// tagFromRow converts a tag row; the get and autocomplete rows have the
+// same columns and convert to db.ListTagsRow
+func tagFromRow(row db.ListTagsRow) Tag {
+	return Tag{
+		ID:          row.ID,
+		Name:        row.Name,
+		RecipeCount: row.RecipeCount,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func (s *tagsService) ListTags(ctx context.Context) ([]Tag, error) {
+	rows, err := s.repo.ListTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	tags := make([]Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = tagFromRow(row)
+	}
+
+	return tags, nil
+}
+
+func (s *tagsService) GetTagByID(ctx context.Context, id int32) (*Tag, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid tag ID", ErrInvalidParams)
+	}
+
+	row, err := s.repo.GetTagByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	tag := tagFromRow(db.ListTagsRow(row))
+	return &tag, nil
+}
+
+// AutocompleteTags returns tags starting with prefix, most used first. An
+// empty prefix returns the most used tags overall.
+func (s *tagsService) AutocompleteTags(ctx context.Context, prefix string, limit int) ([]Tag, error) {
+	if limit < 1 {
+		limit = defaultAutocompleteLimit
+	}
+	if limit > maxAutocompleteLimit {
+		limit = maxAutocompleteLimit
+	}
+
+	// Tag names never contain anything outside the slug alphabet, so a
+	// prefix that does cannot match
+	prefix = strings.TrimSpace(prefix)
+	if prefix != "" {
+		var err error
+		if prefix, err = normalizeTagName(prefix); err != nil {
+			return []Tag{}, nil
+		}
+	}
+
+	rows, err := s.repo.AutocompleteTags(ctx, prefix, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to autocomplete tags: %w", err)
+	}
+
+	tags := make([]Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = tagFromRow(db.ListTagsRow(row))
+	}
+
+	return tags, nil
+}
+
+func (s *tagsService) CreateTag(ctx context.Context, req TagRequest) (*Tag, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreateTag(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: tag %q already exists", ErrConflict, name)
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return s.GetTagByID(ctx, int32(id))
+}
+
+// UpdateTag renames a tag; recipes carrying it pick up the new name
+func (s *tagsService) UpdateTag(ctx context.Context, id int32, req TagRequest) (*Tag, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid tag ID", ErrInvalidParams)
+	}
+
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if tag exists
+	if _, err := s.GetTagByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTag(ctx, id, name); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: tag %q already exists", ErrConflict, name)
+		}
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	return s.GetTagByID(ctx, id)
+}
+
+// DeleteTag removes a tag from every recipe and deletes it
+func (s *tagsService) DeleteTag(ctx context.Context, id int32) error {
+	if _, err := s.GetTagByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTag(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeTagName turns a tag into its slug form: lowercase, with runs of
+// spaces, underscores and hyphens collapsed to one hyphen. "One Pot" and
+// "one_pot" both become "one-pot". Other characters are rejected.
+func normalizeTagName(name string) (string, error) {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		case r == ' ' || r == '_' || r == '-' || r == '\t':
+			pendingHyphen = true
+		default:
+			return "", fmt.Errorf("%w: tag %q may only contain letters, digits, spaces and hyphens", ErrInvalidParams, name)
+		}
+	}
+
+	slug := b.String()
+	if slug == "" {
+		return "", fmt.Errorf("%w: tag name is required", ErrInvalidParams)
+	}
+	if len(slug) > maxTagNameLength {
+		return "", fmt.Errorf("%w: tag %q must be at most %d characters", ErrInvalidParams, slug, maxTagNameLength)
+	}
+	return slug, nil
+}
+
+// normalizeTags slugs and de-duplicates a list of tags, skipping blank ones
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		slug, err := normalizeTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			normalized = append(normalized, slug)
+		}
+	}
+	return normalized, nil
+}
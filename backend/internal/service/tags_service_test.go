@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Mock repository for testing
+type mockTagsRepository struct {
+	getTagByIDFunc       func(ctx context.Context, id int32) (db.GetTagByIDRow, error)
+	autocompleteTagsFunc func(ctx context.Context, prefix string, limit int32) ([]db.AutocompleteTagsRow, error)
+	createTagFunc        func(ctx context.Context, name string) (int64, error)
+	updateTagFunc        func(ctx context.Context, id int32, name string) error
+}
+
+func (m *mockTagsRepository) ListTags(ctx context.Context) ([]db.ListTagsRow, error) {
+	return []db.ListTagsRow{}, nil
+}
+
+func (m *mockTagsRepository) GetTagByID(ctx context.Context, id int32) (db.GetTagByIDRow, error) {
+	if m.getTagByIDFunc != nil {
+		return m.getTagByIDFunc(ctx, id)
+	}
+	return db.GetTagByIDRow{ID: id, Name: "one-pot"}, nil
+}
+
+func (m *mockTagsRepository) AutocompleteTags(ctx context.Context, prefix string, limit int32) ([]db.AutocompleteTagsRow, error) {
+	if m.autocompleteTagsFunc != nil {
+		return m.autocompleteTagsFunc(ctx, prefix, limit)
+	}
+	return []db.AutocompleteTagsRow{}, nil
+}
+
+func (m *mockTagsRepository) CreateTag(ctx context.Context, name string) (int64, error) {
+	if m.createTagFunc != nil {
+		return m.createTagFunc(ctx, name)
+	}
+	return 1, nil
+}
+
+func (m *mockTagsRepository) UpdateTag(ctx context.Context, id int32, name string) error {
+	if m.updateTagFunc != nil {
+		return m.updateTagFunc(ctx, id, name)
+	}
+	return nil
+}
+
+func (m *mockTagsRepository) DeleteTag(ctx context.Context, id int32) error {
+	return nil
+}
+
+func TestNormalizeTagName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"One Pot", "one-pot", true},
+		{"  street_food ", "street-food", true},
+		{"--kid  friendly--", "kid-friendly", true},
+		{"30-minute", "30-minute", true},
+		{"", "", false},
+		{" - ", "", false},
+		{"50% off", "", false},
+		{"pedas🌶", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeTagName(tt.input)
+		if tt.valid && (err != nil || got != tt.expected) {
+			t.Errorf("normalizeTagName(%q) = %q, %v; want %q", tt.input, got, err, tt.expected)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("normalizeTagName(%q): expected ErrInvalidParams, got %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestAutocompleteTags(t *testing.T) {
+	var gotPrefix string
+	var gotLimit int32
+	mockRepo := &mockTagsRepository{
+		autocompleteTagsFunc: func(ctx context.Context, prefix string, limit int32) ([]db.AutocompleteTagsRow, error) {
+			gotPrefix, gotLimit = prefix, limit
+			return []db.AutocompleteTagsRow{
+				{ID: 2, Name: "one-pot", RecipeCount: 7},
+				{ID: 5, Name: "one-pan", RecipeCount: 2},
+			}, nil
+		},
+	}
+
+	service := NewTagsService(mockRepo)
+
+	tags, err := service.AutocompleteTags(context.Background(), "One P", 500)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if gotPrefix != "one-p" {
+		t.Errorf("Expected prefix to be normalized to one-p, got %q", gotPrefix)
+	}
+	if gotLimit != maxAutocompleteLimit {
+		t.Errorf("Expected limit to be capped at %d, got %d", maxAutocompleteLimit, gotLimit)
+	}
+	if len(tags) != 2 || tags[0].RecipeCount != 7 {
+		t.Errorf("Expected tags with usage counts, got %+v", tags)
+	}
+}
+
+func TestAutocompleteTags_UnmatchablePrefix(t *testing.T) {
+	mockRepo := &mockTagsRepository{
+		autocompleteTagsFunc: func(ctx context.Context, prefix string, limit int32) ([]db.AutocompleteTagsRow, error) {
+			t.Error("Expected repository not to be queried")
+			return nil, nil
+		},
+	}
+
+	service := NewTagsService(mockRepo)
+
+	tags, err := service.AutocompleteTags(context.Background(), "50%", 0)
+	if err != nil || len(tags) != 0 {
+		t.Errorf("Expected no tags and no error, got %v, %v", tags, err)
+	}
+}
+
+func TestCreateTag_Duplicate(t *testing.T) {
+	mockRepo := &mockTagsRepository{
+		createTagFunc: func(ctx context.Context, name string) (int64, error) {
+			return 0, repository.ErrDuplicate
+		},
+	}
+
+	service := NewTagsService(mockRepo)
+
+	_, err := service.CreateTag(context.Background(), TagRequest{Name: "One Pot"})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestUpdateTag_NotFound(t *testing.T) {
+	mockRepo := &mockTagsRepository{
+		getTagByIDFunc: func(ctx context.Context, id int32) (db.GetTagByIDRow, error) {
+			return db.GetTagByIDRow{}, sql.ErrNoRows
+		},
+		updateTagFunc: func(ctx context.Context, id int32, name string) error {
+			t.Error("Expected update not to be called")
+			return nil
+		},
+	}
+
+	service := NewTagsService(mockRepo)
+
+	_, err := service.UpdateTag(context.Background(), 9, TagRequest{Name: "weeknight"})
+	if !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected ErrTagNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnitSystem selects how ingredient quantities are rendered
+type UnitSystem string
+
+const (
+	UnitsMetric UnitSystem = "metric"
+	UnitsUS     UnitSystem = "us"
+)
+
+// ParseUnitSystem validates the ?units= value
+func ParseUnitSystem(s string) (UnitSystem, error) {
+	switch UnitSystem(strings.ToLower(s)) {
+	case UnitsMetric:
+		return UnitsMetric, nil
+	case UnitsUS, "imperial":
+		return UnitsUS, nil
+	}
+	return "", fmt.Errorf("%w: units must be metric or us", ErrInvalidParams)
+}
+
+type unitKind int
+
+const (
+	kindCount unitKind = iota
+	kindMass
+	kindVolume
+)
+
+// unitInfo describes a canonical unit and its size in grams (mass) or
+// millilitres (volume)
+type unitInfo struct {
+	kind unitKind
+	base float64
+}
+
+var units = map[string]unitInfo{
+	"mg":    {kindMass, 0.001},
+	"g":     {kindMass, 1},
+	"kg":    {kindMass, 1000},
+	"oz":    {kindMass, 28.35},
+	"lb":    {kindMass, 453.6},
+	"ml":    {kindVolume, 1},
+	"l":     {kindVolume, 1000},
+	"tsp":   {kindVolume, 5},
+	"tbsp":  {kindVolume, 15},
+	"fl oz": {kindVolume, 30},
+	"cup":   {kindVolume, 240},
+}
+
+// unitAliases maps spellings found in recipes to their canonical unit
+var unitAliases = map[string]string{
+	"gram": "g", "grams": "g", "gr": "g",
+	"kilogram": "kg", "kilograms": "kg",
+	"milligram": "mg", "milligrams": "mg",
+	"ounce": "oz", "ounces": "oz",
+	"pound": "lb", "pounds": "lb", "lbs": "lb",
+	"milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"teaspoon": "tsp", "teaspoons": "tsp",
+	"tablespoon": "tbsp", "tablespoons": "tbsp",
+}
+
+// pluralUnits are canonical units written in plural for quantities above
+// one ("2 cups", "3 cloves")
+var pluralUnits = map[string]string{
+	"cup": "cups", "clove": "cloves", "stalk": "stalks", "slice": "slices",
+	"piece": "pieces", "can": "cans", "bunch": "bunches", "sheet": "sheets",
+}
+
+func init() {
+	for singular, plural := range pluralUnits {
+		unitAliases[plural] = singular
+	}
+}
+
+// displayUnit renders a canonical unit for the given quantity
+func displayUnit(unit string, q float64) string {
+	if plural, ok := pluralUnits[unit]; ok && q > 1 {
+		return plural
+	}
+	return unit
+}
+
+// NormalizeUnit returns the canonical spelling of a unit ("Tablespoons" →
+// "tbsp", "cups" → "cup"). Unknown units are lowercased and returned as is.
+func NormalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if canonical, ok := unitAliases[unit]; ok {
+		return canonical
+	}
+	return unit
+}
+
+// densities are grams per cup for ingredients commonly measured by volume.
+// Longer names are matched first so "brown sugar" wins over "sugar".
+var densities = map[string]float64{
+	"rice":             185,
+	"cooked rice":      160,
+	"glutinous rice":   200,
+	"flour":            125,
+	"rice flour":       160,
+	"tapioca flour":    120,
+	"cornstarch":       128,
+	"sugar":            200,
+	"brown sugar":      220,
+	"palm sugar":       190,
+	"powdered sugar":   120,
+	"salt":             288,
+	"butter":           227,
+	"oats":             90,
+	"peanuts":          145,
+	"roasted peanuts":  145,
+	"shredded coconut": 85,
+	"honey":            340,
+	"breadcrumbs":      108,
+}
+
+var densityNames = func() []string {
+	names := make([]string, 0, len(densities))
+	for name := range densities {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}()
+
+// densityFor returns grams per ml for an ingredient name, if known
+func densityFor(name string) (float64, bool) {
+	lower := strings.ToLower(name)
+	for _, candidate := range densityNames {
+		if containsWord(lower, candidate) {
+			return densities[candidate] / units["cup"].base, true
+		}
+	}
+	return 0, false
+}
+
+// ConvertIngredients renders quantities in the given unit system. Metric
+// turns cups and US weights into grams (via the density table) or
+// millilitres; US turns grams and millilitres into cups, spoons, ounces or
+// pounds. Spoons are kept in metric since metric kitchens use them too.
+// Countable items and items without a quantity are left unchanged.
+func ConvertIngredients(items []Ingredient, system UnitSystem) []Ingredient {
+	converted := make([]Ingredient, len(items))
+	for i, item := range items {
+		converted[i] = item
+		if item.Quantity == nil || item.Unit == nil {
+			continue
+		}
+
+		unit := NormalizeUnit(*item.Unit)
+		info, ok := units[unit]
+		if !ok {
+			continue
+		}
+
+		var quantity float64
+		var target string
+		switch system {
+		case UnitsMetric:
+			quantity, target = toMetric(*item.Quantity, unit, info, item.Name)
+		case UnitsUS:
+			quantity, target = toUS(*item.Quantity, unit, info, item.Name)
+		default:
+			continue
+		}
+
+		converted[i].Quantity = &quantity
+		converted[i].Unit = &target
+	}
+	return converted
+}
+
+// ConvertRecipe converts the recipe's ingredients in place and re-renders
+// its ingredient text
+func ConvertRecipe(recipe *Recipe, system UnitSystem) {
+	recipe.IngredientItems = ConvertIngredients(recipe.IngredientItems, system)
+	recipe.Ingredients = FormatIngredients(recipe.IngredientItems)
+}
+
+func toMetric(q float64, unit string, info unitInfo, name string) (float64, string) {
+	switch {
+	case info.kind == kindMass:
+		return metricMass(q * info.base)
+	case unit == "tsp" || unit == "tbsp" || unit == "ml" || unit == "l":
+		return roundKitchen(q, unit), unit
+	}
+
+	ml := q * info.base
+	if density, ok := densityFor(name); ok {
+		return metricMass(ml * density)
+	}
+	if ml >= 1000 {
+		return roundKitchen(ml/1000, "l"), "l"
+	}
+	return roundKitchen(ml, "ml"), "ml"
+}
+
+func metricMass(grams float64) (float64, string) {
+	if grams >= 1000 {
+		return roundKitchen(grams/1000, "kg"), "kg"
+	}
+	return roundKitchen(grams, "g"), "g"
+}
+
+func toUS(q float64, unit string, info unitInfo, name string) (float64, string) {
+	if unit == "tsp" || unit == "tbsp" || unit == "cup" || unit == "oz" || unit == "lb" || unit == "fl oz" {
+		return roundKitchen(q, unit), unit
+	}
+
+	if info.kind == kindMass {
+		grams := q * info.base
+		density, ok := densityFor(name)
+		if !ok {
+			if grams >= units["lb"].base {
+				return roundKitchen(grams/units["lb"].base, "lb"), "lb"
+			}
+			return roundKitchen(grams/units["oz"].base, "oz"), "oz"
+		}
+		return usVolume(grams / density)
+	}
+
+	return usVolume(q * info.base)
+}
+
+// usVolume picks the largest spoon or cup measure that keeps the amount
+// readable: cups from 1/4 cup, tablespoons from 1 tbsp, teaspoons below
+func usVolume(ml float64) (float64, string) {
+	switch {
+	case ml >= units["cup"].base/4:
+		return roundToFraction(ml / units["cup"].base), "cup"
+	case ml >= units["tbsp"].base:
+		return roundToFraction(ml / units["tbsp"].base), "tbsp"
+	default:
+		return roundToFraction(ml / units["tsp"].base), "tsp"
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeUnit(t *testing.T) {
+	tests := map[string]string{
+		"Tablespoons": "tbsp",
+		"cups":        "cup",
+		"grams":       "g",
+		"Lbs":         "lb",
+		"cloves":      "clove",
+		"pinch":       "pinch",
+	}
+
+	for input, want := range tests {
+		if got := NormalizeUnit(input); got != want {
+			t.Errorf("NormalizeUnit(%q): expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestConvertIngredients_Metric(t *testing.T) {
+	items := ParseIngredients("2 cups cooked rice, 1 cup flour, 1 cup coconut milk, 1 tbsp sugar, " +
+		"8 oz chicken, 5 cups water, 3 cloves garlic, Salt to taste")
+
+	want := []string{
+		"320g cooked rice", "125g flour", "240ml coconut milk", "1 tbsp sugar",
+		"225g chicken", "1.2l water", "3 cloves garlic", "Salt to taste",
+	}
+
+	converted := ConvertIngredients(items, UnitsMetric)
+	for i, w := range want {
+		if got := FormatIngredient(converted[i]); got != w {
+			t.Errorf("item %d: expected %q, got %q", i, w, got)
+		}
+	}
+}
+
+func TestConvertIngredients_US(t *testing.T) {
+	items := ParseIngredients("370g rice, 100g brown sugar, 500g chicken, 300g egg noodles, " +
+		"400ml coconut milk, 30ml soy sauce, 10ml lime juice, 2 tbsp oil")
+
+	want := []string{
+		"2 cups rice", "1/2 cup brown sugar", "1 lb chicken", "11 oz egg noodles",
+		"1 2/3 cups coconut milk", "2 tbsp soy sauce", "2 tsp lime juice", "2 tbsp oil",
+	}
+
+	converted := ConvertIngredients(items, UnitsUS)
+	for i, w := range want {
+		if got := FormatIngredient(converted[i]); got != w {
+			t.Errorf("item %d: expected %q, got %q", i, w, got)
+		}
+	}
+}
+
+func TestParseUnitSystem(t *testing.T) {
+	if system, err := ParseUnitSystem("Metric"); err != nil || system != UnitsMetric {
+		t.Errorf("Expected metric, got %q, %v", system, err)
+	}
+	if system, err := ParseUnitSystem("imperial"); err != nil || system != UnitsUS {
+		t.Errorf("Expected us, got %q, %v", system, err)
+	}
+	if _, err := ParseUnitSystem("nautical"); !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+var ErrVariantNotFound = errors.New("variant not found")
+
+// Variant represents a variant in the response
+type Variant struct {
+	ID          int32     `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	RecipeCount int64     `json:"recipe_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// VariantRequest holds data for creating or updating a variant
+type VariantRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// VariantsService defines the interface for variant business logic
+type VariantsService interface {
+	ListVariants(ctx context.Context) ([]Variant, error)
+	GetVariantByID(ctx context.Context, id int32) (*Variant, error)
+	CreateVariant(ctx context.Context, req VariantRequest) (*Variant, error)
+	UpdateVariant(ctx context.Context, id int32, req VariantRequest) (*Variant, error)
+	DeleteVariant(ctx context.Context, id int32) error
+}
+
+type variantsService struct {
+	repo repository.VariantsRepository
+}
+
+// NewVariantsService creates a new variants service
+func NewVariantsService(repo repository.VariantsRepository) VariantsService {
+	return &variantsService{
+		repo: repo,
+	}
+}
+
+func (s *variantsService) ListVariants(ctx context.Context) ([]Variant, error) {
+	rows, err := s.repo.ListVariants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variants: %w", err)
+	}
+
+	variants := make([]Variant, len(rows))
+	for i, row := range rows {
+		variants[i] = Variant{
+			ID:          row.ID,
+			Name:        row.Name,
+			Description: nullStringToPtr(row.Description),
+			RecipeCount: row.RecipeCount,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		}
+	}
+
+	return variants, nil
+}
+
+func (s *variantsService) GetVariantByID(ctx context.Context, id int32) (*Variant, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid variant ID", ErrInvalidParams)
+	}
+
+	row, err := s.repo.GetVariantByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVariantNotFound
+		}
+		return nil, fmt.Errorf("failed to get variant: %w", err)
+	}
+
+	return &Variant{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: nullStringToPtr(row.Description),
+		RecipeCount: row.RecipeCount,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
+}
+
+// validateVariantRequest trims the name and checks it fits the column
+func validateVariantRequest(req *VariantRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidParams)
+	}
+	if len(req.Name) > 100 {
+		return fmt.Errorf("%w: name must be at most 100 characters", ErrInvalidParams)
+	}
+	return nil
+}
+
+func (s *variantsService) CreateVariant(ctx context.Context, req VariantRequest) (*Variant, error) {
+	if err := validateVariantRequest(&req); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.CreateVariant(ctx, repository.VariantParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: variant %q already exists", ErrConflict, req.Name)
+		}
+		return nil, fmt.Errorf("failed to create variant: %w", err)
+	}
+
+	return s.GetVariantByID(ctx, int32(id))
+}
+
+func (s *variantsService) UpdateVariant(ctx context.Context, id int32, req VariantRequest) (*Variant, error) {
+	if id < 1 {
+		return nil, fmt.Errorf("%w: invalid variant ID", ErrInvalidParams)
+	}
+
+	if err := validateVariantRequest(&req); err != nil {
+		return nil, err
+	}
+
+	// Check if variant exists
+	if _, err := s.GetVariantByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.UpdateVariant(ctx, id, repository.VariantParams{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: variant %q already exists", ErrConflict, req.Name)
+		}
+		return nil, fmt.Errorf("failed to update variant: %w", err)
+	}
+
+	return s.GetVariantByID(ctx, id)
+}
+
+func (s *variantsService) DeleteVariant(ctx context.Context, id int32) error {
+	existing, err := s.GetVariantByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Recipes reference variants with ON DELETE RESTRICT
+	if existing.RecipeCount > 0 {
+		return fmt.Errorf("%w: variant is used by %d recipe(s)", ErrConflict, existing.RecipeCount)
+	}
+
+	if err := s.repo.DeleteVariant(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrReferenced) {
+			return fmt.Errorf("%w: variant is still used by recipes", ErrConflict)
+		}
+		return fmt.Errorf("failed to delete variant: %w", err)
+	}
+
+	return nil
+}
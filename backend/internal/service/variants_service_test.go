@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sonyadriko/masakyuk/internal/db"
+	"github.com/sonyadriko/masakyuk/internal/repository"
+)
+
+// Mock repository for testing
+type mockVariantsRepository struct {
+	getVariantByIDFunc func(ctx context.Context, id int32) (db.GetVariantByIDRow, error)
+	deleteVariantFunc  func(ctx context.Context, id int32) error
+}
+
+func (m *mockVariantsRepository) ListVariants(ctx context.Context) ([]db.ListVariantsRow, error) {
+	return nil, nil
+}
+
+func (m *mockVariantsRepository) GetVariantByID(ctx context.Context, id int32) (db.GetVariantByIDRow, error) {
+	if m.getVariantByIDFunc != nil {
+		return m.getVariantByIDFunc(ctx, id)
+	}
+	return db.GetVariantByIDRow{ID: id, Name: "Vegan"}, nil
+}
+
+func (m *mockVariantsRepository) CreateVariant(ctx context.Context, params repository.VariantParams) (int64, error) {
+	return 1, nil
+}
+
+func (m *mockVariantsRepository) UpdateVariant(ctx context.Context, id int32, params repository.VariantParams) error {
+	return nil
+}
+
+func (m *mockVariantsRepository) DeleteVariant(ctx context.Context, id int32) error {
+	if m.deleteVariantFunc != nil {
+		return m.deleteVariantFunc(ctx, id)
+	}
+	return nil
+}
+
+func TestDeleteVariant_Unused(t *testing.T) {
+	service := NewVariantsService(&mockVariantsRepository{})
+
+	if err := service.DeleteVariant(context.Background(), 3); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestDeleteVariant_ForeignKeyRestrict(t *testing.T) {
+	// A recipe may be assigned between the count check and the delete
+	mockRepo := &mockVariantsRepository{
+		deleteVariantFunc: func(ctx context.Context, id int32) error {
+			return repository.ErrReferenced
+		},
+	}
+
+	service := NewVariantsService(mockRepo)
+
+	err := service.DeleteVariant(context.Background(), 3)
+
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
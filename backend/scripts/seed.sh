@@ -44,6 +44,15 @@ echo ""
 echo "🌱 Seeding database with recipes..."
 mysql -h "$DB_HOST" -P "$DB_PORT" -u "$DB_USER" -p"$DB_PASSWORD" "$DB_NAME" < db/seeds/001_recipes_seed.sql
 
+if [ $? -ne 0 ]; then
+    echo "❌ Error seeding database"
+    exit 1
+fi
+
+# Convert free-text recipe data into structured rows
+echo "🧩 Backfilling structured recipe data..."
+go run ./cmd/backfill
+
 if [ $? -eq 0 ]; then
     echo "✅ Database seeded successfully!"
     echo ""
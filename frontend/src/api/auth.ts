@@ -0,0 +1,38 @@
+import apiClient, { getRefreshToken, setAuthTokens } from './client';
+import type { AuthTokens, Credentials } from '@/types/recipe';
+
+export const authApi = {
+    /**
+     * Create an account and sign in
+     */
+    register: async (credentials: Credentials): Promise<AuthTokens> => {
+        const response = await apiClient.post('/auth/register', credentials);
+        setAuthTokens(response.data.data);
+        return response.data.data;
+    },
+
+    /**
+     * Sign in with an email and password
+     */
+    login: async (credentials: Credentials): Promise<AuthTokens> => {
+        const response = await apiClient.post('/auth/login', credentials);
+        setAuthTokens(response.data.data);
+        return response.data.data;
+    },
+
+    /**
+     * Sign out, revoking the stored refresh token
+     */
+    logout: async (): Promise<void> => {
+        const refreshToken = getRefreshToken();
+        setAuthTokens(null);
+        if (refreshToken) {
+            await apiClient.post('/auth/logout', { refresh_token: refreshToken });
+        }
+    },
+
+    /**
+     * Whether tokens from a sign-in are stored
+     */
+    isSignedIn: (): boolean => getRefreshToken() !== null,
+};
@@ -1,4 +1,5 @@
-import axios from 'axios';
+import axios, { type InternalAxiosRequestConfig } from 'axios';
+import type { AuthTokens } from '@/types/recipe';
 
 const apiClient = axios.create({
     baseURL: '/api',
@@ -8,9 +9,73 @@ const apiClient = axios.create({
     timeout: 10000,
 });
 
+const CLIENT_ID_KEY = 'masakyuk-client-id';
+
+// clientId identifies this browser for spin history, the pantry and meal
+// plans while signed out; signed-in requests use the account instead. It
+// is generated once and kept in localStorage.
+const clientId = (): string => {
+    let id = localStorage.getItem(CLIENT_ID_KEY);
+    if (!id) {
+        id = crypto.randomUUID();
+        localStorage.setItem(CLIENT_ID_KEY, id);
+    }
+    return id;
+};
+
+const ACCESS_TOKEN_KEY = 'masakyuk-access-token';
+const REFRESH_TOKEN_KEY = 'masakyuk-refresh-token';
+
+// setAuthTokens stores the tokens from register, login or refresh; null
+// signs out
+export const setAuthTokens = (tokens: AuthTokens | null) => {
+    if (tokens) {
+        localStorage.setItem(ACCESS_TOKEN_KEY, tokens.access_token);
+        localStorage.setItem(REFRESH_TOKEN_KEY, tokens.refresh_token);
+    } else {
+        localStorage.removeItem(ACCESS_TOKEN_KEY);
+        localStorage.removeItem(REFRESH_TOKEN_KEY);
+    }
+};
+
+export const getRefreshToken = (): string | null => localStorage.getItem(REFRESH_TOKEN_KEY);
+
+let refreshing: Promise<string | null> | null = null;
+
+// refreshAccessToken exchanges the refresh token for new tokens. Requests
+// failing at the same time share one refresh, since a refresh token only
+// works once.
+const refreshAccessToken = (): Promise<string | null> => {
+    const refreshToken = getRefreshToken();
+    if (!refreshToken) return Promise.resolve(null);
+
+    if (!refreshing) {
+        refreshing = axios
+            .post(`${apiClient.defaults.baseURL}/auth/refresh`, { refresh_token: refreshToken })
+            .then((response) => {
+                const tokens: AuthTokens = response.data.data;
+                setAuthTokens(tokens);
+                return tokens.access_token;
+            })
+            .catch(() => {
+                setAuthTokens(null);
+                return null;
+            })
+            .finally(() => {
+                refreshing = null;
+            });
+    }
+    return refreshing;
+};
+
 // Request interceptor
 apiClient.interceptors.request.use(
     (config) => {
+        config.headers['X-Client-ID'] = clientId();
+        const accessToken = localStorage.getItem(ACCESS_TOKEN_KEY);
+        if (accessToken) {
+            config.headers.Authorization = `Bearer ${accessToken}`;
+        }
         return config;
     },
     (error) => {
@@ -23,7 +88,18 @@ apiClient.interceptors.response.use(
     (response) => {
         return response;
     },
-    (error) => {
+    async (error) => {
+        // Retry once with a fresh access token when the current one expired
+        const config = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined;
+        if (error.response?.status === 401 && config && !config._retried && !config.url?.startsWith('/auth/')) {
+            config._retried = true;
+            const accessToken = await refreshAccessToken();
+            if (accessToken) {
+                config.headers.Authorization = `Bearer ${accessToken}`;
+                return apiClient(config);
+            }
+        }
+
         if (error.response) {
             // Server responded with error
             console.error('API Error:', error.response.data);
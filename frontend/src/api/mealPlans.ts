@@ -0,0 +1,109 @@
+import apiClient from './client';
+import type {
+    SavedMealPlan,
+    SaveMealPlanRequest,
+    MealPlanEntryInput,
+    MealPlanSpinRequest,
+    SpinResponse,
+    ShoppingList,
+    UnitSystem,
+} from '@/types/recipe';
+
+export const mealPlansApi = {
+    /**
+     * List this client's stored meal plans, latest first
+     */
+    getPlans: async (): Promise<SavedMealPlan[]> => {
+        const response = await apiClient.get('/plans');
+        return response.data.data;
+    },
+
+    /**
+     * Get a plan with its entries
+     */
+    getPlan: async (id: number): Promise<SavedMealPlan> => {
+        const response = await apiClient.get(`/plans/${id}`);
+        return response.data.data;
+    },
+
+    /**
+     * Create a plan, optionally with its entries
+     */
+    createPlan: async (plan: SaveMealPlanRequest): Promise<SavedMealPlan> => {
+        const response = await apiClient.post('/plans', plan);
+        return response.data.data;
+    },
+
+    /**
+     * Rename a plan or change its dates
+     */
+    updatePlan: async (id: number, plan: SaveMealPlanRequest): Promise<SavedMealPlan> => {
+        const response = await apiClient.put(`/plans/${id}`, plan);
+        return response.data.data;
+    },
+
+    /**
+     * Delete a plan and its entries
+     */
+    deletePlan: async (id: number): Promise<void> => {
+        await apiClient.delete(`/plans/${id}`);
+    },
+
+    /**
+     * Put a recipe in a slot, replacing the one there
+     */
+    assignEntry: async (id: number, entry: MealPlanEntryInput): Promise<SavedMealPlan> => {
+        const response = await apiClient.post(`/plans/${id}/entries`, entry);
+        return response.data.data;
+    },
+
+    /**
+     * Fill a slot with a spin that skips recipes already in the plan
+     */
+    spinEntry: async (id: number, request: MealPlanSpinRequest): Promise<{ data: SavedMealPlan; spin: SpinResponse }> => {
+        const response = await apiClient.post(`/plans/${id}/spin`, request);
+        return response.data;
+    },
+
+    /**
+     * Move an entry to another slot, swapping with any entry there
+     */
+    moveEntry: async (id: number, entryId: number, date: string, meal: string): Promise<SavedMealPlan> => {
+        const response = await apiClient.post(`/plans/${id}/entries/${entryId}/move`, { date, meal });
+        return response.data.data;
+    },
+
+    /**
+     * Override an entry's servings; null goes back to the recipe's servings
+     */
+    setServings: async (id: number, entryId: number, servings: number | null): Promise<SavedMealPlan> => {
+        const response = await apiClient.put(`/plans/${id}/entries/${entryId}/servings`, { servings });
+        return response.data.data;
+    },
+
+    /**
+     * Empty a slot
+     */
+    deleteEntry: async (id: number, entryId: number): Promise<void> => {
+        await apiClient.delete(`/plans/${id}/entries/${entryId}`);
+    },
+
+    /**
+     * Shopping list for every entry of a plan
+     */
+    getShoppingList: async (id: number, units?: UnitSystem): Promise<ShoppingList> => {
+        const response = await apiClient.get(`/plans/${id}/shopping-list`, { params: { units } });
+        return response.data.data;
+    },
+
+    /**
+     * Export a plan's shopping list as Markdown
+     */
+    exportShoppingList: async (id: number, units?: UnitSystem): Promise<string> => {
+        const response = await apiClient.get(`/plans/${id}/shopping-list`, {
+            params: { units, format: 'markdown' },
+            responseType: 'text',
+        });
+        return response.data;
+    },
+};
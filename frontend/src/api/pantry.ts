@@ -0,0 +1,35 @@
+import apiClient from './client';
+import type { PantryItem, PantryItemInput } from '@/types/recipe';
+
+export const pantryApi = {
+    /**
+     * List this client's pantry items, soonest to expire first
+     */
+    getItems: async (): Promise<PantryItem[]> => {
+        const response = await apiClient.get('/pantry');
+        return response.data.data;
+    },
+
+    /**
+     * Add an item to the pantry
+     */
+    createItem: async (item: PantryItemInput): Promise<PantryItem> => {
+        const response = await apiClient.post('/pantry', item);
+        return response.data.data;
+    },
+
+    /**
+     * Replace every field of a pantry item
+     */
+    updateItem: async (id: number, item: PantryItemInput): Promise<PantryItem> => {
+        const response = await apiClient.put(`/pantry/${id}`, item);
+        return response.data.data;
+    },
+
+    /**
+     * Remove an item from the pantry
+     */
+    deleteItem: async (id: number): Promise<void> => {
+        await apiClient.delete(`/pantry/${id}`);
+    },
+};
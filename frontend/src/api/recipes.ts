@@ -1,5 +1,5 @@
 import apiClient from './client';
-import type { RecipeFilters, RecipesListResponse, SpinRequest, SpinResponse, Recipe } from '@/types/recipe';
+import type { RecipeFilters, RecipesListResponse, SpinRequest, SpinResponse, Recipe, RecipeInput, MealPlan, MealPlanRequest, MealPlanRerollRequest, ShoppingList, ShoppingListRequest } from '@/types/recipe';
 
 export const recipesApi = {
     /**
@@ -9,12 +9,20 @@ export const recipesApi = {
         const params = new URLSearchParams();
 
         if (filters.search) params.append('search', filters.search);
-        if (filters.skill_level) params.append('skill_level', filters.skill_level);
-        if (filters.variant_id) params.append('variant_id', filters.variant_id.toString());
-        if (filters.category_id) params.append('category_id', filters.category_id.toString());
+        filters.skill_level?.forEach((level) => params.append('skill_level', level));
+        filters.variant_id?.forEach((id) => params.append('variant_id', id.toString()));
+        filters.category_id?.forEach((id) => params.append('category_id', id.toString()));
+        if (filters.min_cooking_time) params.append('min_cooking_time', filters.min_cooking_time.toString());
         if (filters.max_cooking_time) params.append('max_cooking_time', filters.max_cooking_time.toString());
+        filters.tags_any?.forEach((tag) => params.append('tags_any', tag));
+        filters.tags_all?.forEach((tag) => params.append('tags_all', tag));
+        if (filters.sort) params.append('sort', filters.sort);
+        if (filters.order) params.append('order', filters.order);
+        if (filters.cursor) params.append('cursor', filters.cursor);
+        if (filters.facets?.length) params.append('facets', filters.facets.join(','));
         if (filters.page) params.append('page', filters.page.toString());
         if (filters.per_page) params.append('per_page', filters.per_page.toString());
+        if (filters.pantry) params.append('pantry', 'true');
 
         const response = await apiClient.get<RecipesListResponse>('/recipes', { params });
         return response.data;
@@ -23,15 +31,17 @@ export const recipesApi = {
     /**
      * Get a single recipe by ID
      */
-    getRecipeById: async (id: number): Promise<Recipe> => {
-        const response = await apiClient.get(`/recipes/${id}`);
+    getRecipeById: async (id: number, pantry = false): Promise<Recipe> => {
+        const response = await apiClient.get(`/recipes/${id}`, {
+            params: pantry ? { pantry: true } : undefined,
+        });
         return response.data.data;
     },
 
     /**
      * Create a new recipe
      */
-    createRecipe: async (data: Omit<Recipe, 'id' | 'category_name' | 'variant_name'>): Promise<Recipe> => {
+    createRecipe: async (data: RecipeInput): Promise<Recipe> => {
         const response = await apiClient.post('/recipes', data);
         return response.data.data;
     },
@@ -39,16 +49,18 @@ export const recipesApi = {
     /**
      * Update an existing recipe
      */
-    updateRecipe: async (id: number, data: Omit<Recipe, 'id' | 'category_name' | 'variant_name'>): Promise<Recipe> => {
+    updateRecipe: async (id: number, data: RecipeInput): Promise<Recipe> => {
         const response = await apiClient.put(`/recipes/${id}`, data);
         return response.data.data;
     },
 
     /**
-     * Delete a recipe
+     * Delete a recipe. Resolves to the number of meal plan entries removed
+     * with it
      */
-    deleteRecipe: async (id: number): Promise<void> => {
-        await apiClient.delete(`/recipes/${id}`);
+    deleteRecipe: async (id: number): Promise<number> => {
+        const response = await apiClient.delete(`/recipes/${id}`);
+        return response.data.removed_plan_entries;
     },
 
     /**
@@ -58,4 +70,39 @@ export const recipesApi = {
         const response = await apiClient.post<SpinResponse>('/spin', filters);
         return response.data;
     },
+
+    /**
+     * Spin a meal plan of several days and meals
+     */
+    generateMealPlan: async (request: MealPlanRequest = {}): Promise<MealPlan> => {
+        const response = await apiClient.post<MealPlan>('/spin/plan', request);
+        return response.data;
+    },
+
+    /**
+     * Re-roll one slot of a meal plan, keeping the others
+     */
+    rerollMealPlanSlot: async (request: MealPlanRerollRequest): Promise<MealPlan> => {
+        const response = await apiClient.post<MealPlan>('/spin/plan/reroll', request);
+        return response.data;
+    },
+
+    /**
+     * Build a merged shopping list for recipes at the given servings
+     */
+    getShoppingList: async (request: ShoppingListRequest): Promise<ShoppingList> => {
+        const response = await apiClient.post('/shopping-list', request);
+        return response.data.data;
+    },
+
+    /**
+     * Export a shopping list as Markdown
+     */
+    exportShoppingList: async (request: ShoppingListRequest): Promise<string> => {
+        const response = await apiClient.post('/shopping-list', request, {
+            params: { format: 'markdown' },
+            responseType: 'text',
+        });
+        return response.data;
+    },
 };
@@ -0,0 +1,12 @@
+import apiClient from './client';
+import type { Tag } from '@/types/recipe';
+
+export const tagsApi = {
+    /**
+     * Suggest tags starting with a prefix, most used first
+     */
+    autocomplete: async (q: string, limit = 10): Promise<Tag[]> => {
+        const response = await apiClient.get('/tags/autocomplete', { params: { q, limit } });
+        return response.data.data;
+    },
+};
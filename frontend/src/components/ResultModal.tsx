@@ -43,7 +43,9 @@ const ResultModal: React.FC<ResultModalProps> = ({ recipe, isOpen, onClose }) =>
                         <h3 className={styles.sectionTitle}>Category & Variant</h3>
                         <div className={styles.tags}>
                             <span className={styles.tag}>{recipe.category_name}</span>
-                            <span className={styles.tag}>{recipe.variant_name}</span>
+                            {recipe.variants.map((variant) => (
+                                <span key={variant.id} className={styles.tag}>{variant.name}</span>
+                            ))}
                         </div>
                     </div>
 
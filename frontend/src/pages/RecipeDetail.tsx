@@ -62,7 +62,9 @@ const RecipeDetail: React.FC = () => {
                             {recipe.skill_level}
                         </span>
                         <span className={styles.badge}>{recipe.category_name}</span>
-                        <span className={styles.badge}>{recipe.variant_name}</span>
+                        {recipe.variants.map((variant) => (
+                            <span key={variant.id} className={styles.badge}>{variant.name}</span>
+                        ))}
                     </div>
                 </div>
 
@@ -24,7 +24,7 @@ const RecipeForm: React.FC = () => {
         cooking_time: 30,
         skill_level: 'beginner',
         category_id: 1,
-        variant_id: 1,
+        variant_ids: [1],
         servings: 2,
     });
 
@@ -38,7 +38,7 @@ const RecipeForm: React.FC = () => {
                 cooking_time: existingRecipe.cooking_time,
                 skill_level: existingRecipe.skill_level,
                 category_id: existingRecipe.category_id,
-                variant_id: existingRecipe.variant_id,
+                variant_ids: existingRecipe.variants.map((variant) => variant.id),
                 servings: existingRecipe.servings,
             });
         }
@@ -71,11 +71,16 @@ const RecipeForm: React.FC = () => {
         }
     };
 
+    const handleVariantsChange = (e: React.ChangeEvent<HTMLSelectElement>) => {
+        const variantIds = Array.from(e.target.selectedOptions, (option) => Number(option.value));
+        setFormData(prev => ({ ...prev, variant_ids: variantIds }));
+    };
+
     const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement | HTMLSelectElement>) => {
         const { name, value } = e.target;
         setFormData(prev => ({
             ...prev,
-            [name]: ['cooking_time', 'category_id', 'variant_id', 'servings'].includes(name)
+            [name]: ['cooking_time', 'category_id', 'servings'].includes(name)
                 ? Number(value)
                 : value,
         }));
@@ -183,12 +188,13 @@ const RecipeForm: React.FC = () => {
                     </div>
 
                     <div className={styles.formGroup}>
-                        <label htmlFor="variant_id">Varian *</label>
+                        <label htmlFor="variant_ids">Varian *</label>
                         <select
-                            id="variant_id"
-                            name="variant_id"
-                            value={formData.variant_id}
-                            onChange={handleChange}
+                            id="variant_ids"
+                            name="variant_ids"
+                            multiple
+                            value={formData.variant_ids.map(String)}
+                            onChange={handleVariantsChange}
                             required
                             className={styles.select}
                         >
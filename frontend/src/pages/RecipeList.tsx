@@ -83,7 +83,9 @@ const RecipeList: React.FC = () => {
                                 </div>
                                 <div className={styles.tags}>
                                     <span className={styles.tag}>{recipe.category_name}</span>
-                                    <span className={styles.tag}>{recipe.variant_name}</span>
+                                    {recipe.variants.map((variant) => (
+                                        <span key={variant.id} className={styles.tag}>{variant.name}</span>
+                                    ))}
                                 </div>
                                 <div className={styles.actions}>
                                     <Link to={`/recipes/${recipe.id}`} className={styles.viewButton}>
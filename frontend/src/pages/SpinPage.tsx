@@ -6,6 +6,8 @@ import ResultModal from '@/components/ResultModal';
 import type { Recipe, SpinRequest } from '@/types/recipe';
 import styles from './SpinPage.module.css';
 
+const RECENT_SPINS_TO_AVOID = 5;
+
 const SpinPage: React.FC = () => {
     const [filters, setFilters] = useState<SpinRequest>({});
     const [isSpinning, setIsSpinning] = useState(false);
@@ -35,7 +37,8 @@ const SpinPage: React.FC = () => {
         if (isSpinning || spinMutation.isPending) return;
 
         setShowResult(false);
-        spinMutation.mutate(filters);
+        // Avoid landing on the last few results again
+        spinMutation.mutate({ ...filters, exclude_recent: { spins: RECENT_SPINS_TO_AVOID } });
     };
 
     const handleSpinComplete = () => {
@@ -48,7 +51,7 @@ const SpinPage: React.FC = () => {
         setSelectedRecipe(null);
     };
 
-    const handleFilterChange = (key: keyof SpinRequest, value: string | number | undefined) => {
+    const handleFilterChange = (key: keyof SpinRequest, value: SpinRequest[keyof SpinRequest]) => {
         setFilters(prev => ({
             ...prev,
             [key]: value || undefined,
@@ -82,8 +85,8 @@ const SpinPage: React.FC = () => {
                         <label className={styles.label}>Skill Level</label>
                         <select
                             className={styles.select}
-                            value={filters.skill_level || ''}
-                            onChange={(e) => handleFilterChange('skill_level', e.target.value)}
+                            value={filters.skill_level?.[0] || ''}
+                            onChange={(e) => handleFilterChange('skill_level', e.target.value ? [e.target.value] : undefined)}
                         >
                             <option value="">All Levels</option>
                             <option value="beginner">Beginner</option>
@@ -96,8 +99,8 @@ const SpinPage: React.FC = () => {
                         <label className={styles.label}>Variant</label>
                         <select
                             className={styles.select}
-                            value={filters.variant_id || ''}
-                            onChange={(e) => handleFilterChange('variant_id', e.target.value ? parseInt(e.target.value) : undefined)}
+                            value={filters.variant_id?.[0] || ''}
+                            onChange={(e) => handleFilterChange('variant_id', e.target.value ? [parseInt(e.target.value)] : undefined)}
                         >
                             <option value="">All Variants</option>
                             <option value="1">Regular</option>
@@ -112,8 +115,8 @@ const SpinPage: React.FC = () => {
                         <label className={styles.label}>Category</label>
                         <select
                             className={styles.select}
-                            value={filters.category_id || ''}
-                            onChange={(e) => handleFilterChange('category_id', e.target.value ? parseInt(e.target.value) : undefined)}
+                            value={filters.category_id?.[0] || ''}
+                            onChange={(e) => handleFilterChange('category_id', e.target.value ? [parseInt(e.target.value)] : undefined)}
                         >
                             <option value="">All Categories</option>
                             <option value="1">Indonesian</option>
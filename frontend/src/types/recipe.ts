@@ -1,3 +1,24 @@
+export interface Ingredient {
+    quantity?: number;
+    unit?: string;
+    name: string;
+    note?: string;
+    group?: string;
+}
+
+export interface Step {
+    position: number;
+    text: string;
+    duration_seconds?: number;
+    temperature_celsius?: number;
+    ingredient_refs: number[];
+}
+
+export interface RecipeVariant {
+    id: number;
+    name: string;
+}
+
 export interface Recipe {
     id: number;
     title: string;
@@ -8,54 +29,337 @@ export interface Recipe {
     skill_level: 'beginner' | 'intermediate' | 'advanced';
     category_id: number;
     category_name: string;
-    variant_id: number;
-    variant_name: string;
+    variants: RecipeVariant[];
     image_url?: string;
     servings: number;
+    calories?: number;
+    protein?: number;
+    carbs?: number;
+    fat?: number;
+    health_tags?: string[];
+    tags?: string[];
+    allergens?: string[];
+    ingredient_items?: Ingredient[];
+    steps?: Step[];
+    dietary_warnings?: DietaryViolation[];
+    pantry?: PantryStatus;
+}
+
+export interface DietaryViolation {
+    variant: string;
+    ingredient: string;
+    reason: string;
+}
+
+// RecipeInput is the create/update payload: variants are sent as IDs
+export type RecipeInput = Omit<Recipe, 'id' | 'category_name' | 'variants' | 'dietary_warnings'> & {
+    variant_ids: number[];
+    dietary_check?: 'strict' | 'warn';
+};
+
+export type RecipeSort = 'newest' | 'oldest' | 'cooking_time' | 'title' | 'calories' | 'relevance';
+
+export type RecipeFacet = 'category' | 'variant' | 'skill_level' | 'cooking_time';
+
+export interface FacetCount {
+    value: string;
+    label: string;
+    count: number;
 }
 
 export interface RecipeFilters {
     search?: string;
-    skill_level?: string;
-    variant_id?: number;
-    category_id?: number;
+    skill_level?: string[];
+    variant_id?: number[];
+    category_id?: number[];
+    min_cooking_time?: number;
     max_cooking_time?: number;
+    min_calories?: number;
+    max_calories?: number;
+    min_protein?: number;
+    max_carbs?: number;
+    max_fat?: number;
+    health_tags_any?: string[];
+    health_tags_all?: string[];
+    tags_any?: string[];
+    tags_all?: string[];
+    exclude_ingredients?: string[];
+    exclude_allergens?: string[];
+    sort?: RecipeSort;
+    order?: 'asc' | 'desc';
+    cursor?: string;
+    facets?: RecipeFacet[];
     page?: number;
     per_page?: number;
+    pantry?: boolean;
 }
 
 export interface PaginationMeta {
     total: number;
-    page: number;
+    // Omitted when the list was continued from a cursor
+    page?: number;
     per_page: number;
     total_pages: number;
+    next_cursor?: string;
 }
 
 export interface RecipesListResponse {
     data: Recipe[];
     meta: PaginationMeta;
+    facets?: Partial<Record<RecipeFacet, FacetCount[]>>;
 }
 
-export interface SpinRequest {
+export interface SpinFilters {
     search?: string;
-    skill_level?: string;
-    variant_id?: number;
-    category_id?: number;
+    skill_level?: string[];
+    variant_id?: number[];
+    category_id?: number[];
+    min_cooking_time?: number;
     max_cooking_time?: number;
+    min_calories?: number;
+    max_calories?: number;
+    min_protein?: number;
+    max_carbs?: number;
+    max_fat?: number;
+    health_tags_any?: string[];
+    health_tags_all?: string[];
+    tags_any?: string[];
+    tags_all?: string[];
+    exclude_ingredients?: string[];
+    exclude_allergens?: string[];
+    pantry?: string[];
+    max_missing?: number;
+}
+
+export interface SpinRequest extends SpinFilters {
+    exclude_recent?: RecentSpins;
+    weights?: SpinWeights;
+    // seed replays a previous spin's draw
+    seed?: number;
+}
+
+// RecentSpins avoids this browser's recent spins: the last `spins` results
+// and/or those from the last `days` days
+export interface RecentSpins {
+    spins?: number;
+    days?: number;
+}
+
+// SpinWeights skews the draw; each recipe's weight is the product of the
+// factors that apply to it
+export interface SpinWeights {
+    quick?: number;
+    categories?: Record<number, number>;
+    favorites?: number[];
+    favorite_boost?: number;
+    recent_penalty?: number;
+    expiring?: number;
+    expiring_days?: number;
 }
 
 export interface SpinResponse {
     recipe: Recipe;
+    probability: number;
+    candidates: number;
+    recent_repeat?: boolean;
+    seed: number;
+}
+
+export interface MealPlanRequest {
+    days?: number;
+    meals?: string[];
+    max_daily_cooking_time?: number;
+    filters?: SpinFilters;
+    // day_filters narrow `filters` on some days, keyed by day number from 1
+    day_filters?: Record<number, SpinFilters>;
+    weights?: SpinWeights;
+    seed?: number;
+}
+
+// MealPlanRerollRequest re-rolls one slot under the plan's constraints
+export interface MealPlanRerollRequest extends MealPlanRequest {
+    plan: MealPlan;
+    day: number;
+    meal: string;
+}
+
+export interface MealPlanSlot {
+    meal: string;
+    recipe_id: number;
+    recipe?: Recipe;
+    category_repeat?: boolean;
+}
+
+export interface MealPlanDay {
+    day: number;
+    slots: MealPlanSlot[];
+    cooking_time: number;
+}
+
+export interface MealPlan {
+    meals: string[];
+    days: MealPlanDay[];
+    seed: number;
+}
+
+export interface SavedMealPlan {
+    id: number;
+    name: string;
+    start_date: string;
+    end_date: string;
+    entry_count: number;
+    entries?: MealPlanEntry[];
+    created_at: string;
+    updated_at: string;
+}
+
+export interface MealPlanEntry {
+    id: number;
+    date: string;
+    meal: string;
+    recipe_id: number;
+    recipe_title: string;
+    cooking_time: number;
+    servings: number;
+    custom_servings: boolean;
+}
+
+export interface SaveMealPlanRequest {
+    name: string;
+    start_date: string;
+    end_date: string;
+    entries?: MealPlanEntryInput[];
+}
+
+export interface MealPlanEntryInput {
+    date: string;
+    meal: string;
+    recipe_id: number;
+    servings?: number;
+}
+
+export interface MealPlanSpinRequest extends SpinRequest {
+    date: string;
+    meal: string;
+    servings?: number;
+}
+
+export type UnitSystem = 'metric' | 'us';
+
+export interface ShoppingListRecipe {
+    recipe_id: number;
+    title?: string;
+    // 0 or omitted keeps the recipe's own servings
+    servings?: number;
+}
+
+export interface ShoppingListRequest {
+    recipes: ShoppingListRecipe[];
+    units?: UnitSystem;
+}
+
+export interface ShoppingListItem {
+    name: string;
+    quantity: number | null;
+    unit: string | null;
+    text: string;
+    staple: boolean;
+    recipe_ids: number[];
+}
+
+export interface ShoppingAisle {
+    aisle: 'produce' | 'meat' | 'seafood' | 'dairy' | 'spices' | 'pantry' | 'other';
+    title: string;
+    items: ShoppingListItem[];
+}
+
+export interface ShoppingList {
+    name?: string;
+    recipes: ShoppingListRecipe[];
+    aisles: ShoppingAisle[];
+}
+
+export interface PantrySearchRequest {
+    ingredients: string[];
+    max_missing?: number;
+    limit?: number;
+}
+
+export interface PantryMatch {
+    recipe: Recipe;
+    coverage: number;
+    required: number;
+    matched: string[];
+    missing: string[];
+}
+
+export interface PantrySearchResponse {
+    data: PantryMatch[];
 }
 
 export interface Category {
     id: number;
     name: string;
     description?: string;
+    recipe_count: number;
 }
 
 export interface Variant {
     id: number;
     name: string;
     description?: string;
+    recipe_count: number;
+}
+
+export interface Tag {
+    id: number;
+    name: string;
+    recipe_count: number;
+}
+
+// PantryItem is an ingredient stored in this client's pantry
+export interface PantryItem {
+    id: number;
+    name: string;
+    quantity: number | null;
+    unit: string | null;
+    expires_on: string | null;
+    created_at: string;
+    updated_at: string;
+}
+
+export interface PantryItemInput {
+    name: string;
+    quantity?: number | null;
+    unit?: string | null;
+    expires_on?: string | null;
+}
+
+// PantryStatus is how much of a recipe the stored pantry covers
+export interface PantryStatus {
+    have: number;
+    required: number;
+    missing: string[];
+    expiring: string[];
+}
+
+export interface User {
+    id: number;
+    email: string;
+    created_at: string;
+}
+
+export interface Credentials {
+    email: string;
+    password: string;
+}
+
+// AuthTokens is returned by register, login and refresh. Creating,
+// updating and deleting recipes needs the access token.
+export interface AuthTokens {
+    access_token: string;
+    token_type: 'Bearer';
+    expires_in: number;
+    refresh_token: string;
+    user: User;
 }